@@ -26,6 +26,10 @@ import (
 
 type ABI struct {
 	Methods []Method
+
+	// Errors are custom errors which contract reverts with,
+	// they have arguments but no output
+	Errors []Method `json:",omitempty"`
 }
 
 func New(abiJSON string) (ABI, error) {
@@ -91,19 +95,49 @@ func ExtractAbiFromFunction(f ast.FunctionLiteral) (Method, error) {
 		Type: t,
 	}
 
+	if f.ReturnType == ast.TupleType {
+		for _, rt := range f.ReturnTypes {
+			t, err := convertAstTypeToAbi(rt)
+			if err != nil {
+				return Method{}, err
+			}
+			method.Outputs = append(method.Outputs, Argument{Type: t})
+		}
+	}
+
 	return method, nil
 }
 
+// ExtractAbiFromError returns the ABI of custom error, whose ID is
+// the selector which contract reverts with
+func ExtractAbiFromError(e ast.ErrorLiteral) (Method, error) {
+	return ExtractAbiFromFunction(ast.FunctionLiteral{
+		Name:       e.Name,
+		Parameters: e.Parameters,
+		ReturnType: ast.VoidType,
+	})
+}
+
 func convertAstTypeToAbi(p ast.DataStructure) (Type, error) {
 	switch p {
 	case ast.IntType:
 		return NewType("int")
+	case ast.Int8Type, ast.Int16Type, ast.Int32Type:
+		return NewType(p.String())
+	case ast.UintType:
+		return NewType("uint")
+	case ast.AddressType:
+		return NewType("address")
 	case ast.StringType:
 		return NewType("string")
+	case ast.BytesType:
+		return NewType("bytes")
 	case ast.BoolType:
 		return NewType("bool")
 	case ast.VoidType:
 		return NewType("void")
+	case ast.TupleType:
+		return NewType("tuple")
 	default:
 		return Type{}, fmt.Errorf("Unknown paramter type. got=%v", p)
 	}
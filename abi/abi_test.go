@@ -154,6 +154,40 @@ func TestExtractAbiFromFunction(t *testing.T) {
 			},
 			err: nil,
 		},
+		// test function returning multiple values
+		{
+			f: ast.FunctionLiteral{
+				Name: &ast.Identifier{
+					Name: "pair",
+				},
+				Parameters:  []*ast.ParameterLiteral{},
+				ReturnType:  ast.TupleType,
+				ReturnTypes: []ast.DataStructure{ast.IntType, ast.BoolType},
+			},
+			expect: abi.Method{
+				Name:      "pair",
+				Arguments: []abi.Argument{},
+				Output: abi.Argument{
+					Name: "",
+					Type: abi.Type{
+						Type: "tuple",
+					},
+				},
+				Outputs: []abi.Argument{
+					{
+						Type: abi.Type{
+							Type: "int",
+						},
+					},
+					{
+						Type: abi.Type{
+							Type: "bool",
+						},
+					},
+				},
+			},
+			err: nil,
+		},
 	}
 
 	for i, test := range tests {
@@ -60,6 +60,17 @@ func encodeValues(params ...interface{}) ([]Value, error) {
 	values := make([]Value, len(params))
 
 	for index, param := range params {
+		// bytes and address are passed as they are,
+		// so that callee knows their length
+		switch v := param.(type) {
+		case []byte:
+			values[index] = append(values[index], v...)
+			continue
+		case encoding.Address:
+			values[index] = append(values[index], v[:]...)
+			continue
+		}
+
 		bytesValue, err := encoding.EncodeOperand(param)
 		if err != nil {
 			return nil, err
@@ -20,6 +20,10 @@ type Method struct {
 	Name      string
 	Arguments Arguments
 	Output    Argument
+
+	// Outputs are the values returned by function returning multiple
+	// values, whose Output has tuple type
+	Outputs []Argument `json:",omitempty"`
 }
 
 // Signature returns function's signature according to the ABI spec.
@@ -24,10 +24,17 @@ type ParamType string
 
 const (
 	Integer   ParamType = "int"
+	Integer8  ParamType = "int8"
+	Integer16 ParamType = "int16"
+	Integer32 ParamType = "int32"
 	Integer64 ParamType = "int64"
+	Unsigned  ParamType = "uint"
+	Address   ParamType = "address"
 	Boolean   ParamType = "bool"
 	String    ParamType = "string"
+	Bytes     ParamType = "bytes"
 	Void      ParamType = "void"
+	Tuple     ParamType = "tuple"
 )
 
 type Type struct {
@@ -40,14 +47,28 @@ func NewType(paramType string) (Type, error) {
 	switch paramType {
 	case "int":
 		typ.Type = Integer
+	case "int8":
+		typ.Type = Integer8
+	case "int16":
+		typ.Type = Integer16
+	case "int32":
+		typ.Type = Integer32
 	case "int64":
 		typ.Type = Integer64
+	case "uint":
+		typ.Type = Unsigned
+	case "address":
+		typ.Type = Address
 	case "bool":
 		typ.Type = Boolean
 	case "string":
 		typ.Type = String
+	case "bytes":
+		typ.Type = Bytes
 	case "void":
 		typ.Type = Void
+	case "tuple":
+		typ.Type = Tuple
 	default:
 		return Type{}, fmt.Errorf("unsupported arg type: %s", paramType)
 	}
@@ -31,6 +31,14 @@ func TestNewType(t *testing.T) {
 			Type:         "int64",
 			expectedType: abi.Integer64,
 		},
+		{
+			Type:         "int8",
+			expectedType: abi.Integer8,
+		},
+		{
+			Type:         "uint",
+			expectedType: abi.Unsigned,
+		},
 		{
 			Type:         "bool",
 			expectedType: abi.Boolean,
@@ -39,6 +47,10 @@ func TestNewType(t *testing.T) {
 			Type:         "string",
 			expectedType: abi.String,
 		},
+		{
+			Type:         "bytes",
+			expectedType: abi.Bytes,
+		},
 	}
 
 	for _, test := range tests {
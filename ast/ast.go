@@ -18,9 +18,12 @@ package ast
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/DE-labtory/koa/encoding"
 )
 
 // Node represent ast node
@@ -31,19 +34,122 @@ type Node interface {
 // Represent Statement
 type Statement interface {
 	Node
+	Ranged
+	Positioned
 	do()
 }
 
 // Represent Expression
 type Expression interface {
 	Node
+	Ranged
+	Positioned
 	produce()
 }
 
+// Pos represents the line and column where a node starts
+// in the source code. File is the library which node is imported
+// from, or the file of contract when it was parsed from a file.
+// Synthetic is set on nodes which compiler generated instead of
+// parsing them.
+type Pos struct {
+	File      string
+	Line      int
+	Column    int
+	Synthetic bool
+}
+
+// IsValid reports whether position was recorded by the parser
+func (p Pos) IsValid() bool {
+	return p.Line != 0 || p.Column != 0
+}
+
+func (p Pos) String() string {
+	s := fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+	if p.File != "" {
+		s = fmt.Sprintf("%s, %s", p.File, s)
+	}
+	if p.Synthetic {
+		s += " (synthetic)"
+	}
+	return s
+}
+
+// Span is the byte range of node in the source code, from the offset
+// of its first byte to the offset just after its last byte, so that
+// src[Start:End] is the text of node. Offsets are in the source node
+// was parsed from, which is the library for nodes merged from imported
+// library. Span is zero for nodes which were not parsed from source.
+type Span struct {
+	Start int
+	End   int
+}
+
+// IsValid reports whether range was recorded by the parser
+func (s Span) IsValid() bool {
+	return s.End > s.Start
+}
+
+// Range returns the byte range of node
+func (s Span) Range() Span {
+	return s
+}
+
+// SetRange records the byte range of node
+func (s *Span) SetRange(r Span) {
+	*s = r
+}
+
+// Ranged is implemented by every node, which gives the byte range
+// of node in the source code
+type Ranged interface {
+	Range() Span
+	SetRange(r Span)
+}
+
+// NodePos records the position where node starts, it is embedded
+// in every node with Span
+type NodePos struct {
+	Pos Pos
+}
+
+// Position returns the position where node starts
+func (n NodePos) Position() Pos {
+	return n.Pos
+}
+
+// SetPosition records the position where node starts
+func (n *NodePos) SetPosition(p Pos) {
+	n.Pos = p
+}
+
+// Positioned is implemented by every node, which gives the line
+// and column where node starts
+type Positioned interface {
+	Position() Pos
+	SetPosition(p Pos)
+}
+
 // Represent Contract.
 // Contract consists of multiple functions.
 type Contract struct {
+	// Interfaces are declared before contract, and Implements are
+	// the names of interfaces which contract implements
+	Interfaces []*Interface
+	Implements []*Identifier
+
+	// SupportsInterface is set by pragma erc165, compiler generates
+	// supportsInterface function for contract
+	SupportsInterface bool
+
+	Constants []*ConstStatement
+	Enums     []*EnumLiteral
+	Errors    []*ErrorLiteral
+	Modifiers []*ModifierLiteral
 	Functions []*FunctionLiteral
+
+	Span
+	NodePos
 }
 
 func (c *Contract) do() {}
@@ -51,8 +157,36 @@ func (c *Contract) String() string {
 	var buf bytes.Buffer
 
 	// start by change line for readability
-	buf.WriteString("\ncontract {\n")
+	buf.WriteString("\n")
+	if c.SupportsInterface {
+		buf.WriteString("pragma erc165\n")
+	}
+	for _, i := range c.Interfaces {
+		buf.WriteString(i.String() + "\n")
+	}
+
+	if len(c.Implements) == 0 {
+		buf.WriteString("contract {\n")
+	} else {
+		names := make([]string, 0, len(c.Implements))
+		for _, i := range c.Implements {
+			names = append(names, i.String())
+		}
+		buf.WriteString("contract implements " + strings.Join(names, ", ") + " {\n")
+	}
 
+	for _, c := range c.Constants {
+		buf.WriteString(c.String() + "\n")
+	}
+	for _, e := range c.Enums {
+		buf.WriteString(e.String() + "\n")
+	}
+	for _, e := range c.Errors {
+		buf.WriteString(e.String() + "\n")
+	}
+	for _, m := range c.Modifiers {
+		buf.WriteString(m.String() + "\n")
+	}
 	for _, fn := range c.Functions {
 		buf.WriteString(fn.String() + "\n")
 	}
@@ -64,6 +198,9 @@ func (c *Contract) String() string {
 // Represent identifier
 type Identifier struct {
 	Name string
+
+	Span
+	NodePos
 }
 
 func (i *Identifier) String() string {
@@ -124,6 +261,25 @@ const (
 	StringType
 	BoolType
 	VoidType
+
+	// TupleType is the return type of function which returns
+	// multiple values, e.g. func f() (int, bool)
+	TupleType
+
+	BytesType
+
+	// UintType is 64-bit unsigned integer, arithmetic on it wraps
+	// around modulo 2^64
+	UintType
+
+	// Sized signed integers, IntType is 64-bit. Value of sized
+	// integer wraps around to its width when it is stored.
+	Int8Type
+	Int16Type
+	Int32Type
+
+	// AddressType is 20 bytes account address
+	AddressType
 )
 
 var DataStructureMap = map[DataStructure]string{
@@ -131,17 +287,45 @@ var DataStructureMap = map[DataStructure]string{
 	StringType: "string",
 	BoolType:   "bool",
 	VoidType:   "void",
+	TupleType:  "tuple",
+	BytesType:  "bytes",
+	UintType:   "uint",
+	Int8Type:   "int8",
+	Int16Type:  "int16",
+	Int32Type:  "int32",
+
+	AddressType: "address",
 }
 
 func (ds DataStructure) String() string {
 	return DataStructureMap[ds]
 }
 
+// Width returns the number of bits of integer data structure,
+// or 0 if ds is not integer
+func (ds DataStructure) Width() int {
+	switch ds {
+	case Int8Type:
+		return 8
+	case Int16Type:
+		return 16
+	case Int32Type:
+		return 32
+	case IntType, UintType:
+		return 64
+	default:
+		return 0
+	}
+}
+
 // Represent assign statement
 type AssignStatement struct {
 	Type     DataStructure
 	Variable Identifier
 	Value    Expression
+
+	Span
+	NodePos
 }
 
 func (a *AssignStatement) do() {}
@@ -154,10 +338,55 @@ func (a *AssignStatement) String() string {
 	return out.String()
 }
 
+// ConstStatement declares constant at contract scope,
+// whose value is evaluated at compile time. e.g. const int FEE = 100
+type ConstStatement struct {
+	Type  DataStructure
+	Name  Identifier
+	Value Expression
+
+	Span
+	NodePos
+}
+
+func (c *ConstStatement) do() {}
+
+func (c *ConstStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("const " + c.Type.String() + " ")
+	out.WriteString(c.Name.Name + " = ")
+	out.WriteString(c.Value.String())
+	return out.String()
+}
+
+// TupleAssignStatement assigns multiple values to multiple variables
+// e.g. int a, bool b = f()
+type TupleAssignStatement struct {
+	Types     []DataStructure
+	Variables []Identifier
+	Value     Expression
+
+	Span
+	NodePos
+}
+
+func (t *TupleAssignStatement) do() {}
+
+func (t *TupleAssignStatement) String() string {
+	vars := make([]string, 0, len(t.Variables))
+	for i, v := range t.Variables {
+		vars = append(vars, t.Types[i].String()+" "+v.Name)
+	}
+	return strings.Join(vars, ", ") + " = " + t.Value.String()
+}
+
 // ReassignStatement is used when we want re-assign value to variable
 type ReassignStatement struct {
 	Variable *Identifier
 	Value    Expression
+
+	Span
+	NodePos
 }
 
 func (r *ReassignStatement) do() {}
@@ -173,6 +402,9 @@ func (r *ReassignStatement) String() string {
 // Represent return statement
 type ReturnStatement struct {
 	ReturnValue Expression
+
+	Span
+	NodePos
 }
 
 func (r *ReturnStatement) do() {}
@@ -184,11 +416,34 @@ func (r *ReturnStatement) String() string {
 	return fmt.Sprintf("return %s", r.ReturnValue.String())
 }
 
+// RevertStatement aborts the contract with custom error.
+// e.g. revert InsufficientBalance(amount, balance)
+type RevertStatement struct {
+	Error     *Identifier
+	Arguments []Expression
+
+	Span
+	NodePos
+}
+
+func (r *RevertStatement) do() {}
+
+func (r *RevertStatement) String() string {
+	args := make([]string, 0, len(r.Arguments))
+	for _, a := range r.Arguments {
+		args = append(args, a.String())
+	}
+	return fmt.Sprintf("revert %s( %s )", r.Error.String(), strings.Join(args, ", "))
+}
+
 // Represent if statement
 type IfStatement struct {
 	Condition   Expression
 	Consequence *BlockStatement
 	Alternative *BlockStatement
+
+	Span
+	NodePos
 }
 
 func (i *IfStatement) do() {}
@@ -201,6 +456,63 @@ func (i *IfStatement) String() string {
 		i.Alternative.String())
 }
 
+// ForStatement represents loop, which runs Body while Condition is true.
+// Init runs once before the loop, and Post runs after each iteration.
+// Init and Post are nil in condition-only loop, e.g. for (x < 10) { ... }
+type ForStatement struct {
+	Init      Statement
+	Condition Expression
+	Post      Statement
+	Body      *BlockStatement
+
+	Span
+	NodePos
+}
+
+func (f *ForStatement) do() {}
+
+func (f *ForStatement) String() string {
+	if f.Init == nil && f.Condition != nil && f.Post == nil {
+		return fmt.Sprintf("for ( %s ) { %s }", exprString(f.Condition), f.Body.String())
+	}
+
+	return fmt.Sprintf("for ( %s; %s; %s ) { %s }", stmtString(f.Init), exprString(f.Condition),
+		stmtString(f.Post), f.Body.String())
+}
+
+// DoWhileStatement represents loop, which runs Body once and then
+// repeats it while Condition is true
+// e.g. do { ... } while (x < 10)
+type DoWhileStatement struct {
+	Body      *BlockStatement
+	Condition Expression
+
+	Span
+	NodePos
+}
+
+func (d *DoWhileStatement) do() {}
+
+func (d *DoWhileStatement) String() string {
+	return fmt.Sprintf("do { %s } while ( %s )", d.Body.String(), d.Condition.String())
+}
+
+// stmtString returns string of s, or empty string when s is nil
+func stmtString(s Statement) string {
+	if s == nil {
+		return ""
+	}
+	return s.String()
+}
+
+// exprString returns string of e, or empty string when e is nil
+func exprString(e Expression) string {
+	if e == nil {
+		return ""
+	}
+	return e.String()
+}
+
 // FunctionLiteral represents function definition
 // e.g. func foo(int a) { ... }
 type FunctionLiteral struct {
@@ -208,6 +520,17 @@ type FunctionLiteral struct {
 	Parameters []*ParameterLiteral
 	Body       *BlockStatement
 	ReturnType DataStructure
+
+	// Modifiers are the names of modifiers attached to function,
+	// Body is already wrapped by them
+	Modifiers []*Identifier
+
+	// ReturnTypes are the types of returned values when
+	// ReturnType is TupleType
+	ReturnTypes []DataStructure
+
+	Span
+	NodePos
 }
 
 func (f *FunctionLiteral) do() {}
@@ -224,13 +547,37 @@ func (f *FunctionLiteral) String() string {
 
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
-	out.WriteString(f.ReturnType.String() + " {\n")
+	for _, m := range f.Modifiers {
+		out.WriteString(m.String() + " ")
+	}
+	out.WriteString(f.ReturnTypeString())
+
+	// function in interface has no body
+	if f.Body == nil {
+		return out.String()
+	}
+
+	out.WriteString(" {\n")
 	out.WriteString(f.Body.String() + "\n")
 	out.WriteString("}")
 
 	return out.String()
 }
 
+// ReturnTypeString returns return type as it is written in source,
+// e.g. int, (int, bool)
+func (f *FunctionLiteral) ReturnTypeString() string {
+	if f.ReturnType != TupleType {
+		return f.ReturnType.String()
+	}
+
+	types := make([]string, 0, len(f.ReturnTypes))
+	for _, t := range f.ReturnTypes {
+		types = append(types, t.String())
+	}
+	return "(" + strings.Join(types, ", ") + ")"
+}
+
 func (f *FunctionLiteral) Signature() string {
 
 	paramTypes := []string{}
@@ -244,6 +591,9 @@ func (f *FunctionLiteral) Signature() string {
 // Represent block statement
 type BlockStatement struct {
 	Statements []Statement
+
+	Span
+	NodePos
 }
 
 func (b *BlockStatement) do() {}
@@ -260,9 +610,155 @@ func (b *BlockStatement) String() string {
 	return strings.Join(str, "\n")
 }
 
+// Interface has function signatures which contract implements,
+// functions in it have no body
+type Interface struct {
+	Name      *Identifier
+	Functions []*FunctionLiteral
+
+	Span
+	NodePos
+}
+
+func (i *Interface) do() {}
+
+func (i *Interface) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("interface " + i.Name.String() + " {\n")
+	for _, fn := range i.Functions {
+		out.WriteString(fn.String() + "\n")
+	}
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// EnumLiteral declares enum whose members are integer constants
+// numbered from zero. e.g. enum Status { Pending, Active, Closed }
+type EnumLiteral struct {
+	Name    *Identifier
+	Members []*Identifier
+
+	Span
+	NodePos
+}
+
+func (e *EnumLiteral) do() {}
+
+func (e *EnumLiteral) String() string {
+	members := make([]string, 0, len(e.Members))
+	for _, m := range e.Members {
+		members = append(members, m.String())
+	}
+	return fmt.Sprintf("enum %s { %s }", e.Name.String(), strings.Join(members, ", "))
+}
+
+// ErrorLiteral declares custom error which contract reverts with.
+// e.g. error InsufficientBalance(needed int, got int)
+type ErrorLiteral struct {
+	Name       *Identifier
+	Parameters []*ParameterLiteral
+
+	Span
+	NodePos
+}
+
+func (e *ErrorLiteral) do() {}
+
+func (e *ErrorLiteral) String() string {
+	params := make([]string, 0, len(e.Parameters))
+	for _, p := range e.Parameters {
+		params = append(params, p.String())
+	}
+	return fmt.Sprintf("error %s(%s)", e.Name.String(), strings.Join(params, ", "))
+}
+
+// ModifierLiteral is reusable code which wraps function bodies, the
+// body of function is placed where PlaceholderStatement is.
+//
+//	modifier onlyPositive {
+//		require(FEE > 0, "fee")
+//		_
+//	}
+type ModifierLiteral struct {
+	Name *Identifier
+	Body *BlockStatement
+
+	Span
+	NodePos
+}
+
+func (m *ModifierLiteral) do() {}
+
+func (m *ModifierLiteral) String() string {
+	return fmt.Sprintf("modifier %s {\n%s\n}", m.Name.String(), m.Body.String())
+}
+
+// Wrap returns modifier body whose placeholders are replaced with body,
+// the modifier itself is not changed so that it can wrap other functions
+func (m *ModifierLiteral) Wrap(body *BlockStatement) *BlockStatement {
+	return wrapBlock(m.Body, body)
+}
+
+func wrapBlock(b *BlockStatement, body *BlockStatement) *BlockStatement {
+	if b == nil {
+		return nil
+	}
+
+	wrapped := &BlockStatement{Statements: make([]Statement, 0, len(b.Statements)), Span: b.Span}
+	for _, s := range b.Statements {
+		switch stmt := s.(type) {
+		case *PlaceholderStatement:
+			wrapped.Statements = append(wrapped.Statements, body)
+		case *BlockStatement:
+			wrapped.Statements = append(wrapped.Statements, wrapBlock(stmt, body))
+		case *IfStatement:
+			wrapped.Statements = append(wrapped.Statements, &IfStatement{
+				Condition:   stmt.Condition,
+				Consequence: wrapBlock(stmt.Consequence, body),
+				Alternative: wrapBlock(stmt.Alternative, body),
+				Span:        stmt.Span,
+			})
+		case *ForStatement:
+			wrapped.Statements = append(wrapped.Statements, &ForStatement{
+				Init:      stmt.Init,
+				Condition: stmt.Condition,
+				Post:      stmt.Post,
+				Body:      wrapBlock(stmt.Body, body),
+				Span:      stmt.Span,
+			})
+		case *DoWhileStatement:
+			wrapped.Statements = append(wrapped.Statements, &DoWhileStatement{
+				Body:      wrapBlock(stmt.Body, body),
+				Condition: stmt.Condition,
+				Span:      stmt.Span,
+			})
+		default:
+			wrapped.Statements = append(wrapped.Statements, s)
+		}
+	}
+	return wrapped
+}
+
+// PlaceholderStatement marks where modifier places function body
+type PlaceholderStatement struct {
+	Span
+	NodePos
+}
+
+func (p *PlaceholderStatement) do() {}
+
+func (p *PlaceholderStatement) String() string {
+	return "_"
+}
+
 // Represent function statement
 type ExpressionStatement struct {
 	Expr Expression
+
+	Span
+	NodePos
 }
 
 func (e *ExpressionStatement) do() {}
@@ -274,6 +770,9 @@ func (e *ExpressionStatement) String() string {
 // Represent string literal
 type StringLiteral struct {
 	Value string
+
+	Span
+	NodePos
 }
 
 func (s *StringLiteral) produce() {}
@@ -282,9 +781,40 @@ func (s *StringLiteral) String() string {
 	return s.Value
 }
 
+// Represent byte string literal, e.g. 0x"deadbeef"
+type BytesLiteral struct {
+	Value []byte
+
+	Span
+	NodePos
+}
+
+func (b *BytesLiteral) produce() {}
+
+func (b *BytesLiteral) String() string {
+	return `0x"` + hex.EncodeToString(b.Value) + `"`
+}
+
+// Represent address literal, e.g. 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed
+type AddressLiteral struct {
+	Value encoding.Address
+
+	Span
+	NodePos
+}
+
+func (a *AddressLiteral) produce() {}
+
+func (a *AddressLiteral) String() string {
+	return a.Value.String()
+}
+
 // Represent integer literal
 type IntegerLiteral struct {
 	Value int64
+
+	Span
+	NodePos
 }
 
 func (i *IntegerLiteral) produce() {}
@@ -296,6 +826,9 @@ func (i *IntegerLiteral) String() string {
 // Represent Boolean expression
 type BooleanLiteral struct {
 	Value bool
+
+	Span
+	NodePos
 }
 
 func (b *BooleanLiteral) produce() {}
@@ -308,11 +841,21 @@ func (b *BooleanLiteral) String() string {
 type ParameterLiteral struct {
 	Identifier *Identifier
 	Type       DataStructure
+
+	// Default is the value of parameter when call omits it,
+	// nil if parameter has no default value
+	Default Expression
+
+	Span
+	NodePos
 }
 
 func (p *ParameterLiteral) produce() {}
 
 func (p *ParameterLiteral) String() string {
+	if p.Default != nil {
+		return fmt.Sprintf("Parameter : (Identifier: %s, Type: %s, Default: %s)", p.Identifier.String(), p.Type.String(), p.Default.String())
+	}
 	return fmt.Sprintf("Parameter : (Identifier: %s, Type: %s)", p.Identifier.String(), p.Type.String())
 }
 
@@ -320,6 +863,9 @@ func (p *ParameterLiteral) String() string {
 type PrefixExpression struct {
 	Operator
 	Right Expression
+
+	Span
+	NodePos
 }
 
 func (p *PrefixExpression) produce() {}
@@ -333,6 +879,9 @@ type InfixExpression struct {
 	Left Expression
 	Operator
 	Right Expression
+
+	Span
+	NodePos
 }
 
 func (i *InfixExpression) produce() {}
@@ -341,10 +890,63 @@ func (i *InfixExpression) String() string {
 	return fmt.Sprintf("(%s %s %s)", i.Left.String(), i.Operator.String(), i.Right.String())
 }
 
+// TupleExpression is the list of values separated by comma
+// e.g. return 1, true
+type TupleExpression struct {
+	Elements []Expression
+
+	Span
+	NodePos
+}
+
+func (t *TupleExpression) produce() {}
+
+func (t *TupleExpression) String() string {
+	strs := make([]string, 0, len(t.Elements))
+	for _, e := range t.Elements {
+		strs = append(strs, e.String())
+	}
+	return strings.Join(strs, ", ")
+}
+
 // Represent Call expression
+// Represent index expression, e.g. b[0]
+type IndexExpression struct {
+	Left  Expression
+	Index Expression
+
+	Span
+	NodePos
+}
+
+func (i *IndexExpression) produce() {}
+
+func (i *IndexExpression) String() string {
+	return fmt.Sprintf("(%s[%s])", i.Left.String(), i.Index.String())
+}
+
+// SelectorExpression selects field of reserved identifier or member
+// of enum, e.g. msg.sender, Status.Active
+type SelectorExpression struct {
+	Left  *Identifier
+	Field *Identifier
+
+	Span
+	NodePos
+}
+
+func (s *SelectorExpression) produce() {}
+
+func (s *SelectorExpression) String() string {
+	return fmt.Sprintf("%s.%s", s.Left.String(), s.Field.String())
+}
+
 type CallExpression struct {
 	Function  Expression
 	Arguments []Expression
+
+	Span
+	NodePos
 }
 
 func (c *CallExpression) produce() {}
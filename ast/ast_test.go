@@ -43,6 +43,19 @@ func TestAssignStatement_String(t *testing.T) {
 	}
 }
 
+func TestTupleAssignStatement_String(t *testing.T) {
+	stmt := TupleAssignStatement{
+		Types:     []DataStructure{IntType, BoolType},
+		Variables: []Identifier{{Name: "a"}, {Name: "b"}},
+		Value: &CallExpression{
+			Function:  &Identifier{Name: "f"},
+			Arguments: []Expression{},
+		},
+	}
+
+	testString(t, stmt.String(), "int a, bool b = function f(  )")
+}
+
 func TestIdentifier_String(t *testing.T) {
 	tests := []struct {
 		input    Identifier
@@ -82,23 +95,23 @@ func TestStringLiteral_String(t *testing.T) {
 		expected string
 	}{
 		{
-			StringLiteral{"hello"},
+			StringLiteral{Value: "hello"},
 			"hello",
 		},
 		{
-			StringLiteral{"hello, world"},
+			StringLiteral{Value: "hello, world"},
 			"hello, world",
 		},
 		{
-			StringLiteral{"123"},
+			StringLiteral{Value: "123"},
 			"123",
 		},
 		{
-			StringLiteral{"123, hello"},
+			StringLiteral{Value: "123, hello"},
 			"123, hello",
 		},
 		{
-			StringLiteral{""},
+			StringLiteral{Value: ""},
 			"",
 		},
 	}
@@ -136,11 +149,11 @@ func TestBooleanLiteral_String(t *testing.T) {
 		expected string
 	}{
 		{
-			BooleanLiteral{true},
+			BooleanLiteral{Value: true},
 			"true",
 		},
 		{
-			BooleanLiteral{false},
+			BooleanLiteral{Value: false},
 			"false",
 		},
 	}
@@ -184,6 +197,29 @@ func TestFunctionLiteral_String(t *testing.T) {
 			},
 			`func foo() int {
 int a = 1
+}`,
+		},
+		{
+			FunctionLiteral{
+				Name:       &Identifier{Name: "foo"},
+				Parameters: []*ParameterLiteral{},
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ReturnStatement{
+							ReturnValue: &TupleExpression{
+								Elements: []Expression{
+									&IntegerLiteral{Value: 1},
+									&BooleanLiteral{Value: true},
+								},
+							},
+						},
+					},
+				},
+				ReturnType:  TupleType,
+				ReturnTypes: []DataStructure{IntType, BoolType},
+			},
+			`func foo() (int, bool) {
+return 1, true
 }`,
 		},
 	}
@@ -337,6 +373,42 @@ func TestFunctionLiteral_Signature(t *testing.T) {
 	}
 }
 
+func TestPos_String(t *testing.T) {
+	tests := []struct {
+		input         Pos
+		expected      string
+		expectedValid bool
+	}{
+		{
+			Pos{Line: 3, Column: 9},
+			"line 3, column 9",
+			true,
+		},
+		{
+			Pos{File: "math.koa", Line: 3, Column: 9},
+			"math.koa, line 3, column 9",
+			true,
+		},
+		{
+			Pos{File: "math.koa", Line: 3, Column: 9, Synthetic: true},
+			"math.koa, line 3, column 9 (synthetic)",
+			true,
+		},
+		{
+			Pos{Synthetic: true},
+			"line 0, column 0 (synthetic)",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		testString(t, tt.input.String(), tt.expected)
+		if tt.input.IsValid() != tt.expectedValid {
+			t.Errorf("IsValid() wrong result. expected=%t, got=%t", tt.expectedValid, tt.input.IsValid())
+		}
+	}
+}
+
 func testString(t *testing.T, got, expected string) {
 	t.Helper()
 	if got != expected {
@@ -0,0 +1,340 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// JSONVersion is the version of the JSON form of AST, which is
+// described by docs/ast.schema.json. It is increased whenever the
+// form changes incompatibly, so that external consumers can reject
+// the form they don't know.
+const JSONVersion = "1"
+
+// MarshalContract returns the JSON form of contract. Every node is an
+// object with "node" field which is the name of its type, e.g.
+//
+//	{"node": "Identifier", "name": "a"}
+//
+// Integer value is a string, because it may not fit in the number
+// of JSON consumers.
+func MarshalContract(c *Contract) ([]byte, error) {
+	return json.MarshalIndent(map[string]interface{}{
+		"version":  JSONVersion,
+		"contract": jsonOfContract(c),
+	}, "", "  ")
+}
+
+type jsonObject map[string]interface{}
+
+func jsonOfContract(c *Contract) jsonObject {
+	interfaces := make([]interface{}, 0, len(c.Interfaces))
+	for _, i := range c.Interfaces {
+		functions := make([]interface{}, 0, len(i.Functions))
+		for _, f := range i.Functions {
+			functions = append(functions, jsonOfFunction(f))
+		}
+		interfaces = append(interfaces, jsonObject{
+			"node":      "Interface",
+			"name":      i.Name.Name,
+			"functions": functions,
+		})
+	}
+
+	constants := make([]interface{}, 0, len(c.Constants))
+	for _, cs := range c.Constants {
+		constants = append(constants, jsonOfStatement(cs))
+	}
+
+	enums := make([]interface{}, 0, len(c.Enums))
+	for _, e := range c.Enums {
+		enums = append(enums, jsonObject{
+			"node":    "EnumLiteral",
+			"name":    e.Name.Name,
+			"members": names(e.Members),
+		})
+	}
+
+	errs := make([]interface{}, 0, len(c.Errors))
+	for _, e := range c.Errors {
+		errs = append(errs, jsonObject{
+			"node":       "ErrorLiteral",
+			"name":       e.Name.Name,
+			"parameters": jsonOfParameters(e.Parameters),
+		})
+	}
+
+	modifiers := make([]interface{}, 0, len(c.Modifiers))
+	for _, m := range c.Modifiers {
+		modifiers = append(modifiers, jsonObject{
+			"node": "ModifierLiteral",
+			"name": m.Name.Name,
+			"body": jsonOfBlock(m.Body),
+		})
+	}
+
+	functions := make([]interface{}, 0, len(c.Functions))
+	for _, f := range c.Functions {
+		functions = append(functions, jsonOfFunction(f))
+	}
+
+	return jsonObject{
+		"node":              "Contract",
+		"interfaces":        interfaces,
+		"implements":        names(c.Implements),
+		"supportsInterface": c.SupportsInterface,
+		"constants":         constants,
+		"enums":             enums,
+		"errors":            errs,
+		"modifiers":         modifiers,
+		"functions":         functions,
+	}
+}
+
+// jsonOfFunction returns function literal, body of function in
+// interface is null
+func jsonOfFunction(f *FunctionLiteral) jsonObject {
+	returnTypes := make([]string, 0, len(f.ReturnTypes))
+	for _, t := range f.ReturnTypes {
+		returnTypes = append(returnTypes, t.String())
+	}
+
+	return jsonObject{
+		"node":        "FunctionLiteral",
+		"name":        f.Name.Name,
+		"parameters":  jsonOfParameters(f.Parameters),
+		"modifiers":   names(f.Modifiers),
+		"returnType":  f.ReturnType.String(),
+		"returnTypes": returnTypes,
+		"body":        jsonOfBlock(f.Body),
+	}
+}
+
+func jsonOfParameters(ps []*ParameterLiteral) []interface{} {
+	params := make([]interface{}, 0, len(ps))
+	for _, p := range ps {
+		params = append(params, jsonObject{
+			"node":    "ParameterLiteral",
+			"name":    p.Identifier.Name,
+			"type":    p.Type.String(),
+			"default": jsonOfExpression(p.Default),
+		})
+	}
+	return params
+}
+
+func jsonOfBlock(b *BlockStatement) interface{} {
+	if b == nil {
+		return nil
+	}
+
+	statements := make([]interface{}, 0, len(b.Statements))
+	for _, s := range b.Statements {
+		statements = append(statements, jsonOfStatement(s))
+	}
+
+	return jsonObject{
+		"node":       "BlockStatement",
+		"statements": statements,
+	}
+}
+
+func jsonOfStatement(s Statement) interface{} {
+	switch stmt := s.(type) {
+	case nil:
+		return nil
+	case *AssignStatement:
+		return jsonObject{
+			"node":     "AssignStatement",
+			"type":     stmt.Type.String(),
+			"variable": stmt.Variable.Name,
+			"value":    jsonOfExpression(stmt.Value),
+		}
+	case *ConstStatement:
+		return jsonObject{
+			"node":  "ConstStatement",
+			"type":  stmt.Type.String(),
+			"name":  stmt.Name.Name,
+			"value": jsonOfExpression(stmt.Value),
+		}
+	case *TupleAssignStatement:
+		types := make([]string, 0, len(stmt.Types))
+		for _, t := range stmt.Types {
+			types = append(types, t.String())
+		}
+		variables := make([]string, 0, len(stmt.Variables))
+		for _, v := range stmt.Variables {
+			variables = append(variables, v.Name)
+		}
+		return jsonObject{
+			"node":      "TupleAssignStatement",
+			"types":     types,
+			"variables": variables,
+			"value":     jsonOfExpression(stmt.Value),
+		}
+	case *ReassignStatement:
+		return jsonObject{
+			"node":     "ReassignStatement",
+			"variable": stmt.Variable.Name,
+			"value":    jsonOfExpression(stmt.Value),
+		}
+	case *ReturnStatement:
+		return jsonObject{
+			"node":  "ReturnStatement",
+			"value": jsonOfExpression(stmt.ReturnValue),
+		}
+	case *RevertStatement:
+		args := make([]interface{}, 0, len(stmt.Arguments))
+		for _, a := range stmt.Arguments {
+			args = append(args, jsonOfExpression(a))
+		}
+		return jsonObject{
+			"node":      "RevertStatement",
+			"error":     stmt.Error.Name,
+			"arguments": args,
+		}
+	case *IfStatement:
+		return jsonObject{
+			"node":        "IfStatement",
+			"condition":   jsonOfExpression(stmt.Condition),
+			"consequence": jsonOfBlock(stmt.Consequence),
+			"alternative": jsonOfBlock(stmt.Alternative),
+		}
+	case *ForStatement:
+		return jsonObject{
+			"node":      "ForStatement",
+			"init":      jsonOfStatement(stmt.Init),
+			"condition": jsonOfExpression(stmt.Condition),
+			"post":      jsonOfStatement(stmt.Post),
+			"body":      jsonOfBlock(stmt.Body),
+		}
+	case *DoWhileStatement:
+		return jsonObject{
+			"node":      "DoWhileStatement",
+			"body":      jsonOfBlock(stmt.Body),
+			"condition": jsonOfExpression(stmt.Condition),
+		}
+	case *BlockStatement:
+		return jsonOfBlock(stmt)
+	case *ExpressionStatement:
+		return jsonObject{
+			"node":       "ExpressionStatement",
+			"expression": jsonOfExpression(stmt.Expr),
+		}
+	case *PlaceholderStatement:
+		return jsonObject{
+			"node": "PlaceholderStatement",
+		}
+	default:
+		panic(fmt.Sprintf("jsonOfStatement() - unknown statement %T", s))
+	}
+}
+
+func jsonOfExpression(e Expression) interface{} {
+	switch expr := e.(type) {
+	case nil:
+		return nil
+	case *Identifier:
+		return jsonObject{
+			"node": "Identifier",
+			"name": expr.Name,
+		}
+	case *IntegerLiteral:
+		return jsonObject{
+			"node":  "IntegerLiteral",
+			"value": strconv.FormatInt(expr.Value, 10),
+		}
+	case *StringLiteral:
+		return jsonObject{
+			"node":  "StringLiteral",
+			"value": expr.Value,
+		}
+	case *BytesLiteral:
+		return jsonObject{
+			"node":  "BytesLiteral",
+			"value": hex.EncodeToString(expr.Value),
+		}
+	case *AddressLiteral:
+		return jsonObject{
+			"node":  "AddressLiteral",
+			"value": expr.Value.String(),
+		}
+	case *BooleanLiteral:
+		return jsonObject{
+			"node":  "BooleanLiteral",
+			"value": expr.Value,
+		}
+	case *PrefixExpression:
+		return jsonObject{
+			"node":     "PrefixExpression",
+			"operator": expr.Operator.String(),
+			"right":    jsonOfExpression(expr.Right),
+		}
+	case *InfixExpression:
+		return jsonObject{
+			"node":     "InfixExpression",
+			"left":     jsonOfExpression(expr.Left),
+			"operator": expr.Operator.String(),
+			"right":    jsonOfExpression(expr.Right),
+		}
+	case *TupleExpression:
+		elements := make([]interface{}, 0, len(expr.Elements))
+		for _, el := range expr.Elements {
+			elements = append(elements, jsonOfExpression(el))
+		}
+		return jsonObject{
+			"node":     "TupleExpression",
+			"elements": elements,
+		}
+	case *IndexExpression:
+		return jsonObject{
+			"node":  "IndexExpression",
+			"left":  jsonOfExpression(expr.Left),
+			"index": jsonOfExpression(expr.Index),
+		}
+	case *SelectorExpression:
+		return jsonObject{
+			"node":  "SelectorExpression",
+			"left":  expr.Left.Name,
+			"field": expr.Field.Name,
+		}
+	case *CallExpression:
+		args := make([]interface{}, 0, len(expr.Arguments))
+		for _, arg := range expr.Arguments {
+			args = append(args, jsonOfExpression(arg))
+		}
+		return jsonObject{
+			"node":      "CallExpression",
+			"function":  jsonOfExpression(expr.Function),
+			"arguments": args,
+		}
+	default:
+		panic(fmt.Sprintf("jsonOfExpression() - unknown expression %T", e))
+	}
+}
+
+func names(idents []*Identifier) []string {
+	result := make([]string, 0, len(idents))
+	for _, i := range idents {
+		result = append(result, i.Name)
+	}
+	return result
+}
@@ -0,0 +1,295 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/parse"
+)
+
+func TestMarshalContract(t *testing.T) {
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(`
+contract {
+	func foo(a int) int {
+		return a + 1
+	}
+}`)))
+	if err != nil {
+		t.Fatalf("Parse() returns unexpected error: %s", err)
+	}
+
+	b, err := ast.MarshalContract(contract)
+	if err != nil {
+		t.Fatalf("MarshalContract() returns unexpected error: %s", err)
+	}
+
+	expected := `{
+  "contract": {
+    "constants": [],
+    "enums": [],
+    "errors": [],
+    "functions": [
+      {
+        "body": {
+          "node": "BlockStatement",
+          "statements": [
+            {
+              "node": "ReturnStatement",
+              "value": {
+                "left": {
+                  "name": "a",
+                  "node": "Identifier"
+                },
+                "node": "InfixExpression",
+                "operator": "+",
+                "right": {
+                  "node": "IntegerLiteral",
+                  "value": "1"
+                }
+              }
+            }
+          ]
+        },
+        "modifiers": [],
+        "name": "foo",
+        "node": "FunctionLiteral",
+        "parameters": [
+          {
+            "default": null,
+            "name": "a",
+            "node": "ParameterLiteral",
+            "type": "int"
+          }
+        ],
+        "returnType": "int",
+        "returnTypes": []
+      }
+    ],
+    "implements": [],
+    "interfaces": [],
+    "modifiers": [],
+    "node": "Contract",
+    "supportsInterface": false
+  },
+  "version": "1"
+}`
+	if string(b) != expected {
+		t.Errorf("MarshalContract() wrong result.\nexpected=%s\ngot=%s", expected, b)
+	}
+}
+
+// TestMarshalContract_schema validates the JSON form of contract using
+// every kind of node against docs/ast.schema.json
+func TestMarshalContract_schema(t *testing.T) {
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(`
+pragma erc165
+interface Token {
+	func balance(owner int) int
+}
+contract implements Token {
+	const int FEE = 10
+	const address ADMIN = 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed
+	enum Status { Pending, Active }
+	error Invalid(got int)
+	modifier positive {
+		require(FEE > 0, "fee")
+		_
+	}
+	func balance(owner int) int {
+		return owner
+	}
+	func pair(a int) (int, bool) {
+		return a, true
+	}
+	func foo(a int, b int = 2) positive int {
+		int x, bool ok = pair(a)
+		bytes data = 0x"beef"
+		int sum = 0
+		do {
+			sum = sum + 1
+		} while (sum < 1)
+		for (int i = 0; i < b; i = i + 1) {
+			sum = sum + data[i]
+		}
+		if (!ok || x == -1) {
+			return block.number
+		} else {
+			sum = sum * 2
+		}
+		return sum
+	}
+	func bar() {
+		revert Invalid(Status.Active)
+	}
+}`)))
+	if err != nil {
+		t.Fatalf("Parse() returns unexpected error: %s", err)
+	}
+
+	b, err := ast.MarshalContract(contract)
+	if err != nil {
+		t.Fatalf("MarshalContract() returns unexpected error: %s", err)
+	}
+
+	raw, err := ioutil.ReadFile("../docs/ast.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("invalid schema: %s", err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := validate(schema, schema, doc, "$"); err != nil {
+		t.Errorf("MarshalContract() doesn't match schema: %s", err)
+	}
+
+	// every node of the output is defined by schema
+	definitions := schema["definitions"].(map[string]interface{})
+	for _, node := range nodesOf(doc) {
+		if _, ok := definitions[node]; !ok {
+			t.Errorf("node %s is not defined by schema", node)
+		}
+	}
+}
+
+// validate checks value against the subset of JSON Schema which
+// docs/ast.schema.json uses
+func validate(root, schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		def, ok := root["definitions"].(map[string]interface{})[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: undefined %s", path, ref)
+		}
+		return validate(root, def, value, path)
+	}
+
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, value) {
+		return fmt.Errorf("%s: expected %v, got %v", path, c, value)
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || reflect.DeepEqual(e, value)
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+		}
+	}
+
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matched := 0
+		for _, s := range oneOf {
+			if validate(root, s.(map[string]interface{}), value, path) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fmt.Errorf("%s: %d schemas of oneOf match %v", path, matched, value)
+		}
+	}
+
+	switch schema["type"] {
+	case nil:
+		return nil
+	case "null":
+		if value != nil {
+			return fmt.Errorf("%s: expected null", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean", path)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string", path)
+		}
+		if p, ok := schema["pattern"].(string); ok && !regexp.MustCompile(p).MatchString(s) {
+			return fmt.Errorf("%s: %s doesn't match %s", path, s, p)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array", path)
+		}
+		for i, item := range items {
+			if err := validate(root, schema["items"].(map[string]interface{}), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object", path)
+		}
+		for _, r := range schema["required"].([]interface{}) {
+			if _, ok := obj[r.(string)]; !ok {
+				return fmt.Errorf("%s: missing %s", path, r)
+			}
+		}
+		props := schema["properties"].(map[string]interface{})
+		for k, v := range obj {
+			p, ok := props[k]
+			if !ok {
+				return fmt.Errorf("%s: unexpected %s", path, k)
+			}
+			if err := validate(root, p.(map[string]interface{}), v, path+"."+k); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%s: unsupported type %v", path, schema["type"])
+	}
+
+	return nil
+}
+
+// nodesOf returns the names of every node in JSON value
+func nodesOf(value interface{}) []string {
+	nodes := make([]string, 0)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if node, ok := v["node"].(string); ok {
+			nodes = append(nodes, node)
+		}
+		for _, e := range v {
+			nodes = append(nodes, nodesOf(e)...)
+		}
+	case []interface{}:
+		for _, e := range v {
+			nodes = append(nodes, nodesOf(e)...)
+		}
+	}
+	return nodes
+}
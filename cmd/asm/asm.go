@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package asm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/DE-labtory/koa/translate"
+	"github.com/urfave/cli"
+)
+
+var asmCmd = cli.Command{
+	Name:  "asm",
+	Usage: "koa asm [filePath], koa asm --disassemble [raw byte code]",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "disassemble, d",
+			Usage: "print raw byte code in text format",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.Bool("disassemble") {
+			return disassemble(c.Args().Get(0))
+		}
+		return assemble(c.Args().Get(0))
+	},
+}
+
+func Cmd() cli.Command {
+	return asmCmd
+}
+
+func assemble(path string) error {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	asm, err := translate.Assemble(string(file))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%x\n", asm.ToRawByteCode())
+	return nil
+}
+
+func disassemble(rawByteCode string) error {
+	raw, err := hex.DecodeString(strings.TrimPrefix(rawByteCode, "0x"))
+	if err != nil {
+		return err
+	}
+
+	asm, err := translate.Disassemble(raw)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(asm.Text())
+	return nil
+}
@@ -0,0 +1,279 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/DE-labtory/koa/ast"
+	parser "github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/typecheck"
+	"github.com/urfave/cli"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://schemastore.azurewebsites.net/schemas/json/sarif-2.1.0-rtm.5.json"
+
+	syntaxErrorRule = "syntax-error"
+	typeErrorRule   = "type-error"
+)
+
+var checkCmd = cli.Command{
+	Name:  "check",
+	Usage: "koa check [--format text|sarif] [filePath]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format, f",
+			Value: "text",
+			Usage: "output format of findings, text or sarif",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		return check(c.Args().Get(0), c.String("format"))
+	},
+}
+
+func Cmd() cli.Command {
+	return checkCmd
+}
+
+// Finding is a single problem found while checking a source file.
+// Line is 1-based, and zero when the position is unknown.
+type Finding struct {
+	Rule    string
+	Message string
+	Line    int
+}
+
+func check(path string, format string) error {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	findings := CheckFile(string(file), path)
+
+	switch format {
+	case "text":
+		for _, f := range findings {
+			fmt.Printf("%s: %s: %s\n", path, f.Rule, f.Message)
+		}
+		if len(findings) != 0 {
+			return fmt.Errorf("%d problem(s) found", len(findings))
+		}
+		return nil
+	case "sarif":
+		b, err := json.MarshalIndent(ToSarif(path, findings), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	default:
+		return fmt.Errorf("unknown check format [%s]", format)
+	}
+}
+
+// Check parses and type checks input, and returns every
+// finding reported by parser and type checker
+func Check(input string) []Finding {
+	contract, err := parser.Parse(
+		parser.NewTokenBuffer(
+			parser.NewLexer(input)))
+	return checkContract(contract, err)
+}
+
+// CheckFile is like Check but input is the source of the file of path,
+// whose imports are read relative to the directory of the file as
+// koa compile does
+func CheckFile(input string, path string) []Finding {
+	contract, err := parser.ParseReader(strings.NewReader(input), path)
+
+	// findings are reported with path already
+	if fe, ok := err.(parser.FileError); ok {
+		err = fe.Err
+	}
+	return checkContract(contract, err)
+}
+
+// checkContract type checks contract unless parser failed with err
+func checkContract(contract *ast.Contract, err error) []Finding {
+	if err != nil {
+		return []Finding{
+			{
+				Rule:    syntaxErrorRule,
+				Message: err.Error(),
+				Line:    syntaxErrorLine(err),
+			},
+		}
+	}
+
+	findings := []Finding{}
+
+	errs, ok := typecheck.Check(contract).(typecheck.Errors)
+	if !ok {
+		return findings
+	}
+
+	for _, e := range errs {
+		f := Finding{
+			Rule:    typeErrorRule,
+			Message: e.Error(),
+		}
+		if e.Pos.IsValid() {
+			f.Line = e.Pos.Line + 1
+		}
+		findings = append(findings, f)
+	}
+
+	return findings
+}
+
+// syntaxErrorLine returns 1-based line of the token which
+// parser failed on. Error in imported library is reported on
+// the line of import directive.
+func syntaxErrorLine(err error) int {
+	switch e := err.(type) {
+	case parser.Error:
+		return e.Source.Line + 1
+	case parser.ExpectError:
+		return e.Source.Line + 1
+	case parser.DupSymError:
+		return e.Source.Line + 1
+	case parser.PrefixError:
+		return e.Source.Line + 1
+	case parser.NotExistSymError:
+		return e.Source.Line + 1
+	case parser.ConstAssignError:
+		return e.Source.Line + 1
+	case parser.LimitError:
+		return e.Source.Line + 1
+	case parser.ImportError:
+		return e.Source.Line + 1
+	case parser.FileError:
+		return syntaxErrorLine(e.Err)
+	default:
+		return 0
+	}
+}
+
+// SarifLog is the root object of SARIF 2.1.0 document
+type SarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription SarifMessage `json:"shortDescription"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   SarifMessage    `json:"message"`
+	Locations []SarifLocation `json:"locations"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	Region           *SarifRegion          `json:"region,omitempty"`
+}
+
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// ToSarif converts findings of the file in path to SARIF log. The
+// artifact uri is slash separated whatever the platform is.
+func ToSarif(path string, findings []Finding) SarifLog {
+	results := make([]SarifResult, 0, len(findings))
+	for _, f := range findings {
+		loc := SarifPhysicalLocation{
+			ArtifactLocation: SarifArtifactLocation{URI: filepath.ToSlash(path)},
+		}
+		if f.Line > 0 {
+			loc.Region = &SarifRegion{StartLine: f.Line}
+		}
+
+		results = append(results, SarifResult{
+			RuleID:    f.Rule,
+			Level:     "error",
+			Message:   SarifMessage{Text: f.Message},
+			Locations: []SarifLocation{{PhysicalLocation: loc}},
+		})
+	}
+
+	return SarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []SarifRun{
+			{
+				Tool: SarifTool{
+					Driver: SarifDriver{
+						Name:           "koa",
+						InformationURI: "https://github.com/DE-labtory/koa",
+						Rules: []SarifRule{
+							{
+								ID:               syntaxErrorRule,
+								ShortDescription: SarifMessage{Text: "source code could not be parsed"},
+							},
+							{
+								ID:               typeErrorRule,
+								ShortDescription: SarifMessage{Text: "expression has unexpected type"},
+							},
+						},
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package check
+
+import (
+	"errors"
+	"testing"
+
+	parser "github.com/DE-labtory/koa/parse"
+)
+
+func Test_syntaxErrorLine(t *testing.T) {
+	source := parser.Token{Type: parser.Ident, Val: "a", Line: 2}
+
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{err: parser.Error{Source: source}, expected: 3},
+		{err: parser.ExpectError{Source: source, Expected: parser.Assign}, expected: 3},
+		{err: parser.DupSymError{Source: source}, expected: 3},
+		{err: parser.PrefixError{Source: source}, expected: 3},
+		{err: parser.NotExistSymError{Source: source}, expected: 3},
+		{err: parser.ConstAssignError{Source: source}, expected: 3},
+		{err: parser.LimitError{Source: source, Limit: "functions", Max: 1}, expected: 3},
+		{
+			err: parser.ImportError{
+				Source: parser.Token{Type: parser.String, Val: `"lib.koa"`},
+				Path:   "lib.koa",
+				Err:    parser.Error{Source: source},
+			},
+			expected: 1,
+		},
+		{err: parser.FileError{Filename: "main.koa", Err: parser.ConstAssignError{Source: source}}, expected: 3},
+		{err: errors.New("unknown"), expected: 0},
+	}
+
+	for i, test := range tests {
+		if line := syntaxErrorLine(test.err); line != test.expected {
+			t.Errorf("test[%d] - syntaxErrorLine() of %T wrong result. expected=%d, got=%d", i, test.err, test.expected, line)
+		}
+	}
+}
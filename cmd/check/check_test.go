@@ -0,0 +1,163 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package check_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/DE-labtory/koa/cmd/check"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []check.Finding
+	}{
+		{
+			input: `contract {
+	func foo() int {
+		return 1
+	}
+}`,
+			expected: []check.Finding{},
+		},
+		{
+			input: `contract {
+	func foo() string {
+		int a = true
+		return 1
+	}
+}`,
+			expected: []check.Finding{
+				{
+					Rule:    "type-error",
+					Message: "[line 2, column 5] [int a = true] cannot assign bool to a (type int)",
+					Line:    3,
+				},
+				{
+					Rule:    "type-error",
+					Message: "[line 3, column 8] [return 1] cannot return int in function foo (return type string)",
+					Line:    4,
+				},
+			},
+		},
+		{
+			input: `contract {
+	func foo( {
+	}
+}`,
+			expected: []check.Finding{
+				{
+					Rule:    "syntax-error",
+					Message: "[line 1, column 12] Expected [IDENT], but got [LBRACE]",
+					Line:    2,
+				},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		findings := check.Check(tt.input)
+		if !reflect.DeepEqual(findings, tt.expected) {
+			t.Errorf("test[%d] - Check() result wrong.\nexpected=%v\ngot=%v", i, tt.expected, findings)
+		}
+	}
+}
+
+func TestCheckFile_import(t *testing.T) {
+	dir, err := ioutil.TempDir("", "koa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "lib.koa"), []byte("const int FEE = 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	findings := check.CheckFile(`import "lib.koa"
+contract {
+	func foo() int {
+		return FEE
+	}
+}`, filepath.Join(dir, "main.koa"))
+	if len(findings) != 0 {
+		t.Errorf("CheckFile() returns unexpected findings: %v", findings)
+	}
+
+	findings = check.CheckFile(`import "missing.koa"
+contract {
+}`, filepath.Join(dir, "main.koa"))
+	if len(findings) != 1 || findings[0].Rule != "syntax-error" || findings[0].Line != 1 {
+		t.Errorf("CheckFile() wrong findings of missing import. got=%v", findings)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "broken.koa"), []byte("const int FEE = 1\nconst int FEE = 2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	findings = check.CheckFile(`import "lib.koa"
+import "broken.koa"
+contract {
+}`, filepath.Join(dir, "main.koa"))
+	if len(findings) != 1 || findings[0].Rule != "syntax-error" || findings[0].Line != 2 {
+		t.Errorf("CheckFile() wrong findings of broken import. got=%v", findings)
+	}
+}
+
+func TestToSarif(t *testing.T) {
+	log := check.ToSarif("test.koa", []check.Finding{
+		{Rule: "type-error", Message: "no position"},
+		{Rule: "syntax-error", Message: "with position", Line: 3},
+	})
+
+	if log.Version != "2.1.0" {
+		t.Errorf("wrong sarif version. expected=2.1.0, got=%s", log.Version)
+	}
+
+	results := log.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("wrong number of results. expected=2, got=%d", len(results))
+	}
+
+	if results[0].Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("finding without line should not have region")
+	}
+
+	region := results[1].Locations[0].PhysicalLocation.Region
+	if region == nil || region.StartLine != 3 {
+		t.Errorf("wrong region of finding. expected startLine=3, got=%v", region)
+	}
+
+	if uri := results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "test.koa" {
+		t.Errorf("wrong artifact uri. expected=test.koa, got=%s", uri)
+	}
+}
+
+func TestToSarif_slashSeparatedURI(t *testing.T) {
+	log := check.ToSarif(filepath.Join("contracts", "test.koa"), []check.Finding{
+		{Rule: "type-error", Message: "no position"},
+	})
+
+	if uri := log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "contracts/test.koa" {
+		t.Errorf("wrong artifact uri. expected=contracts/test.koa, got=%s", uri)
+	}
+}
@@ -1,14 +1,19 @@
 package compile
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"text/tabwriter"
 
 	"github.com/DE-labtory/koa/abi"
+	"github.com/DE-labtory/koa/natspec"
 
 	parser "github.com/DE-labtory/koa/parse"
 	"github.com/DE-labtory/koa/translate"
+	"github.com/DE-labtory/koa/typecheck"
 	"github.com/urfave/cli"
 )
 
@@ -16,6 +21,18 @@ type Result struct {
 	Abi     *abi.ABI
 	Asm     string
 	RawByte string
+
+	// Functions has the size and stack usage of each function
+	Functions []translate.FunctionReport `json:",omitempty"`
+
+	// Docs has the documentation of functions declared in the file
+	// by their names
+	Docs map[string]natspec.Doc `json:",omitempty"`
+
+	// UserDoc and DevDoc are the documentation of contract and its
+	// functions for users and developers
+	UserDoc natspec.UserDoc
+	DevDoc  natspec.DevDoc
 }
 
 var compileCmd = cli.Command{
@@ -37,14 +54,16 @@ func compile(path string) error {
 		return err
 	}
 
-	contract, err := parser.Parse(
-		parser.NewTokenBuffer(
-			parser.NewLexer(string(file))))
-
+	// imports are relative to the directory of the contract
+	contract, err := parser.ParseReader(bytes.NewReader(file), path)
 	if err != nil {
 		return err
 	}
 
+	if err := typecheck.Check(contract); err != nil {
+		return err
+	}
+
 	asm, err := translate.CompileContract(*contract)
 	if err != nil {
 		return err
@@ -55,20 +74,47 @@ func compile(path string) error {
 		return err
 	}
 
-	if err := PrintCompileResult(asm, ab); err != nil {
+	reports, err := translate.ReportFunctions(*contract)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	// report goes to stderr, so that stdout stays valid JSON
+	if err := printReport(reports); err != nil {
+		return err
+	}
+
+	user, dev := natspec.Build(string(file), contract)
+	return printResult(Result{
+		Abi:       ab,
+		Asm:       asm.String(),
+		RawByte:   fmt.Sprintf("%x", asm.ToRawByteCode()),
+		Functions: reports,
+		Docs:      natspec.Extract(string(file)),
+		UserDoc:   user,
+		DevDoc:    dev,
+	})
 }
 
 func PrintCompileResult(asm translate.Asm, ab *abi.ABI) error {
-	result := Result{
+	return printResult(Result{
 		Abi:     ab,
 		Asm:     asm.String(),
 		RawByte: fmt.Sprintf("%x", asm.ToRawByteCode()),
+	})
+}
+
+// printReport prints table of the size and stack usage of functions
+func printReport(reports []translate.FunctionReport) error {
+	w := tabwriter.NewWriter(os.Stderr, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "FUNCTION\tSIZE\tMAX STACK")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", r.Signature, r.Size, r.MaxStack)
 	}
+	return w.Flush()
+}
 
+func printResult(result Result) error {
 	b, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return err
@@ -1,13 +1,18 @@
 package execute
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/DE-labtory/koa"
 	"github.com/DE-labtory/koa/abi"
+	"github.com/DE-labtory/koa/encoding"
+	"github.com/DE-labtory/koa/vm"
 	"github.com/urfave/cli"
 )
 
@@ -15,14 +20,62 @@ var executeCmd = cli.Command{
 	Name:    "execute",
 	Aliases: []string{"e"},
 	Usage:   "koa execute [raw byte code] [function name] [args...]",
+	Flags: []cli.Flag{
+		cli.Int64Flag{
+			Name:  "chain-id",
+			Usage: "chain id which chainid() returns",
+		},
+		cli.Int64Flag{
+			Name:  "block-number",
+			Usage: "block number which block.number returns",
+		},
+		cli.Int64Flag{
+			Name:  "timestamp",
+			Usage: "block timestamp which block.timestamp returns",
+		},
+		cli.StringFlag{
+			Name:  "caller",
+			Usage: "address which msg.sender returns, mixed-case address should have valid checksum",
+		},
+		cli.StringFlag{
+			Name:  "returns",
+			Usage: "comma separated types of returned values to print the result as, e.g. int,address",
+		},
+		cli.BoolFlag{
+			Name:  "sandbox",
+			Usage: "limit steps, memory and stack as for untrusted contract",
+		},
+	},
 	Action: func(c *cli.Context) error {
 		if len(c.Args()) < 2 {
 			return errors.New("you must input at least byte code and function name")
 		}
+		var caller []byte
+		if c.String("caller") != "" {
+			decoded, err := encoding.DecodeAddress(c.String("caller"))
+			if err != nil {
+				return err
+			}
+			caller = decoded
+		}
+		env := koa.Env{
+			ChainID:     c.Int64("chain-id"),
+			BlockNumber: c.Int64("block-number"),
+			Timestamp:   c.Int64("timestamp"),
+			Caller:      caller,
+		}
+		limits := vm.Limits{}
+		if c.Bool("sandbox") {
+			limits = vm.Sandbox
+		}
+		var returns []string
+		if c.String("returns") != "" {
+			returns = strings.Split(c.String("returns"), ",")
+		}
 		if len(c.Args()) == 2 {
-			return execute(c.Args().Get(0), c.Args().Get(1), nil)
+			return execute(env, limits, c.Args().Get(0), c.Args().Get(1), nil, returns)
 		}
-		return execute(c.Args().Get(0), c.Args().Get(1), c.Args()[2:])
+		return execute(env, limits, c.Args().Get(0), c.Args().Get(1), c.Args()[2:], returns)
 	},
 }
 
@@ -30,7 +83,7 @@ func Cmd() cli.Command {
 	return executeCmd
 }
 
-func execute(rawByteCode string, functionName string, args []string) error {
+func execute(env koa.Env, limits vm.Limits, rawByteCode string, functionName string, args []string, returns []string) error {
 	fnSel := abi.Selector(functionName)
 	params, err := encodeParams(args)
 	if err != nil {
@@ -42,19 +95,29 @@ func execute(rawByteCode string, functionName string, args []string) error {
 		return err
 	}
 
-	result, err := koa.Execute(contractDecoding, fnSel, params)
+	result, err := koa.ExecuteLimited(env, limits, contractDecoding, fnSel, params)
 	if err != nil {
 		return err
 	}
 
-	printExecuteResult(result)
-
-	return nil
+	return printExecuteResult(result, returns)
 }
 
 func encodeParams(params []string) ([]byte, error) {
 	ps := make([]interface{}, len(params))
 	for idx, oneParam := range params {
+		// check param is address
+		if len(oneParam) == 2+encoding.AddressLength*2 && strings.HasPrefix(oneParam, "0x") {
+			decoded, err := encoding.DecodeAddress(oneParam)
+			if err != nil {
+				return nil, err
+			}
+			var address encoding.Address
+			copy(address[:], decoded)
+			ps[idx] = address
+			continue
+		}
+
 		// check param is integer
 		if iVal, err := strconv.ParseInt(oneParam, 10, 64); err == nil {
 			ps[idx] = iVal
@@ -80,6 +143,58 @@ func encodeParams(params []string) ([]byte, error) {
 	return result, nil
 }
 
-func printExecuteResult(result []byte) {
-	fmt.Printf("execute Result: %s\n", string(result))
+// printExecuteResult prints result, formatting values by returns if
+// they are given
+func printExecuteResult(result []byte, returns []string) error {
+	if len(returns) == 0 {
+		fmt.Printf("execute Result: %s\n", string(result))
+		return nil
+	}
+
+	values, err := formatResult(result, returns)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("execute Result: %s\n", strings.Join(values, ", "))
+	return nil
+}
+
+// formatResult formats each word of result as its type in returns.
+// Address takes three words and is formatted with checksum.
+func formatResult(result []byte, returns []string) ([]string, error) {
+	values := make([]string, 0, len(returns))
+	for _, typ := range returns {
+		words := 1
+		if typ == "address" {
+			words = 3
+		}
+		if len(result) < words*8 {
+			return nil, fmt.Errorf("result has fewer values than returns %s", strings.Join(returns, ","))
+		}
+
+		word := int64(binary.BigEndian.Uint64(result))
+		switch typ {
+		case "int", "int8", "int16", "int32", "int64":
+			values = append(values, strconv.FormatInt(word, 10))
+		case "uint":
+			values = append(values, strconv.FormatUint(uint64(word), 10))
+		case "bool":
+			values = append(values, strconv.FormatBool(word != 0))
+		case "string":
+			values = append(values, string(bytes.TrimRight(result[:8], "\x00")))
+		case "bytes":
+			values = append(values, "0x"+hex.EncodeToString(encoding.UnpackBytes(word)))
+		case "address":
+			address := encoding.WordsToAddress([3]int64{
+				word,
+				int64(binary.BigEndian.Uint64(result[8:16])),
+				int64(binary.BigEndian.Uint64(result[16:24])),
+			})
+			values = append(values, address.String())
+		default:
+			return nil, fmt.Errorf("unknown type %s", typ)
+		}
+		result = result[words*8:]
+	}
+	return values, nil
 }
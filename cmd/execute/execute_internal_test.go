@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package execute
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/DE-labtory/koa/abi"
+	"github.com/DE-labtory/koa/encoding"
+)
+
+func Test_encodeParams_address(t *testing.T) {
+	checksum := "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	decoded, err := encoding.DecodeAddress(checksum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := encoding.BytesToAddress(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := abi.Encode(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := encodeParams([]string{checksum})
+	if err != nil {
+		t.Fatalf("encodeParams() returns unexpected error: %s", err)
+	}
+	if !bytes.Equal(result, expected) {
+		t.Errorf("encodeParams() wrong result. expected=%x, got=%x", expected, result)
+	}
+
+	if _, err := encodeParams([]string{"0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}); err == nil {
+		t.Errorf("encodeParams() should fail on wrong checksum")
+	}
+}
+
+func Test_formatResult(t *testing.T) {
+	decoded, err := encoding.DecodeAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := encoding.BytesToAddress(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result []byte
+	for _, word := range []int64{-3, 1, address.Words()[0], address.Words()[1], address.Words()[2]} {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64(word))
+		result = append(result, b...)
+	}
+
+	values, err := formatResult(result, []string{"int", "bool", "address"})
+	if err != nil {
+		t.Fatalf("formatResult() returns unexpected error: %s", err)
+	}
+	expected := []string{"-3", "true", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("formatResult() wrong result. expected=%v, got=%v", expected, values)
+	}
+
+	if _, err := formatResult(result[:16], []string{"int", "address"}); err == nil {
+		t.Errorf("formatResult() should fail on short result")
+	}
+}
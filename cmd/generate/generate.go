@@ -0,0 +1,54 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generate
+
+import (
+	"fmt"
+
+	"github.com/DE-labtory/koa/generate"
+	"github.com/urfave/cli"
+)
+
+var generateCmd = cli.Command{
+	Name:  "generate",
+	Usage: "koa generate [--force] [filePath]",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "force, f",
+			Usage: "generate outputs even if they are up to date",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		return run(c.Args().Get(0), c.Bool("force"))
+	},
+}
+
+func Cmd() cli.Command {
+	return generateCmd
+}
+
+func run(path string, force bool) error {
+	results, err := generate.Run(path, force)
+	for _, r := range results {
+		if r.Skipped {
+			fmt.Printf("%s: up to date\n", r.Output)
+			continue
+		}
+		fmt.Printf("%s: generated by %s\n", r.Output, r.Generator)
+	}
+	return err
+}
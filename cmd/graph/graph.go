@@ -0,0 +1,205 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/DE-labtory/koa/ast"
+	parser "github.com/DE-labtory/koa/parse"
+	"github.com/urfave/cli"
+)
+
+var graphCmd = cli.Command{
+	Name:    "graph",
+	Aliases: []string{"g"},
+	Usage:   "koa graph [--format dot|json] [filePath]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format, f",
+			Value: "dot",
+			Usage: "output format of call graph, dot or json",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		return graph(c.Args().Get(0), c.String("format"))
+	},
+}
+
+func Cmd() cli.Command {
+	return graphCmd
+}
+
+// Call represents the edge of call graph, caller function
+// calls callee function
+type Call struct {
+	Caller string `json:"caller"`
+	Callee string `json:"callee"`
+}
+
+// CallGraph represents which functions in contract call
+// which functions
+type CallGraph struct {
+	Functions []string `json:"functions"`
+	Calls     []Call   `json:"calls"`
+}
+
+func graph(path string, format string) error {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	contract, err := parser.ParseReader(bytes.NewReader(file), path)
+	if err != nil {
+		return err
+	}
+
+	cg := BuildCallGraph(contract)
+
+	switch format {
+	case "dot":
+		fmt.Print(PrintDot(cg))
+	case "json":
+		b, err := json.MarshalIndent(cg, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	default:
+		return fmt.Errorf("unknown graph format [%s]", format)
+	}
+
+	return nil
+}
+
+// BuildCallGraph walks every function of contract and collects
+// call expressions. Each edge appears once, in the order it is
+// first seen.
+func BuildCallGraph(contract *ast.Contract) CallGraph {
+	cg := CallGraph{
+		Functions: []string{},
+		Calls:     []Call{},
+	}
+
+	for _, fn := range contract.Functions {
+		cg.Functions = append(cg.Functions, fn.Name.Name)
+
+		seen := map[string]bool{}
+		for _, callee := range calleesOfBlock(fn.Body) {
+			if seen[callee] {
+				continue
+			}
+			seen[callee] = true
+			cg.Calls = append(cg.Calls, Call{Caller: fn.Name.Name, Callee: callee})
+		}
+	}
+
+	return cg
+}
+
+// PrintDot renders call graph in graphviz DOT language
+func PrintDot(cg CallGraph) string {
+	var out bytes.Buffer
+
+	out.WriteString("digraph contract {\n")
+	for _, fn := range cg.Functions {
+		out.WriteString(fmt.Sprintf("\t%q;\n", fn))
+	}
+	for _, call := range cg.Calls {
+		out.WriteString(fmt.Sprintf("\t%q -> %q;\n", call.Caller, call.Callee))
+	}
+	out.WriteString("}\n")
+
+	return out.String()
+}
+
+func calleesOfBlock(b *ast.BlockStatement) []string {
+	callees := []string{}
+	if b == nil {
+		return callees
+	}
+
+	for _, s := range b.Statements {
+		callees = append(callees, calleesOfStatement(s)...)
+	}
+	return callees
+}
+
+func calleesOfStatement(s ast.Statement) []string {
+	switch stmt := s.(type) {
+	case *ast.AssignStatement:
+		return calleesOfExpression(stmt.Value)
+	case *ast.TupleAssignStatement:
+		return calleesOfExpression(stmt.Value)
+	case *ast.ReassignStatement:
+		return calleesOfExpression(stmt.Value)
+	case *ast.ReturnStatement:
+		return calleesOfExpression(stmt.ReturnValue)
+	case *ast.RevertStatement:
+		callees := []string{}
+		for _, arg := range stmt.Arguments {
+			callees = append(callees, calleesOfExpression(arg)...)
+		}
+		return callees
+	case *ast.ExpressionStatement:
+		return calleesOfExpression(stmt.Expr)
+	case *ast.BlockStatement:
+		return calleesOfBlock(stmt)
+	case *ast.IfStatement:
+		callees := calleesOfExpression(stmt.Condition)
+		callees = append(callees, calleesOfBlock(stmt.Consequence)...)
+		return append(callees, calleesOfBlock(stmt.Alternative)...)
+	case *ast.ForStatement:
+		callees := calleesOfStatement(stmt.Init)
+		callees = append(callees, calleesOfExpression(stmt.Condition)...)
+		callees = append(callees, calleesOfStatement(stmt.Post)...)
+		return append(callees, calleesOfBlock(stmt.Body)...)
+	case *ast.DoWhileStatement:
+		return append(calleesOfBlock(stmt.Body), calleesOfExpression(stmt.Condition)...)
+	default:
+		return []string{}
+	}
+}
+
+func calleesOfExpression(e ast.Expression) []string {
+	switch expr := e.(type) {
+	case *ast.CallExpression:
+		callees := []string{expr.Function.String()}
+		for _, arg := range expr.Arguments {
+			callees = append(callees, calleesOfExpression(arg)...)
+		}
+		return callees
+	case *ast.InfixExpression:
+		return append(calleesOfExpression(expr.Left), calleesOfExpression(expr.Right)...)
+	case *ast.PrefixExpression:
+		return calleesOfExpression(expr.Right)
+	case *ast.IndexExpression:
+		return append(calleesOfExpression(expr.Left), calleesOfExpression(expr.Index)...)
+	case *ast.TupleExpression:
+		callees := []string{}
+		for _, elem := range expr.Elements {
+			callees = append(callees, calleesOfExpression(elem)...)
+		}
+		return callees
+	default:
+		return []string{}
+	}
+}
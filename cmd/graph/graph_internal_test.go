@@ -0,0 +1,67 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graph
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	capturer "github.com/kami-zh/go-capturer"
+)
+
+func Test_graph_import(t *testing.T) {
+	dir, err := ioutil.TempDir("", "koa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	lib := `func double(a int) int {
+	return a * 2
+}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "lib.koa"), []byte(lib), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	main := `import "lib.koa"
+contract {
+	func foo() int {
+		return double(1)
+	}
+}`
+	path := filepath.Join(dir, "main.koa")
+	if err := ioutil.WriteFile(path, []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `digraph contract {
+	"double";
+	"foo";
+	"foo" -> "double";
+}
+`
+	out := capturer.CaptureStdout(func() {
+		if err := graph(path, "dot"); err != nil {
+			t.Errorf("graph() returns unexpected error: %s", err)
+		}
+	})
+	if out != expected {
+		t.Errorf("graph() wrong output.\nexpected=%s\ngot=%s", expected, out)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package graph_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DE-labtory/koa/cmd/graph"
+	"github.com/DE-labtory/koa/parse"
+)
+
+func TestBuildCallGraph(t *testing.T) {
+	input := `
+contract {
+	func foo() int {
+		return bar(1) + bar(2)
+	}
+
+	func bar(a int) int {
+		if (a > baz()) {
+			return a
+		}
+		return 0
+	}
+
+	func baz() int {
+		return 1
+	}
+}`
+
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(input)))
+	if err != nil {
+		t.Fatalf("parser error: %s", err)
+	}
+
+	cg := graph.BuildCallGraph(contract)
+
+	expected := graph.CallGraph{
+		Functions: []string{"foo", "bar", "baz"},
+		Calls: []graph.Call{
+			{Caller: "foo", Callee: "bar"},
+			{Caller: "bar", Callee: "baz"},
+		},
+	}
+	if !reflect.DeepEqual(cg, expected) {
+		t.Fatalf("BuildCallGraph() result wrong.\nexpected=%v\ngot=%v", expected, cg)
+	}
+
+	expectedDot := `digraph contract {
+	"foo";
+	"bar";
+	"baz";
+	"foo" -> "bar";
+	"bar" -> "baz";
+}
+`
+	if dot := graph.PrintDot(cg); dot != expectedDot {
+		t.Fatalf("PrintDot() result wrong.\nexpected=%s\ngot=%s", expectedDot, dot)
+	}
+}
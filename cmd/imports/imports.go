@@ -0,0 +1,385 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package imports
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DE-labtory/koa/ast"
+	parser "github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/typecheck"
+	"github.com/urfave/cli"
+)
+
+var importsCmd = cli.Command{
+	Name:  "imports",
+	Usage: "koa imports [--write] [filePath]",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "write, w",
+			Usage: "write result to the file instead of stdout",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		return imports(c.Args().Get(0), c.Bool("write"))
+	},
+}
+
+func Cmd() cli.Command {
+	return importsCmd
+}
+
+// Result is the source whose imports are organized, with the
+// imports added and removed. Suggestions has the libraries declaring
+// the symbol which more than one library declares, so that none of
+// them is added
+type Result struct {
+	Source      string
+	Added       []string
+	Removed     []string
+	Suggestions map[string][]string
+}
+
+// directive is import directive of source, line is 0-based
+type directive struct {
+	path string
+	line int
+}
+
+func imports(path string, write bool) error {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	libraries, err := findLibraries(dir, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	result, err := Organize(string(file), parser.DirResolver(dir), libraries)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range result.Added {
+		fmt.Printf("%s: add import %q\n", path, p)
+	}
+	for _, p := range result.Removed {
+		fmt.Printf("%s: remove import %q\n", path, p)
+	}
+	for _, name := range sortedKeys(result.Suggestions) {
+		fmt.Printf("%s: %s is declared by %s\n", path, name, strings.Join(result.Suggestions[name], ", "))
+	}
+
+	if !write {
+		fmt.Print(result.Source)
+		return nil
+	}
+	return ioutil.WriteFile(path, []byte(result.Source), 0644)
+}
+
+// findLibraries returns the koa files in dir except the file of name
+func findLibraries(dir string, name string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.koa"))
+	if err != nil {
+		return nil, err
+	}
+
+	libraries := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if filepath.Base(p) != name {
+			libraries = append(libraries, filepath.Base(p))
+		}
+	}
+	return libraries, nil
+}
+
+// Organize adds the import of library which declares the symbol
+// used but not declared in src, and then removes the imports which
+// src doesn't need. libraries are the paths r resolves, which are
+// searched for the undeclared symbols. Paths which are not library
+// are skipped.
+func Organize(src string, r parser.Resolver, libraries []string) (Result, error) {
+	result := Result{Suggestions: map[string][]string{}}
+	declarers := declarersOf(r, libraries)
+
+	for range libraries {
+		undefined, _, err := check(src, r)
+		if err != nil {
+			return Result{}, err
+		}
+
+		added := false
+		for _, name := range undefined {
+			libs := declarers[name]
+			if len(libs) > 1 {
+				result.Suggestions[name] = libs
+				continue
+			}
+			if len(libs) == 0 || isImported(src, libs[0]) {
+				continue
+			}
+
+			src = addImport(src, libs[0])
+			result.Added = append(result.Added, libs[0])
+			added = true
+		}
+
+		if !added {
+			break
+		}
+	}
+
+	undefined, errs, err := check(src, r)
+	if err != nil {
+		return Result{}, err
+	}
+
+	for _, d := range directivesOf(src) {
+		removed := removeImport(src, d.path)
+		u, n, err := check(removed, r)
+		if err != nil || n > errs || !subsetOf(u, undefined) {
+			continue
+		}
+
+		src = removed
+		result.Removed = append(result.Removed, d.path)
+	}
+
+	result.Source = src
+	return result, nil
+}
+
+// check parses and type checks src, and returns the symbols which
+// are not declared and the number of type errors. Error is returned
+// when src can't be parsed for the other reason than undefined symbol.
+func check(src string, r parser.Resolver) ([]string, int, error) {
+	contract, err := parser.ParseImports(context.Background(),
+		parser.NewTokenBuffer(parser.NewLexer(src)), parser.Limits{}, r)
+	if err != nil {
+		e, ok := err.(parser.Error)
+		if !ok || !strings.HasPrefix(e.Reason, "undefined modifier") {
+			return nil, 0, err
+		}
+		return []string{e.Source.Val}, 1, nil
+	}
+
+	errs, ok := typecheck.Check(contract).(typecheck.Errors)
+	if !ok {
+		return []string{}, 0, nil
+	}
+
+	undefined := []string{}
+	for _, e := range errs {
+		for _, prefix := range []string{"undefined: ", "undefined function: ", "undefined interface ", "undefined error: "} {
+			name := strings.TrimPrefix(e.Reason, prefix)
+			if name != e.Reason && !strings.Contains(name, ".") {
+				undefined = append(undefined, name)
+			}
+		}
+	}
+	return undefined, len(errs), nil
+}
+
+// declarersOf returns the libraries declaring each symbol. The symbols
+// a library imports from another library are not its declarations.
+func declarersOf(r parser.Resolver, libraries []string) map[string][]string {
+	provided := map[string][]string{}
+	for _, lib := range libraries {
+		if names, err := namesOf(r, lib); err == nil {
+			provided[lib] = names
+		}
+	}
+
+	declarers := map[string][]string{}
+	for _, lib := range libraries {
+		names, ok := provided[lib]
+		if !ok {
+			continue
+		}
+
+		src, err := r.Resolve(lib)
+		if err != nil {
+			continue
+		}
+
+		imported := map[string]bool{}
+		for _, d := range directivesOf(src) {
+			other, err := namesOf(r, d.path)
+			if err != nil {
+				continue
+			}
+			for _, name := range other {
+				imported[name] = true
+			}
+		}
+
+		for _, name := range names {
+			if !imported[name] {
+				declarers[name] = append(declarers[name], lib)
+			}
+		}
+	}
+	return declarers
+}
+
+// namesOf returns the names of declarations which contract gets by
+// importing library, including the libraries it imports
+func namesOf(r parser.Resolver, library string) ([]string, error) {
+	contract, err := parser.ParseImports(context.Background(),
+		parser.NewTokenBuffer(parser.NewLexer(fmt.Sprintf("import %q\ncontract {\n}", library))), parser.Limits{}, r)
+	if err != nil {
+		return nil, err
+	}
+	return declarationsOf(contract), nil
+}
+
+func declarationsOf(contract *ast.Contract) []string {
+	names := []string{}
+	for _, i := range contract.Interfaces {
+		names = append(names, i.Name.Name)
+	}
+	for _, c := range contract.Constants {
+		names = append(names, c.Name.Name)
+	}
+	for _, e := range contract.Enums {
+		names = append(names, e.Name.Name)
+	}
+	for _, e := range contract.Errors {
+		names = append(names, e.Name.Name)
+	}
+	for _, m := range contract.Modifiers {
+		names = append(names, m.Name.Name)
+	}
+	for _, f := range contract.Functions {
+		names = append(names, f.Name.Name)
+	}
+	return names
+}
+
+// directivesOf returns import directives at the start of src
+func directivesOf(src string) []directive {
+	directives := []directive{}
+	toks := tokensOf(src)
+	for i := 0; i+1 < len(toks); i++ {
+		if toks[i].Type != parser.Import {
+			continue
+		}
+		if toks[i+1].Type == parser.String {
+			directives = append(directives, directive{
+				path: strings.Trim(toks[i+1].Val, `"`),
+				line: toks[i].Line,
+			})
+		}
+	}
+	return directives
+}
+
+// insertLine returns the line where new import is written, which is
+// after the last import, or before the first declaration after pragmas
+func insertLine(src string) int {
+	if ds := directivesOf(src); len(ds) != 0 {
+		return ds[len(ds)-1].line + 1
+	}
+
+	pragmas := map[int]bool{}
+	for _, tok := range tokensOf(src) {
+		if tok.Type == parser.Pragma {
+			pragmas[tok.Line] = true
+		}
+		if tok.Type != parser.Semicolon && !pragmas[tok.Line] {
+			return tok.Line
+		}
+	}
+	return len(strings.Split(src, "\n"))
+}
+
+func tokensOf(src string) []parser.Token {
+	l := parser.NewLexer(src)
+	toks := []parser.Token{}
+	for {
+		tok := l.NextToken()
+		if tok.Type == parser.Eof || tok == (parser.Token{}) {
+			return toks
+		}
+		toks = append(toks, tok)
+	}
+}
+
+func isImported(src string, path string) bool {
+	for _, d := range directivesOf(src) {
+		if d.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func addImport(src string, path string) string {
+	lines := strings.Split(src, "\n")
+	at := insertLine(src)
+	if at > len(lines) {
+		at = len(lines)
+	}
+
+	result := append([]string{}, lines[:at]...)
+	result = append(result, fmt.Sprintf("import %q", path))
+	return strings.Join(append(result, lines[at:]...), "\n")
+}
+
+func removeImport(src string, path string) string {
+	lines := strings.Split(src, "\n")
+	for _, d := range directivesOf(src) {
+		if d.path == path {
+			return strings.Join(append(lines[:d.line:d.line], lines[d.line+1:]...), "\n")
+		}
+	}
+	return src
+}
+
+// subsetOf reports whether every name of a is in b
+func subsetOf(a []string, b []string) bool {
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if x == y {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
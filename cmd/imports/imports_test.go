@@ -0,0 +1,139 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package imports_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DE-labtory/koa/cmd/imports"
+)
+
+// mapResolver resolves imported path to the source in map
+type mapResolver map[string]string
+
+func (m mapResolver) Resolve(path string) (string, error) {
+	src, ok := m[path]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return src, nil
+}
+
+func TestOrganize(t *testing.T) {
+	resolver := mapResolver{
+		"math.koa": `
+const int SCALE = 100
+
+func scale(a int) int {
+	return a * SCALE
+}`,
+		"guard.koa": `
+modifier positive {
+	require(1 > 0, "positive")
+	_
+}`,
+		"token.koa": `
+import "math.koa"
+
+interface Token {
+	func total() int
+}`,
+		"max.koa":  `func max(a int, b int) int { return a }`,
+		"max2.koa": `func max(a int, b int) int { return b }`,
+		"main.koa": `contract {
+}`,
+	}
+	libraries := []string{"guard.koa", "main.koa", "math.koa", "max.koa", "max2.koa", "token.koa"}
+
+	tests := []struct {
+		input    string
+		expected imports.Result
+	}{
+		{
+			input: `contract {
+	func foo() positive int {
+		return scale(2)
+	}
+}`,
+			expected: imports.Result{
+				Source: `import "guard.koa"
+import "math.koa"
+contract {
+	func foo() positive int {
+		return scale(2)
+	}
+}`,
+				Added:       []string{"guard.koa", "math.koa"},
+				Suggestions: map[string][]string{},
+			},
+		},
+		{
+			input: `pragma koa >=0.0.1
+
+import "token.koa"
+import "guard.koa"
+
+contract implements Token {
+	func total() int {
+		return SCALE
+	}
+}`,
+			expected: imports.Result{
+				Source: `pragma koa >=0.0.1
+
+import "token.koa"
+
+contract implements Token {
+	func total() int {
+		return SCALE
+	}
+}`,
+				Removed:     []string{"guard.koa"},
+				Suggestions: map[string][]string{},
+			},
+		},
+		{
+			input: `contract {
+	func foo() int {
+		int scale = 1
+		return max(scale, 2)
+	}
+}`,
+			expected: imports.Result{
+				Source: `contract {
+	func foo() int {
+		int scale = 1
+		return max(scale, 2)
+	}
+}`,
+				Suggestions: map[string][]string{"max": {"max.koa", "max2.koa"}},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		result, err := imports.Organize(test.input, resolver, libraries)
+		if err != nil {
+			t.Fatalf("test[%d] - Organize() returns unexpected error: %s", i, err)
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("test[%d] - Organize() wrong result.\nexpected=%#v\ngot=%#v", i, test.expected, result)
+		}
+	}
+}
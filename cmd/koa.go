@@ -22,12 +22,19 @@ import (
 	"os"
 	"time"
 
+	"github.com/DE-labtory/koa/cmd/asm"
+	"github.com/DE-labtory/koa/cmd/check"
 	"github.com/DE-labtory/koa/cmd/compile"
 
 	"github.com/DE-labtory/koa/cmd/execute"
+	"github.com/DE-labtory/koa/cmd/generate"
+	"github.com/DE-labtory/koa/cmd/graph"
+	"github.com/DE-labtory/koa/cmd/imports"
 	"github.com/DE-labtory/koa/cmd/lex"
 	"github.com/DE-labtory/koa/cmd/parse"
 	"github.com/DE-labtory/koa/cmd/repl"
+	"github.com/DE-labtory/koa/cmd/version"
+	parser "github.com/DE-labtory/koa/parse"
 	"github.com/fatih/color"
 	"github.com/urfave/cli"
 )
@@ -61,7 +68,7 @@ func main() {
 
 	app := cli.NewApp()
 	app.Name = "koa"
-	app.Version = "0.0.1"
+	app.Version = parser.Version
 	app.Compiled = time.Now()
 	app.Authors = []cli.Author{
 		{
@@ -85,6 +92,12 @@ func main() {
 	app.Commands = append(app.Commands, parse.Cmd())
 	app.Commands = append(app.Commands, compile.Cmd())
 	app.Commands = append(app.Commands, execute.Cmd())
+	app.Commands = append(app.Commands, graph.Cmd())
+	app.Commands = append(app.Commands, check.Cmd())
+	app.Commands = append(app.Commands, asm.Cmd())
+	app.Commands = append(app.Commands, imports.Cmd())
+	app.Commands = append(app.Commands, generate.Cmd())
+	app.Commands = append(app.Commands, version.Cmd())
 
 	app.Action = func(c *cli.Context) error {
 		repl.Run()
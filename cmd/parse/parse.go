@@ -17,8 +17,6 @@
 package parse
 
 import (
-	"io/ioutil"
-
 	"fmt"
 
 	"github.com/DE-labtory/koa/ast"
@@ -37,9 +35,16 @@ const (
 var parseCmd = cli.Command{
 	Name:    "parse",
 	Aliases: []string{"p"},
-	Usage:   "koa parse [filePath]",
+	Usage:   "koa parse [--format tree|json] [filePath]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format, f",
+			Value: "tree",
+			Usage: "output format of ast, tree or json",
+		},
+	},
 	Action: func(c *cli.Context) error {
-		return parse(c.Args().Get(0))
+		return parse(c.Args().Get(0), c.String("format"))
 	},
 }
 
@@ -47,21 +52,26 @@ func Cmd() cli.Command {
 	return parseCmd
 }
 
-func parse(path string) error {
-	file, err := ioutil.ReadFile(path)
+func parse(path string, format string) error {
+	contract, err := parser.ParseFile(path)
 	if err != nil {
 		return err
 	}
 
-	l := parser.NewLexer(string(file))
-	buf := parser.NewTokenBuffer(l)
-	contract, err := parser.Parse(buf)
-	if err != nil {
-		return err
+	switch format {
+	case "tree":
+		fmt.Println(PrintContract(contract))
+		return nil
+	case "json":
+		b, err := ast.MarshalContract(contract)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	default:
+		return fmt.Errorf("unknown parse format [%s]", format)
 	}
-
-	fmt.Println(PrintContract(contract))
-	return nil
 }
 
 func PrintContract(contract *ast.Contract) string {
@@ -24,10 +24,12 @@ import (
 
 	"bufio"
 	"os"
+	"strings"
 
 	compile_cmd "github.com/DE-labtory/koa/cmd/compile"
 	lex_cmd "github.com/DE-labtory/koa/cmd/lex"
 	parse_cmd "github.com/DE-labtory/koa/cmd/parse"
+	"github.com/DE-labtory/koa/complete"
 	"github.com/DE-labtory/koa/parse"
 	"github.com/fatih/color"
 )
@@ -53,7 +55,7 @@ func PrintLogo() {
 	bold := color.New(color.Bold)
 	bold.Printf("github: https://github.com/DE-labtory/koa \n\n")
 	fmt.Printf("The project is inspired by the simplicity and the ivy-bitcoin. The koa project is to create \na high-level language that has more expressions than the bitcoin script and is simpler and easy to analyze than soldity(ethereum).\n\n")
-	bold.Print("Use exit() or Ctrl-c to exit \n")
+	bold.Print("Use exit() or Ctrl-c to exit, end line with tab to complete it \n")
 }
 
 func Run() {
@@ -79,6 +81,15 @@ func run(in io.Reader, out io.Writer) {
 			return
 		}
 
+		// line ending with tab asks for completion instead of compiling
+		if strings.HasSuffix(line, "\t") {
+			if sig, ok := complete.SignatureHelp(line, len(line)-1); ok {
+				printSignature(sig)
+			}
+			printCandidates(complete.Complete(line, len(line)-1))
+			continue
+		}
+
 		l := parse.NewLexer(line)
 		l2 := parse.NewLexer(line)
 
@@ -117,3 +128,20 @@ func run(in io.Reader, out io.Writer) {
 		fmt.Println()
 	}
 }
+
+func printCandidates(candidates []complete.Candidate) {
+	for _, c := range candidates {
+		fmt.Printf("%s\t%s\t%s\n", c.Label, c.Kind, c.Detail)
+	}
+}
+
+// printSignature prints signature of function being called,
+// marking the parameter whose argument is being typed
+func printSignature(sig complete.Signature) {
+	fmt.Print(sig.Label)
+	if sig.Active < len(sig.Parameters) {
+		p := sig.Parameters[sig.Active]
+		fmt.Printf("\t%s", strings.TrimSpace(p.Name+" "+p.Type))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	parser "github.com/DE-labtory/koa/parse"
+	"github.com/urfave/cli"
+)
+
+var versionCmd = cli.Command{
+	Name:  "version",
+	Usage: "koa version [--format text|json]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format, f",
+			Value: "text",
+			Usage: "output format of build info, text or json",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		return version(c.String("format"))
+	},
+}
+
+func Cmd() cli.Command {
+	return versionCmd
+}
+
+// Info describes the build of koa binary, so that the binary released
+// for each platform tells what it is built from
+type Info struct {
+	// Version is the version of koa language which parser accepts
+	Version string
+
+	GoVersion string
+	OS        string
+	Arch      string
+
+	// Revision and Time are the commit which binary is built from,
+	// Modified is set when the working tree had local changes. They
+	// are empty when binary is built without version control info.
+	Revision string `json:",omitempty"`
+	Time     string `json:",omitempty"`
+	Modified bool   `json:",omitempty"`
+}
+
+// Read returns the build info of running binary
+func Read() Info {
+	info := Info{
+		Version:   parser.Version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	for _, s := range build.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.Time = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+func (i Info) String() string {
+	s := fmt.Sprintf("koa %s\ngo %s\nplatform %s/%s", i.Version, i.GoVersion, i.OS, i.Arch)
+	if i.Revision == "" {
+		return s
+	}
+
+	s += fmt.Sprintf("\nrevision %s", i.Revision)
+	if i.Modified {
+		s += " (modified)"
+	}
+	if i.Time != "" {
+		s += fmt.Sprintf("\nbuilt from commit at %s", i.Time)
+	}
+	return s
+}
+
+func version(format string) error {
+	info := Read()
+
+	switch format {
+	case "text":
+		fmt.Println(info)
+		return nil
+	case "json":
+		b, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	default:
+		return fmt.Errorf("unknown version format [%s]", format)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/DE-labtory/koa/cmd/version"
+	"github.com/DE-labtory/koa/parse"
+)
+
+func TestRead(t *testing.T) {
+	info := version.Read()
+
+	if info.Version != parse.Version {
+		t.Errorf("wrong version. expected=%s, got=%s", parse.Version, info.Version)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("wrong go version. expected=%s, got=%s", runtime.Version(), info.GoVersion)
+	}
+	if info.OS != runtime.GOOS || info.Arch != runtime.GOARCH {
+		t.Errorf("wrong platform. expected=%s/%s, got=%s/%s", runtime.GOOS, runtime.GOARCH, info.OS, info.Arch)
+	}
+}
+
+func TestInfo_String(t *testing.T) {
+	tests := []struct {
+		info     version.Info
+		expected string
+	}{
+		{
+			info:     version.Info{Version: "0.0.1", GoVersion: "go1.12", OS: "windows", Arch: "amd64"},
+			expected: "koa 0.0.1\ngo go1.12\nplatform windows/amd64",
+		},
+		{
+			info: version.Info{Version: "0.0.1", GoVersion: "go1.12", OS: "darwin", Arch: "arm64",
+				Revision: "cdf949b", Time: "2019-05-01T00:00:00Z", Modified: true},
+			expected: "koa 0.0.1\ngo go1.12\nplatform darwin/arm64\nrevision cdf949b (modified)\nbuilt from commit at 2019-05-01T00:00:00Z",
+		},
+	}
+
+	for i, tt := range tests {
+		if s := tt.info.String(); s != tt.expected {
+			t.Errorf("test[%d] - String() wrong result.\nexpected=%s\ngot=%s", i, tt.expected, s)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package complete suggests what can be written at the cursor in koa
+// source, describes the identifier under it, and outlines the
+// declarations of source. It works on tokens instead of AST, so that
+// source which is being typed and doesn't parse yet can be completed.
+// It is shared by the REPL and editor integrations.
+package complete
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/typecheck"
+)
+
+// Kind is the kind of candidate
+type Kind int
+
+const (
+	Keyword Kind = iota
+	Type
+	Function
+	Constant
+	Variable
+	Field
+	Contract
+	Interface
+	Modifier
+	Error
+	Enum
+)
+
+var kindNames = map[Kind]string{
+	Keyword:   "keyword",
+	Type:      "type",
+	Function:  "function",
+	Constant:  "constant",
+	Variable:  "variable",
+	Field:     "field",
+	Contract:  "contract",
+	Interface: "interface",
+	Modifier:  "modifier",
+	Error:     "error",
+	Enum:      "enum",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Candidate is an identifier or keyword which can be written
+// at the cursor
+type Candidate struct {
+	Label string
+	Kind  Kind
+
+	// Detail is the type of variable, constant and field,
+	// or the signature of function. e.g. add(int, int) int
+	Detail string
+}
+
+var (
+	declarationKeywords = []string{"import", "pragma", "contract", "interface", "func", "const", "enum", "modifier"}
+	memberKeywords      = []string{"func", "const", "enum", "modifier"}
+	statementKeywords   = []string{"if", "for", "do", "return", "const"}
+	blockKeywords       = []string{"else", "while"}
+	literalKeywords     = []string{"true", "false"}
+	typeNames           = []string{"int", "int8", "int16", "int32", "int64", "uint", "bool", "string", "bytes", "address"}
+)
+
+// Complete returns candidates which can be written at offset, the byte
+// offset of the cursor in src. Only candidates which start with the word
+// being typed at offset are returned, sorted by their labels.
+func Complete(src string, offset int) []Candidate {
+	if offset < 0 || offset > len(src) {
+		return nil
+	}
+
+	before := src[:offset]
+	word := partialWord(before)
+	toks := tokenize(before[:len(before)-len(word)])
+
+	var candidates []Candidate
+	if n := len(toks); tokenAt(toks, n-1).Type == parse.Dot {
+		candidates = fields(tokenAt(toks, n-2).Val)
+	} else {
+		candidates = inScope(tokenize(src), toks)
+	}
+
+	return filter(candidates, word)
+}
+
+// partialWord returns the identifier which ends at the end of src
+func partialWord(src string) string {
+	i := len(src)
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(src[:i])
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		i -= size
+	}
+	return src[i:]
+}
+
+// tokenize returns tokens of src without the semicolon which lexer
+// inserts at the end of src, so that the last token is the one before
+// the cursor
+func tokenize(src string) []parse.Token {
+	l := parse.NewLexer(src)
+	toks := make([]parse.Token, 0)
+	for {
+		tok := l.NextToken()
+		if tok.Type == parse.Eof || tok == (parse.Token{}) {
+			break
+		}
+		toks = append(toks, tok)
+	}
+
+	if n := len(toks); n > 0 && toks[n-1].Type == parse.Semicolon && toks[n-1].Val == "" {
+		toks = toks[:n-1]
+	}
+	return toks
+}
+
+func tokenAt(toks []parse.Token, i int) parse.Token {
+	if i < 0 || i >= len(toks) {
+		return parse.Token{}
+	}
+	return toks[i]
+}
+
+func isType(tok parse.Token) bool {
+	return tok.Type >= parse.IntType && tok.Type <= parse.AddressType
+}
+
+// fields returns fields of reserved identifier name
+func fields(name string) []Candidate {
+	candidates := make([]Candidate, 0)
+	for field, t := range typecheck.Fields(name) {
+		candidates = append(candidates, Candidate{Label: field, Kind: Field, Detail: t.String()})
+	}
+	return candidates
+}
+
+// inScope returns candidates after the tokens before the cursor,
+// src is the tokens of whole source which has declarations
+// after the cursor as well
+func inScope(src, before []parse.Token) []Candidate {
+	decls := walk(src)
+	w := walk(before)
+	last := tokenAt(before, len(before)-1)
+
+	switch {
+	case w.params > 0:
+		// type of parameter follows its name
+		if last.Type == parse.Ident {
+			return keywords(Type, typeNames)
+		}
+		return nil
+
+	case w.function > 0:
+		return w.inFunction(last, decls)
+
+	case last.Type == parse.Const:
+		return keywords(Type, typeNames)
+
+	case !startsStatement(last):
+		return nil
+
+	case w.iface > 0:
+		return keywords(Keyword, []string{"func"})
+
+	case w.contract > 0:
+		return keywords(Keyword, memberKeywords)
+
+	default:
+		return keywords(Keyword, declarationKeywords)
+	}
+}
+
+// inFunction returns candidates in the body of function or modifier
+func (w *walker) inFunction(last parse.Token, decls *walker) []Candidate {
+	switch {
+	case startsStatement(last):
+		candidates := keywords(Keyword, statementKeywords)
+		if last.Type == parse.Rbrace {
+			candidates = append(candidates, keywords(Keyword, blockKeywords)...)
+		}
+		candidates = append(candidates, keywords(Type, typeNames)...)
+		candidates = append(candidates, keywords(Keyword, literalKeywords)...)
+		return append(candidates, w.identifiers(decls)...)
+
+	case last.Type == parse.Else:
+		return keywords(Keyword, []string{"if"})
+
+	case endsOperand(last):
+		// operator or name of new variable follows
+		return nil
+
+	default:
+		candidates := keywords(Keyword, literalKeywords)
+		return append(candidates, w.identifiers(decls)...)
+	}
+}
+
+// identifiers returns locals in scope, declarations of contract,
+// builtin functions and reserved identifiers. Inner locals shadow
+// the outer ones.
+func (w *walker) identifiers(decls *walker) []Candidate {
+	candidates := make([]Candidate, 0)
+	for i := len(w.locals) - 1; i >= 0; i-- {
+		candidates = append(candidates, w.locals[i].Candidate)
+	}
+	candidates = append(candidates, decls.functions...)
+	candidates = append(candidates, decls.constants...)
+
+	for _, fn := range typecheck.Builtins() {
+		candidates = append(candidates, Candidate{Label: fn.Name, Kind: Function, Detail: fn.Signature()})
+	}
+	for _, name := range typecheck.Reserved() {
+		candidates = append(candidates, Candidate{Label: name, Kind: Variable})
+	}
+
+	return candidates
+}
+
+// startsStatement reports whether statement or declaration
+// starts after tok
+func startsStatement(tok parse.Token) bool {
+	switch tok.Type {
+	case parse.Illegal, parse.Semicolon, parse.Lbrace, parse.Rbrace:
+		return true
+	}
+	return false
+}
+
+// endsOperand reports whether tok can be the end of operand
+// or the type of declaration
+func endsOperand(tok parse.Token) bool {
+	switch tok.Type {
+	case parse.Ident, parse.Int, parse.String, parse.Bytes, parse.True, parse.False,
+		parse.Rparen, parse.Rbracket, parse.Function, parse.Modifier, parse.Interface, parse.Contract:
+		return true
+	}
+	return isType(tok)
+}
+
+func keywords(kind Kind, labels []string) []Candidate {
+	candidates := make([]Candidate, 0, len(labels))
+	for _, label := range labels {
+		candidates = append(candidates, Candidate{Label: label, Kind: kind})
+	}
+	return candidates
+}
+
+// filter returns candidates starting with word sorted by their labels,
+// only the first of candidates with the same label is kept
+func filter(candidates []Candidate, word string) []Candidate {
+	seen := make(map[string]bool)
+	filtered := make([]Candidate, 0)
+	for _, c := range candidates {
+		if !strings.HasPrefix(c.Label, word) || seen[c.Label] {
+			continue
+		}
+		seen[c.Label] = true
+		filtered = append(filtered, c)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Label < filtered[j].Label
+	})
+	return filtered
+}
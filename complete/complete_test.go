@@ -0,0 +1,222 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package complete_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DE-labtory/koa/complete"
+)
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		// input has cursor at $
+		input    string
+		expected []complete.Candidate
+	}{
+		{
+			input: `co$`,
+			expected: []complete.Candidate{
+				{Label: "const", Kind: complete.Keyword},
+				{Label: "contract", Kind: complete.Keyword},
+			},
+		},
+		{
+			input: `
+contract {
+	f$
+}`,
+			expected: []complete.Candidate{
+				{Label: "func", Kind: complete.Keyword},
+			},
+		},
+		{
+			input: `
+contract {
+	const i$
+}`,
+			expected: []complete.Candidate{
+				{Label: "int", Kind: complete.Type},
+				{Label: "int16", Kind: complete.Type},
+				{Label: "int32", Kind: complete.Type},
+				{Label: "int64", Kind: complete.Type},
+				{Label: "int8", Kind: complete.Type},
+			},
+		},
+		{
+			input: `
+contract {
+	func foo(a i$) {
+	}
+}`,
+			expected: []complete.Candidate{
+				{Label: "int", Kind: complete.Type},
+				{Label: "int16", Kind: complete.Type},
+				{Label: "int32", Kind: complete.Type},
+				{Label: "int64", Kind: complete.Type},
+				{Label: "int8", Kind: complete.Type},
+			},
+		},
+		{
+			input: `
+contract {
+	func foo(a int, b string) int {
+		int c = 1
+		return b$
+	}
+}`,
+			expected: []complete.Candidate{
+				{Label: "b", Kind: complete.Variable, Detail: "string"},
+				{Label: "block", Kind: complete.Variable},
+			},
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		i$
+	}
+}`,
+			expected: []complete.Candidate{
+				{Label: "if", Kind: complete.Keyword},
+				{Label: "int", Kind: complete.Type},
+				{Label: "int16", Kind: complete.Type},
+				{Label: "int32", Kind: complete.Type},
+				{Label: "int64", Kind: complete.Type},
+				{Label: "int8", Kind: complete.Type},
+				{Label: "itoa", Kind: complete.Function, Detail: "itoa(int) string"},
+			},
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return a$
+	}
+	func add(a int, b int = 1) int {
+		return a + b
+	}
+	func addr() (int, string) {
+		return 1, "a"
+	}
+}`,
+			expected: []complete.Candidate{
+				{Label: "add", Kind: complete.Function, Detail: "add(int, int) int"},
+				{Label: "addr", Kind: complete.Function, Detail: "addr() (int, string)"},
+				{Label: "assert", Kind: complete.Function, Detail: "assert(bool) void"},
+				{Label: "atoi", Kind: complete.Function, Detail: "atoi(string) int"},
+			},
+		},
+		{
+			input: `
+contract {
+	const int MAX = 10
+	func foo() int {
+		const int MIN = 1
+		return M$
+	}
+}`,
+			expected: []complete.Candidate{
+				{Label: "MAX", Kind: complete.Constant, Detail: "int"},
+				{Label: "MIN", Kind: complete.Constant, Detail: "int"},
+			},
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		if (true) {
+			int inner = 1
+		}
+		int outer = 2
+		return o$ + in
+	}
+}`,
+			expected: []complete.Candidate{
+				{Label: "outer", Kind: complete.Variable, Detail: "int"},
+			},
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		if (true) {
+			int inner = 1
+		}
+		return in$
+	}
+}`,
+			expected: []complete.Candidate{},
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		if (true) {
+		} e$
+	}
+}`,
+			expected: []complete.Candidate{
+				{Label: "else", Kind: complete.Keyword},
+			},
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		int s$
+	}
+}`,
+			expected: []complete.Candidate{},
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return block.t$
+	}
+}`,
+			expected: []complete.Candidate{
+				{Label: "timestamp", Kind: complete.Field, Detail: "int"},
+			},
+		},
+		{
+			input: `
+interface Token {
+	func balance() int
+}
+contract {
+	func foo() int {
+		return ba$
+	}
+}`,
+			expected: []complete.Candidate{},
+		},
+	}
+
+	for i, test := range tests {
+		offset := strings.Index(test.input, "$")
+		src := strings.Replace(test.input, "$", "", 1)
+
+		candidates := complete.Complete(src, offset)
+		if !reflect.DeepEqual(candidates, test.expected) {
+			t.Errorf("test[%d] - Complete() wrong result.\nexpected=%v\ngot=%v", i, test.expected, candidates)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package complete
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/DE-labtory/koa/natspec"
+	"github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/typecheck"
+)
+
+// Hover describes the identifier under the cursor. Detail is the type
+// of variable, constant and field, or the signature of function and
+// error. Doc is the documentation of function from its comment.
+type Hover struct {
+	Name   string
+	Kind   Kind
+	Detail string
+	Doc    natspec.Doc
+}
+
+// HoverAt returns the description of identifier at offset, the byte
+// offset of the cursor in src. It returns false when there is no
+// identifier at offset or it is not declared.
+func HoverAt(src string, offset int) (Hover, bool) {
+	if offset < 0 || offset > len(src) {
+		return Hover{}, false
+	}
+
+	start := offset - len(partialWord(src[:offset]))
+	end := offset + len(wordPrefix(src[offset:]))
+	name := src[start:end]
+	if name == "" {
+		return Hover{}, false
+	}
+
+	if left := partialWord(strings.TrimSuffix(src[:start], ".")); strings.HasSuffix(src[:start], ".") && left != "" {
+		return selected(src, left, name)
+	}
+
+	// parameter is declared by the type after it
+	toks := tokenize(src)
+	n := len(tokenize(src[:end]))
+	if isType(tokenAt(toks, n)) {
+		n++
+	}
+
+	w := walk(toks[:n])
+	for i := len(w.locals) - 1; i >= 0; i-- {
+		if l := w.locals[i]; l.Label == name {
+			return Hover{Name: name, Kind: l.Kind, Detail: l.Detail}, true
+		}
+	}
+
+	for _, sym := range Outline(src) {
+		for _, s := range append([]Symbol{sym}, sym.Children...) {
+			if s.Name != name || s.Kind == Contract {
+				continue
+			}
+			h := Hover{Name: name, Kind: s.Kind, Detail: s.Detail}
+			if s.Kind == Function {
+				h.Doc = natspec.Extract(src)[name]
+			}
+			return h, true
+		}
+	}
+
+	for _, fn := range typecheck.Builtins() {
+		if fn.Name == name {
+			return Hover{Name: name, Kind: Function, Detail: fn.Signature()}, true
+		}
+	}
+	for _, r := range typecheck.Reserved() {
+		if r == name {
+			return Hover{Name: name, Kind: Variable}, true
+		}
+	}
+
+	return Hover{}, false
+}
+
+// selected describes field of reserved identifier or member of enum
+func selected(src string, left string, name string) (Hover, bool) {
+	if t, ok := typecheck.Fields(left)[name]; ok {
+		return Hover{Name: left + "." + name, Kind: Field, Detail: t.String()}, true
+	}
+
+	toks := tokenize(src)
+	for i, tok := range toks {
+		if tok.Type != parse.Enum || tokenAt(toks, i+1).Val != left {
+			continue
+		}
+		for j := i + 2; j < len(toks) && toks[j].Type != parse.Rbrace; j++ {
+			if toks[j].Val == name {
+				return Hover{Name: left + "." + name, Kind: Constant, Detail: "int"}, true
+			}
+		}
+	}
+	return Hover{}, false
+}
+
+// wordPrefix returns the identifier which starts at the start of src
+func wordPrefix(src string) string {
+	for i, r := range src {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return src[:i]
+		}
+	}
+	return src
+}
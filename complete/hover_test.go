@@ -0,0 +1,119 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package complete_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DE-labtory/koa/complete"
+	"github.com/DE-labtory/koa/natspec"
+)
+
+func TestHoverAt(t *testing.T) {
+	src := `
+contract {
+	const int FEE = 10
+	enum Status { Pending, Active }
+
+	// @notice Adds fee to amount
+	// @param amount amount without fee
+	func withFee(amount int) int {
+		int total = amount + FEE
+		return total + Status.Active + block.number + len(0x"00")
+	}
+}`
+
+	tests := []struct {
+		// word is hovered at its second character,
+		// after skip occurrences of it
+		word     string
+		skip     int
+		expected complete.Hover
+		ok       bool
+	}{
+		{
+			word: "withFee",
+			expected: complete.Hover{
+				Name:   "withFee",
+				Kind:   complete.Function,
+				Detail: "withFee(int) int",
+				Doc: natspec.Doc{
+					Notice: "Adds fee to amount",
+					Params: map[string]string{"amount": "amount without fee"},
+				},
+			},
+			ok: true,
+		},
+		{
+			word:     "total",
+			skip:     1,
+			expected: complete.Hover{Name: "total", Kind: complete.Variable, Detail: "int"},
+			ok:       true,
+		},
+		{
+			word:     "amount",
+			skip:     3,
+			expected: complete.Hover{Name: "amount", Kind: complete.Variable, Detail: "int"},
+			ok:       true,
+		},
+		{
+			word:     "amount",
+			skip:     4,
+			expected: complete.Hover{Name: "amount", Kind: complete.Variable, Detail: "int"},
+			ok:       true,
+		},
+		{
+			word:     "FEE",
+			skip:     1,
+			expected: complete.Hover{Name: "FEE", Kind: complete.Constant, Detail: "int"},
+			ok:       true,
+		},
+		{
+			word:     "Active",
+			skip:     1,
+			expected: complete.Hover{Name: "Status.Active", Kind: complete.Constant, Detail: "int"},
+			ok:       true,
+		},
+		{
+			word:     "number",
+			expected: complete.Hover{Name: "block.number", Kind: complete.Field, Detail: "int"},
+			ok:       true,
+		},
+		{
+			word:     "len",
+			expected: complete.Hover{Name: "len", Kind: complete.Function, Detail: "len(bytes) int"},
+			ok:       true,
+		},
+		{
+			word: "contract",
+		},
+	}
+
+	for i, test := range tests {
+		offset := 0
+		for n := 0; n <= test.skip; n++ {
+			offset += strings.Index(src[offset:], test.word) + 1
+		}
+
+		hover, ok := complete.HoverAt(src, offset)
+		if ok != test.ok || !reflect.DeepEqual(hover, test.expected) {
+			t.Errorf("test[%d] - HoverAt() wrong result. expected=%+v %v, got=%+v %v", i, test.expected, test.ok, hover, ok)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package complete
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/DE-labtory/koa/parse"
+)
+
+// Symbol is a declaration of source. Line and Column are the position
+// of its name as parse.Token has, which is the end of token, or of
+// contract keyword for contract.
+// Children are the declarations in the body of contract and interface.
+type Symbol struct {
+	Name     string
+	Kind     Kind
+	Detail   string
+	Line     int
+	Column   parse.Pos
+	Children []Symbol
+}
+
+// Outline returns the declarations of src in the order they appear.
+// Source which is a library has its declarations at the top level.
+func Outline(src string) []Symbol {
+	toks := tokenize(src)
+
+	symbols := make([]Symbol, 0)
+	var container *Symbol
+	depth := 0
+	for i, tok := range toks {
+		next := tokenAt(toks, i+1)
+
+		var sym *Symbol
+		switch tok.Type {
+		case parse.Lbrace:
+			depth++
+		case parse.Rbrace:
+			depth--
+			if depth == 0 {
+				container = nil
+			}
+		case parse.Contract:
+			sym = &Symbol{Name: "contract", Kind: Contract, Line: tok.Line, Column: tok.Column}
+		case parse.Interface:
+			sym = declared(next, Interface, "")
+		case parse.Function:
+			if next.Type == parse.Ident {
+				sym = declared(next, Function, signature(toks[i+1:]))
+			}
+		case parse.Modifier:
+			sym = declared(next, Modifier, "")
+		case parse.Enum:
+			sym = declared(next, Enum, "")
+		case parse.ErrorDecl:
+			if next.Type == parse.Ident {
+				sym = declared(next, Error, errorSignature(toks[i+1:]))
+			}
+		case parse.Const:
+			if after := tokenAt(toks, i+2); isType(next) && after.Type == parse.Ident {
+				sym = declared(after, Constant, next.Val)
+			}
+		}
+
+		switch {
+		case sym == nil:
+		case depth == 0:
+			symbols = append(symbols, *sym)
+			if sym.Kind == Contract || sym.Kind == Interface {
+				container = &symbols[len(symbols)-1]
+			}
+		case depth == 1 && container != nil:
+			container.Children = append(container.Children, *sym)
+		}
+	}
+
+	return symbols
+}
+
+func declared(name parse.Token, kind Kind, detail string) *Symbol {
+	return &Symbol{Name: name.Val, Kind: kind, Detail: detail, Line: name.Line, Column: name.Column}
+}
+
+// errorSignature formats the parameter types of error starting
+// from its name. e.g. Insufficient(int, int)
+func errorSignature(toks []parse.Token) string {
+	sig := signature(toks)
+	return sig[:strings.LastIndex(sig, ")")+1]
+}
+
+// Match is a symbol found by Search, in the file of Path. Container is
+// the name of contract or interface which has the symbol, empty for
+// the symbol at the top level.
+type Match struct {
+	Path      string
+	Container string
+	Symbol
+}
+
+// Search returns the symbols of files, mapping path to source, whose
+// names match query fuzzily. Name matches when it has the characters
+// of query in order, ignoring case. Names starting with query come
+// first, then shorter names.
+func Search(files map[string]string, query string) []Match {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	matches := make([]Match, 0)
+	for _, path := range paths {
+		for _, sym := range Outline(files[path]) {
+			if fuzzy(sym.Name, query) {
+				matches = append(matches, Match{Path: path, Symbol: withoutChildren(sym)})
+			}
+			for _, child := range sym.Children {
+				if fuzzy(child.Name, query) {
+					matches = append(matches, Match{Path: path, Container: sym.Name, Symbol: child})
+				}
+			}
+		}
+	}
+
+	query = strings.ToLower(query)
+	sort.SliceStable(matches, func(i, j int) bool {
+		pi := strings.HasPrefix(strings.ToLower(matches[i].Name), query)
+		pj := strings.HasPrefix(strings.ToLower(matches[j].Name), query)
+		if pi != pj {
+			return pi
+		}
+		return len(matches[i].Name) < len(matches[j].Name)
+	})
+	return matches
+}
+
+func withoutChildren(sym Symbol) Symbol {
+	sym.Children = nil
+	return sym
+}
+
+// fuzzy reports whether name has the characters of query in order
+func fuzzy(name string, query string) bool {
+	rest := []rune(query)
+	for _, r := range name {
+		if len(rest) == 0 {
+			break
+		}
+		if unicode.ToLower(r) == unicode.ToLower(rest[0]) {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
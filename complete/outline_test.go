@@ -0,0 +1,112 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package complete_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DE-labtory/koa/complete"
+)
+
+func TestOutline(t *testing.T) {
+	input := `
+interface Token {
+	func total() int
+}
+
+contract implements Token {
+	const int FEE = 10
+	enum Status { Pending, Active }
+	error Insufficient(needed int, got int)
+	modifier positive {
+		require(FEE > 0, "fee")
+		_
+	}
+	func total() int {
+		int sum = 0
+		return sum
+	}
+}`
+
+	expected := []complete.Symbol{
+		{
+			Name: "Token", Kind: complete.Interface, Line: 1, Column: 15,
+			Children: []complete.Symbol{
+				{Name: "total", Kind: complete.Function, Detail: "total() int", Line: 2, Column: 11},
+			},
+		},
+		{
+			Name: "contract", Kind: complete.Contract, Line: 5, Column: 8,
+			Children: []complete.Symbol{
+				{Name: "FEE", Kind: complete.Constant, Detail: "int", Line: 6, Column: 14},
+				{Name: "Status", Kind: complete.Enum, Line: 7, Column: 12},
+				{Name: "Insufficient", Kind: complete.Error, Detail: "Insufficient(int, int)", Line: 8, Column: 19},
+				{Name: "positive", Kind: complete.Modifier, Line: 9, Column: 18},
+				{Name: "total", Kind: complete.Function, Detail: "total() int", Line: 13, Column: 11},
+			},
+		},
+	}
+
+	if outline := complete.Outline(input); !reflect.DeepEqual(outline, expected) {
+		t.Errorf("Outline() wrong result.\nexpected=%+v\ngot=%+v", expected, outline)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	files := map[string]string{
+		"math.koa": `
+const int SCALE = 100
+
+func scale(a int) int {
+	return a * SCALE
+}`,
+		"token.koa": `
+contract {
+	func transfer(to address, amount int) bool {
+		return true
+	}
+	func totalSupply() int {
+		return 0
+	}
+}`,
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "sc", expected: []string{"math.koa SCALE", "math.koa scale"}},
+		{query: "ts", expected: []string{"token.koa contract.transfer", "token.koa contract.totalSupply"}},
+		{query: "tr", expected: []string{"token.koa contract.transfer", "token.koa contract"}},
+		{query: "xyz", expected: []string{}},
+	}
+
+	for i, test := range tests {
+		got := []string{}
+		for _, m := range complete.Search(files, test.query) {
+			name := m.Name
+			if m.Container != "" {
+				name = m.Container + "." + name
+			}
+			got = append(got, m.Path+" "+name)
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("test[%d] - Search() wrong result. expected=%v, got=%v", i, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package complete
+
+import (
+	"github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/typecheck"
+)
+
+// Signature is the signature of function whose arguments are being
+// typed at the cursor
+type Signature struct {
+	Name string
+
+	// Label is the header of function with parameter names,
+	// e.g. add(a int, b int) int
+	Label      string
+	Parameters []Parameter
+
+	// Active is the index of parameter whose argument is at the cursor
+	Active int
+}
+
+// Parameter is the parameter of function, Name is empty
+// for the parameter of builtin function
+type Parameter struct {
+	Name string
+	Type string
+}
+
+// SignatureHelp returns the signature of function called at offset,
+// the byte offset of the cursor in src, with the parameter whose
+// argument is at the cursor. It returns false when the cursor is not in
+// the arguments of call, or the function is not declared.
+func SignatureHelp(src string, offset int) (Signature, bool) {
+	if offset < 0 || offset > len(src) {
+		return Signature{}, false
+	}
+
+	name, active, ok := callAt(tokenize(src[:offset]))
+	if !ok {
+		return Signature{}, false
+	}
+
+	for _, sig := range walk(tokenize(src)).signatures {
+		if sig.Name == name {
+			sig.Active = active
+			return sig, true
+		}
+	}
+
+	for _, fn := range typecheck.Builtins() {
+		if fn.Name != name {
+			continue
+		}
+
+		params := make([]Parameter, 0, len(fn.Parameters))
+		for _, p := range fn.Parameters {
+			params = append(params, Parameter{Type: p.String()})
+		}
+		return Signature{Name: fn.Name, Label: fn.Signature(), Parameters: params, Active: active}, true
+	}
+
+	return Signature{}, false
+}
+
+// callAt returns the name of function whose arguments toks end in, and
+// the index of argument of the last token
+func callAt(toks []parse.Token) (string, int, bool) {
+	depth, active := 0, 0
+	for i := len(toks) - 1; i >= 0; i-- {
+		switch toks[i].Type {
+		case parse.Rparen:
+			depth++
+		case parse.Lparen:
+			if depth > 0 {
+				depth--
+				continue
+			}
+			name := tokenAt(toks, i-1)
+			if name.Type != parse.Ident || tokenAt(toks, i-2).Type == parse.Function {
+				return "", 0, false
+			}
+			return name.Val, active, true
+		case parse.Comma:
+			if depth == 0 {
+				active++
+			}
+		case parse.Lbrace, parse.Rbrace, parse.Semicolon:
+			return "", 0, false
+		}
+	}
+	return "", 0, false
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package complete_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DE-labtory/koa/complete"
+)
+
+func TestSignatureHelp(t *testing.T) {
+	add := complete.Signature{
+		Name:  "add",
+		Label: "add(a int, b int) int",
+		Parameters: []complete.Parameter{
+			{Name: "a", Type: "int"},
+			{Name: "b", Type: "int"},
+		},
+	}
+
+	tests := []struct {
+		// input has cursor at $
+		input    string
+		expected complete.Signature
+		ok       bool
+	}{
+		{
+			input: `
+contract {
+	func foo() int {
+		return add($
+	}
+	func add(a int, b int) int {
+		return a + b
+	}
+}`,
+			expected: add,
+			ok:       true,
+		},
+		{
+			input: `
+contract {
+	func add(a int, b int) int {
+		return a + b
+	}
+	func foo() int {
+		return add(add(1, 2), $
+	}
+}`,
+			expected: complete.Signature{Name: add.Name, Label: add.Label, Parameters: add.Parameters, Active: 1},
+			ok:       true,
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return atoi($
+	}
+}`,
+			expected: complete.Signature{
+				Name:       "atoi",
+				Label:      "atoi(string) int",
+				Parameters: []complete.Parameter{{Type: "string"}},
+			},
+			ok: true,
+		},
+		{
+			input: `
+contract {
+	func foo(a int$) int {
+		return a
+	}
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return bar($
+	}
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return add(1, 2) $
+	}
+}`,
+		},
+	}
+
+	for i, test := range tests {
+		offset := strings.Index(test.input, "$")
+		src := strings.Replace(test.input, "$", "", 1)
+
+		sig, ok := complete.SignatureHelp(src, offset)
+		if ok != test.ok || !reflect.DeepEqual(sig, test.expected) {
+			t.Errorf("test[%d] - SignatureHelp() wrong result. expected=%v %v, got=%v %v", i, test.expected, test.ok, sig, ok)
+		}
+	}
+}
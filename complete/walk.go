@@ -0,0 +1,218 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package complete
+
+import (
+	"strings"
+
+	"github.com/DE-labtory/koa/parse"
+)
+
+// walker follows the tokens of source, keeping track of the block
+// which the last token is in and the declarations seen so far
+type walker struct {
+	depth  int
+	parens int
+
+	// contract, function and iface are the depth of the body of
+	// contract, function or modifier and interface which walker
+	// is in, zero when it is not in one
+	contract int
+	function int
+	iface    int
+
+	// params is the depth of parens of parameter list which walker
+	// is in, zero when it is not in one
+	params int
+
+	// pending is the declaration whose body starts at the next brace
+	pending parse.TokenType
+
+	functions  []Candidate
+	signatures []Signature
+	constants  []Candidate
+	locals     []local
+}
+
+// local is variable or constant declared in function,
+// depth is the depth of the block it is declared in
+type local struct {
+	Candidate
+	depth int
+}
+
+func walk(toks []parse.Token) *walker {
+	w := &walker{}
+	for i, tok := range toks {
+		w.step(toks, i, tok)
+	}
+	return w
+}
+
+func (w *walker) step(toks []parse.Token, i int, tok parse.Token) {
+	prev, next := tokenAt(toks, i-1), tokenAt(toks, i+1)
+
+	switch tok.Type {
+	case parse.Contract, parse.Interface, parse.Modifier:
+		w.pending = tok.Type
+
+	case parse.Function:
+		w.pending = tok.Type
+		if w.iface == 0 && next.Type == parse.Ident {
+			w.functions = append(w.functions, Candidate{Label: next.Val, Kind: Function, Detail: signature(toks[i+1:])})
+			w.signatures = append(w.signatures, signatureOf(toks[i+1:]))
+		}
+
+	case parse.Lparen:
+		w.parens++
+		if w.pending == parse.Function && w.iface == 0 && w.function == 0 && w.params == 0 {
+			w.params = w.parens
+		}
+
+	case parse.Rparen:
+		if w.params == w.parens {
+			w.params = 0
+		}
+		w.parens--
+
+	case parse.Lbrace:
+		w.depth++
+		switch w.pending {
+		case parse.Contract:
+			w.contract = w.depth
+		case parse.Interface:
+			w.iface = w.depth
+		case parse.Function, parse.Modifier:
+			if w.iface == 0 && w.function == 0 {
+				w.function = w.depth
+			}
+		}
+		w.pending = parse.Illegal
+
+	case parse.Rbrace:
+		w.closeBlock()
+
+	case parse.Ident:
+		w.declare(tok, prev, next, tokenAt(toks, i-2))
+	}
+}
+
+// declare records tok if it is the name of parameter, local or constant
+func (w *walker) declare(tok, prev, next, prevprev parse.Token) {
+	switch {
+	case w.params > 0 && w.params == w.parens && isType(next) &&
+		(prev.Type == parse.Lparen || prev.Type == parse.Comma):
+		// parameters are in scope of the function body
+		w.locals = append(w.locals, local{Candidate{Label: tok.Val, Kind: Variable, Detail: next.Val}, w.depth + 1})
+
+	case w.function > 0 && isType(prev):
+		kind := Variable
+		if prevprev.Type == parse.Const {
+			kind = Constant
+		}
+		w.locals = append(w.locals, local{Candidate{Label: tok.Val, Kind: kind, Detail: prev.Val}, w.depth})
+
+	case w.function == 0 && w.iface == 0 && isType(prev) && prevprev.Type == parse.Const:
+		w.constants = append(w.constants, Candidate{Label: tok.Val, Kind: Constant, Detail: prev.Val})
+	}
+}
+
+// closeBlock drops locals declared in the block being closed
+func (w *walker) closeBlock() {
+	locals := w.locals[:0]
+	for _, l := range w.locals {
+		if l.depth < w.depth {
+			locals = append(locals, l)
+		}
+	}
+	w.locals = locals
+
+	switch w.depth {
+	case w.function:
+		w.function = 0
+	case w.iface:
+		w.iface = 0
+	case w.contract:
+		w.contract = 0
+	}
+	w.depth--
+	w.pending = parse.Illegal
+}
+
+// signature formats the header of function starting from its name
+// as symbol.Function does. e.g. add(int, int) int
+func signature(toks []parse.Token) string {
+	params, ret := header(toks)
+	types := make([]string, 0, len(params))
+	for _, p := range params {
+		types = append(types, p.Type)
+	}
+	return toks[0].Val + "(" + strings.Join(types, ", ") + ") " + ret
+}
+
+// signatureOf returns the signature of function starting from its name,
+// whose label has parameter names. e.g. add(a int, b int) int
+func signatureOf(toks []parse.Token) Signature {
+	params, ret := header(toks)
+	labels := make([]string, 0, len(params))
+	for _, p := range params {
+		labels = append(labels, p.Name+" "+p.Type)
+	}
+	return Signature{
+		Name:       toks[0].Val,
+		Label:      toks[0].Val + "(" + strings.Join(labels, ", ") + ") " + ret,
+		Parameters: params,
+	}
+}
+
+// header returns the parameters and return type of function
+// starting from its name
+func header(toks []parse.Token) ([]Parameter, string) {
+	params := make([]Parameter, 0)
+	returns := make([]string, 0)
+
+	depth := 0
+	closed := false
+	for i := 1; i < len(toks); i++ {
+		tok := toks[i]
+		if tok.Type == parse.Lbrace || tok.Type == parse.Semicolon {
+			break
+		}
+
+		switch {
+		case tok.Type == parse.Lparen:
+			depth++
+		case tok.Type == parse.Rparen:
+			depth--
+			closed = closed || depth == 0
+		case !isType(tok):
+		case closed:
+			returns = append(returns, tok.Val)
+		case depth == 1 && toks[i-1].Type == parse.Ident:
+			params = append(params, Parameter{Name: toks[i-1].Val, Type: tok.Val})
+		}
+	}
+
+	switch len(returns) {
+	case 0:
+		return params, "void"
+	case 1:
+		return params, returns[0]
+	default:
+		return params, "(" + strings.Join(returns, ", ") + ")"
+	}
+}
@@ -0,0 +1,297 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crpyto
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Typed structured data is hashed as EIP-712 does, so that off-chain
+// signed messages like orders or permits can be verified against the
+// hash. The digest to sign is
+//
+//	keccak256(0x1901 || domainSeparator || hashStruct(message))
+//
+// where domainSeparator is hashStruct of the domain with EIP712Domain type.
+
+// DomainType is the name of the struct type of domain
+const DomainType = "EIP712Domain"
+
+// TypedField is a member of struct type
+type TypedField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is structured data with its struct types. It has the same
+// JSON format with the one eth_signTypedData takes.
+type TypedData struct {
+	Types       map[string][]TypedField `json:"types"`
+	PrimaryType string                  `json:"primaryType"`
+	Domain      map[string]interface{}  `json:"domain"`
+	Message     map[string]interface{}  `json:"message"`
+}
+
+// Hash returns the digest of typed data to be signed
+func (td TypedData) Hash() ([]byte, error) {
+	domain, err := td.DomainSeparator()
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	return Keccak256([]byte{0x19, 0x01}, domain, message), nil
+}
+
+// DomainSeparator returns the hash of domain
+func (td TypedData) DomainSeparator() ([]byte, error) {
+	return td.HashStruct(DomainType, td.Domain)
+}
+
+// HashStruct returns keccak256(typeHash || encodeData(data))
+func (td TypedData) HashStruct(name string, data map[string]interface{}) ([]byte, error) {
+	typeHash, err := td.TypeHash(name)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := [][]byte{typeHash}
+	for _, field := range td.Types[name] {
+		value, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing field %s of %s", field.Name, name)
+		}
+
+		b, err := td.encodeValue(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %s: %s", field.Name, name, err)
+		}
+		encoded = append(encoded, b)
+	}
+
+	return Keccak256(encoded...), nil
+}
+
+// TypeHash returns keccak256 of encoded type
+func (td TypedData) TypeHash(name string) ([]byte, error) {
+	encoded, err := td.EncodeType(name)
+	if err != nil {
+		return nil, err
+	}
+	return Keccak256([]byte(encoded)), nil
+}
+
+// EncodeType encodes struct type as name(type1 field1,type2 field2),
+// followed by the struct types it references sorted by name
+//
+//	Mail(Person from,Person to,string contents)Person(string name,address wallet)
+func (td TypedData) EncodeType(name string) (string, error) {
+	deps := make(map[string]bool)
+	if err := td.dependencies(name, deps); err != nil {
+		return "", err
+	}
+	delete(deps, name)
+
+	names := make([]string, 0, len(deps))
+	for dep := range deps {
+		names = append(names, dep)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	for _, n := range append([]string{name}, names...) {
+		fields := make([]string, 0, len(td.Types[n]))
+		for _, field := range td.Types[n] {
+			fields = append(fields, field.Type+" "+field.Name)
+		}
+		out.WriteString(n + "(" + strings.Join(fields, ",") + ")")
+	}
+
+	return out.String(), nil
+}
+
+// dependencies collects struct types referenced by name, including itself
+func (td TypedData) dependencies(name string, deps map[string]bool) error {
+	if deps[name] {
+		return nil
+	}
+
+	fields, ok := td.Types[name]
+	if !ok {
+		return fmt.Errorf("undefined type %s", name)
+	}
+	deps[name] = true
+
+	for _, field := range fields {
+		typ := strings.TrimSuffix(field.Type, "[]")
+		if _, ok := td.Types[typ]; ok {
+			if err := td.dependencies(typ, deps); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeValue encodes value of typ to 32 bytes
+func (td TypedData) encodeValue(typ string, value interface{}) ([]byte, error) {
+	if strings.HasSuffix(typ, "[]") {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v is not array", value)
+		}
+
+		encoded := make([][]byte, 0, len(items))
+		for _, item := range items {
+			b, err := td.encodeValue(strings.TrimSuffix(typ, "[]"), item)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, b)
+		}
+		return Keccak256(encoded...), nil
+	}
+
+	if _, ok := td.Types[typ]; ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v is not %s", value, typ)
+		}
+		return td.HashStruct(typ, data)
+	}
+
+	switch {
+	case typ == "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is not string", value)
+		}
+		return Keccak256([]byte(s)), nil
+
+	case typ == "bytes":
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return Keccak256(b), nil
+
+	case typ == "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v is not bool", value)
+		}
+		if b {
+			return word(big.NewInt(1))
+		}
+		return word(big.NewInt(0))
+
+	case typ == "address":
+		b, err := toBytes(value)
+		if err != nil || len(b) != 20 {
+			return nil, fmt.Errorf("%v is not address", value)
+		}
+		return leftPad(b), nil
+
+	case strings.HasPrefix(typ, "bytes"):
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 32 {
+			return nil, fmt.Errorf("%s is longer than 32 bytes", typ)
+		}
+		return append(b, make([]byte, 32-len(b))...), nil
+
+	case strings.HasPrefix(typ, "int"), strings.HasPrefix(typ, "uint"):
+		n, err := toBigInt(value)
+		if err != nil {
+			return nil, err
+		}
+		return word(n)
+
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+}
+
+// word encodes n to 32 bytes in big endian two's complement
+func word(n *big.Int) ([]byte, error) {
+	if n.BitLen() > 256 {
+		return nil, fmt.Errorf("%s overflows 256 bits", n)
+	}
+	if n.Sign() < 0 {
+		n = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	return leftPad(n.Bytes()), nil
+}
+
+func leftPad(b []byte) []byte {
+	return append(make([]byte, 32-len(b)), b...)
+}
+
+// toBytes converts []byte or 0x prefixed hexadecimal string to bytes
+func toBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		if !strings.HasPrefix(v, "0x") {
+			return nil, fmt.Errorf("%s should start with 0x", v)
+		}
+		return hex.DecodeString(v[2:])
+	default:
+		return nil, fmt.Errorf("%v is not bytes", value)
+	}
+}
+
+// toBigInt converts go integer, JSON number or decimal or 0x prefixed
+// hexadecimal string to big integer
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case float64:
+		f := big.NewFloat(v)
+		n, accuracy := f.Int(nil)
+		if accuracy != big.Exact {
+			return nil, fmt.Errorf("%s is not integer", f.Text('g', -1))
+		}
+		return n, nil
+	case *big.Int:
+		return v, nil
+	case string:
+		n, ok := new(big.Int).SetString(v, 0)
+		if !ok {
+			return nil, fmt.Errorf("%s is not integer", v)
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("%v is not integer", value)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crpyto_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/DE-labtory/koa/crpyto"
+)
+
+// mail is the example of EIP-712
+const mail = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xcccccccccccccccccccccccccccccccccccccccc"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826"},
+		"to": {"name": "Bob", "wallet": "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestTypedData(t *testing.T) {
+	var td crpyto.TypedData
+	if err := json.Unmarshal([]byte(mail), &td); err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := td.EncodeType("Mail")
+	if err != nil {
+		t.Fatalf("EncodeType() returns unexpected error: %s", err)
+	}
+	if expected := "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; encoded != expected {
+		t.Errorf("EncodeType() wrong result. expected=%s, got=%s", expected, encoded)
+	}
+
+	tests := []struct {
+		name     string
+		hash     func() ([]byte, error)
+		expected string
+	}{
+		{"TypeHash", func() ([]byte, error) { return td.TypeHash("Mail") }, "a0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"},
+		{"DomainSeparator", td.DomainSeparator, "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"},
+		{"HashStruct", func() ([]byte, error) { return td.HashStruct("Mail", td.Message) }, "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"},
+		{"Hash", td.Hash, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"},
+	}
+
+	for _, test := range tests {
+		hash, err := test.hash()
+		if err != nil {
+			t.Errorf("%s() returns unexpected error: %s", test.name, err)
+			continue
+		}
+		if result := hex.EncodeToString(hash); result != test.expected {
+			t.Errorf("%s() wrong result. expected=%s, got=%s", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestTypedData_error(t *testing.T) {
+	td := crpyto.TypedData{
+		Types: map[string][]crpyto.TypedField{
+			"Order": {
+				{Name: "maker", Type: "address"},
+				{Name: "amount", Type: "uint256"},
+				{Name: "asset", Type: "Asset"},
+			},
+			"Asset": {
+				{Name: "id", Type: "uint256"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		data        map[string]interface{}
+		expectedErr string
+	}{
+		{
+			name:        "Permit",
+			data:        map[string]interface{}{},
+			expectedErr: "undefined type Permit",
+		},
+		{
+			name:        "Order",
+			data:        map[string]interface{}{"maker": "0x00"},
+			expectedErr: "field maker of Order: 0x00 is not address",
+		},
+		{
+			name:        "Order",
+			data:        map[string]interface{}{"maker": "0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826"},
+			expectedErr: "missing field amount of Order",
+		},
+		{
+			name: "Order",
+			data: map[string]interface{}{
+				"maker":  "0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826",
+				"amount": 1.5,
+				"asset":  map[string]interface{}{"id": 1},
+			},
+			expectedErr: "field amount of Order: 1.5 is not integer",
+		},
+		{
+			name: "Order",
+			data: map[string]interface{}{
+				"maker":  "0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826",
+				"amount": 10,
+				"asset":  map[string]interface{}{"id": true},
+			},
+			expectedErr: "field asset of Order: field id of Asset: true is not integer",
+		},
+	}
+
+	for i, test := range tests {
+		_, err := td.HashStruct(test.name, test.data)
+		if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("test[%d] - HashStruct() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package koa
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// consensusPackages produce bytecode, ABI and vm results, which must be
+// the same on every node whatever its platform is. Parser and type
+// checker decide which contracts compile, so they are included.
+var consensusPackages = []string{"abi", "ast", "crpyto", "encoding", "opcode", "parse", "symbol", "translate", "typecheck", "vm"}
+
+// platformDependent are calls whose result may differ between nodes,
+// by Unicode case folding, float formatting, clock, randomness or
+// environment, keyed by import path of the package
+var platformDependent = map[string]bool{
+	"strings.ToLower":     true,
+	"strings.ToUpper":     true,
+	"strings.ToTitle":     true,
+	"strings.Title":       true,
+	"strings.EqualFold":   true,
+	"unicode.ToLower":     true,
+	"unicode.ToUpper":     true,
+	"strconv.FormatFloat": true,
+	"strconv.ParseFloat":  true,
+	"time.Now":            true,
+	"os.Getenv":           true,
+	"os.LookupEnv":        true,
+	"runtime.GOOS":        true,
+	"runtime.GOARCH":      true,
+	"math/rand.Int":       true,
+	"math/rand.Intn":      true,
+	"math/rand.Read":      true,
+	"crypto/rand.Read":    true,
+}
+
+// auditedCalls are platform dependent calls which are reviewed to be
+// deterministic, keyed by file and call
+var auditedCalls = map[string]bool{
+	// applied to hexadecimal digits which are already validated,
+	// case folding of ASCII doesn't depend on platform
+	"encoding/address.go strings.ToLower": true,
+	"encoding/address.go strings.ToUpper": true,
+}
+
+// TestDeterminism_audit fails when consensus package uses platform
+// dependent call which is not audited, or formats float with fmt.
+// Calls are matched on the package they resolve to, so that
+// aliased and dot imports are found as well.
+func TestDeterminism_audit(t *testing.T) {
+	fset := token.NewFileSet()
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+
+	for _, dir := range consensusPackages {
+		pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+			return !strings.HasSuffix(info.Name(), "_test.go")
+		}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, pkg := range pkgs {
+			paths := make([]string, 0, len(pkg.Files))
+			for path := range pkg.Files {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+
+			files := make([]*ast.File, 0, len(paths))
+			for _, path := range paths {
+				files = append(files, pkg.Files[path])
+			}
+
+			info := &types.Info{
+				Types: map[ast.Expr]types.TypeAndValue{},
+				Uses:  map[*ast.Ident]types.Object{},
+			}
+			if _, err := conf.Check(dir, fset, files, info); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, file := range files {
+				for _, finding := range auditFile(fset, paths[i], file, info) {
+					t.Error(finding)
+				}
+			}
+		}
+	}
+}
+
+// auditFile returns platform dependent calls in file which are not
+// audited
+func auditFile(fset *token.FileSet, path string, file *ast.File, info *types.Info) []string {
+	findings := []string{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			call := qualifiedName(info.Uses[n])
+			if platformDependent[call] && !auditedCalls[filepath.ToSlash(path)+" "+call] {
+				findings = append(findings, fmt.Sprintf("%s: platform dependent %s in consensus package",
+					fset.Position(n.Pos()), call))
+			}
+
+		case *ast.CallExpr:
+			fn, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || !strings.HasPrefix(qualifiedName(info.Uses[fn.Sel]), "fmt.") {
+				return true
+			}
+			for _, arg := range n.Args {
+				if isFloat(info.TypeOf(arg)) {
+					findings = append(findings, fmt.Sprintf("%s: float formatted by fmt in consensus package",
+						fset.Position(arg.Pos())))
+				}
+			}
+		}
+		return true
+	})
+	return findings
+}
+
+// qualifiedName returns the import path and name of package level
+// object, e.g. math/rand.Intn, or empty for other objects
+func qualifiedName(obj types.Object) string {
+	if obj == nil || obj.Pkg() == nil || obj.Parent() != obj.Pkg().Scope() {
+		return ""
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// isFloat reports whether value of type t is float or complex
+func isFloat(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&(types.IsFloat|types.IsComplex) != 0
+}
+
+func TestDeterminism_auditFile(t *testing.T) {
+	src := `package p
+
+import (
+	"fmt"
+	str "strings"
+	. "time"
+)
+
+func f(s string, x float64) (string, error) {
+	Now()
+	return str.ToUpper(s), fmt.Errorf("%v %d", x, 1)
+}
+
+func g(strings struct{ ToLower func(string) string }) string {
+	return strings.ToLower("A")
+}
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("p", fset, []*ast.File{file}, info); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"p.go:10:2: platform dependent time.Now in consensus package",
+		"p.go:11:13: platform dependent strings.ToUpper in consensus package",
+		"p.go:11:45: float formatted by fmt in consensus package",
+	}
+	if findings := auditFile(fset, "p.go", file, info); strings.Join(findings, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("auditFile() wrong findings.\nexpected=%v\ngot=%v", expected, findings)
+	}
+}
+
+// TestDeterminism_compile compiles the same contract repeatedly, so that
+// iteration order of map leaking into bytecode or ABI is detected
+func TestDeterminism_compile(t *testing.T) {
+	input := `
+interface Token {
+	func balance(owner int) int
+}
+contract implements Token {
+	const int FEE = 10
+	const uint LIMIT = 100
+	modifier positive {
+		require(FEE > 0, "fee")
+		_
+	}
+	func balance(owner int) int {
+		return owner
+	}
+	func fee(amount int, rate int = FEE) positive int {
+		int8 small = 1
+		uint big = LIMIT
+		return amount * rate + small
+	}
+	func count(n int) int {
+		int i = 0
+		do {
+			i = i + 1
+		} while (i < n)
+		return i
+	}
+}`
+
+	asm, a, err := Compile(input)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		again, b, err := Compile(input)
+		if err != nil {
+			t.Fatalf("Compile() returns unexpected error: %s", err)
+		}
+		if !bytes.Equal(asm.ToRawByteCode(), again.ToRawByteCode()) {
+			t.Fatalf("Compile() returns different bytecode.\nfirst=%x\nagain=%x", asm.ToRawByteCode(), again.ToRawByteCode())
+		}
+		if fmt.Sprint(a) != fmt.Sprint(b) {
+			t.Fatalf("Compile() returns different ABI.\nfirst=%v\nagain=%v", a, b)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package diag presents diagnostics of parser and type checker in the
+// language of user. Embedders register a Catalog for each locale and
+// ask for Message of the error which koa returned.
+//
+// Catalogs only change the text shown to user. Error() of every error,
+// and the sentinels matched with errors.Is, stay the same whatever the
+// locale is, so that tools can keep matching them.
+package diag
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/typecheck"
+)
+
+// DefaultLocale is the locale which parser and type checker write
+// messages in, it needs no catalog
+const DefaultLocale = "en"
+
+// Catalog translates diagnostic messages to a language. Key is the
+// format of English message as parser and type checker write it, e.g.
+//
+//	"undefined: %s"
+//
+// and value is the format of translated message. Arguments are given
+// to translated format as text, so it refers them with %s, or with
+// %[n]s to change their order.
+type Catalog map[string]string
+
+var mu sync.RWMutex
+var catalogs = map[string]Catalog{}
+
+// Register sets the catalog of locale, replacing catalog
+// registered before
+func Register(locale string, c Catalog) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	catalogs[locale] = c
+}
+
+// Message returns the message of err in the language of locale.
+// Messages without translation, and messages of locale which has no
+// catalog, are left in English.
+func Message(err error, locale string) string {
+	mu.RLock()
+	c, ok := catalogs[locale]
+	mu.RUnlock()
+
+	if !ok || locale == DefaultLocale {
+		return err.Error()
+	}
+	return c.message(err)
+}
+
+func (c Catalog) message(err error) string {
+	switch e := err.(type) {
+	case parse.Error:
+		return c.sprintf("[line %d, column %d] [%s] %s",
+			e.Source.Line, e.Source.Column, parse.TokenTypeMap[e.Source.Type], c.translate(e.Reason))
+	case parse.ExpectError:
+		return c.sprintf("[line %d, column %d] Expected [%s], but got [%s]",
+			e.Source.Line, e.Source.Column, parse.TokenTypeMap[e.Expected], parse.TokenTypeMap[e.Source.Type])
+	case parse.DupSymError:
+		return c.sprintf("[line %d, column %d] symbol [%s] already exist",
+			e.Source.Line, e.Source.Column, e.Source.Val)
+	case parse.PrefixError:
+		return c.sprintf("[line %d, columnd %d] Invalid prefix of %s",
+			e.Source.Line, e.Source.Column, e.Right.String())
+	case parse.NotExistSymError:
+		return c.sprintf("[line %d, column %d] symbol [%s] is not exist",
+			e.Source.Line, e.Source.Column, e.Source.Val)
+	case parse.ConstAssignError:
+		return c.sprintf("[line %d, column %d] cannot assign to constant [%s]",
+			e.Source.Line, e.Source.Column, e.Source.Val)
+	case parse.LimitError:
+		return c.sprintf("[line %d, column %d] number of %s exceeds limit %d",
+			e.Source.Line, e.Source.Column, e.Limit, e.Max)
+	case parse.ImportError:
+		return fmt.Sprintf("[%s] %s", e.Path, c.message(e.Err))
+	case parse.FileError:
+		return fmt.Sprintf("[%s] %s", e.Filename, c.message(e.Err))
+	case typecheck.Error:
+		if e.Pos.IsValid() {
+			return fmt.Sprintf("[%s] [%s] %s", e.Pos, e.Source.String(), c.translate(e.Reason))
+		}
+		return fmt.Sprintf("[%s] %s", e.Source.String(), c.translate(e.Reason))
+	case typecheck.Errors:
+		msgs := make([]string, 0, len(e))
+		for _, err := range e {
+			msgs = append(msgs, c.message(err))
+		}
+		return strings.Join(msgs, "\n")
+	default:
+		return c.translate(err.Error())
+	}
+}
+
+// sprintf formats args with the translation of format, or with
+// format itself when catalog has no translation
+func (c Catalog) sprintf(format string, args ...interface{}) string {
+	t, ok := c[format]
+	if !ok {
+		return fmt.Sprintf(format, args...)
+	}
+	return fmt.Sprintf(t, texts(args)...)
+}
+
+// translate translates message which is already formatted, by
+// matching it against English formats of catalog. When more than one
+// format matches, the one with the most text besides its verbs wins.
+func (c Catalog) translate(msg string) string {
+	formats := make([]string, 0, len(c))
+	for format := range c {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool {
+		li, lj := len(verb.ReplaceAllString(formats[i], "")), len(verb.ReplaceAllString(formats[j], ""))
+		if li != lj {
+			return li > lj
+		}
+		return formats[i] < formats[j]
+	})
+
+	for _, format := range formats {
+		if args, ok := match(format, msg); ok {
+			return fmt.Sprintf(c[format], args...)
+		}
+	}
+	return msg
+}
+
+// verb matches formatting verbs of English format
+var verb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+// match reports whether msg is formatted with format, and returns
+// the text of each argument
+func match(format string, msg string) ([]interface{}, bool) {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	last := 0
+	for _, loc := range verb.FindAllStringIndex(format, -1) {
+		pattern.WriteString(regexp.QuoteMeta(strings.Replace(format[last:loc[0]], "%%", "%", -1)))
+		pattern.WriteString("(.*)")
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(strings.Replace(format[last:], "%%", "%", -1)))
+	pattern.WriteString("$")
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, false
+	}
+
+	m := re.FindStringSubmatch(msg)
+	if m == nil {
+		return nil, false
+	}
+
+	args := make([]interface{}, 0, len(m)-1)
+	for _, arg := range m[1:] {
+		args = append(args, arg)
+	}
+	return args, true
+}
+
+func texts(args []interface{}) []interface{} {
+	out := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		out = append(out, fmt.Sprint(arg))
+	}
+	return out
+}
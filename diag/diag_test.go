@@ -0,0 +1,115 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diag_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DE-labtory/koa/diag"
+	"github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/typecheck"
+)
+
+func init() {
+	diag.Register("ko", diag.Catalog{
+		"[line %d, column %d] symbol [%s] already exist":          "[%s행 %s열] 심볼 [%s]이(가) 이미 존재합니다",
+		"[line %d, column %d] [%s] %s":                            "[%s행 %s열] [%s] %s",
+		"undefined: %s":                                           "정의되지 않은 이름: %s",
+		"undefined function: %s":                                  "정의되지 않은 함수: %s",
+		"cannot use %s (type %s) as type %s in argument %d to %s": "%[5]s의 %[4]s번째 인자로 %[3]s 타입이 필요하지만 %[1]s (%[2]s 타입)을 사용했습니다",
+	})
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		input    string
+		locale   string
+		expected string
+	}{
+		{
+			input: `
+contract {
+	const int A = 1
+	const int A = 2
+}`,
+			locale:   "ko",
+			expected: "[3행 12열] 심볼 [A]이(가) 이미 존재합니다",
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return bar()
+	}
+	func baz(a int) {
+		baz("x")
+	}
+}`,
+			locale: "ko",
+			expected: "[line 3, column 12] [function bar(  )] 정의되지 않은 함수: bar\n" +
+				"[line 6, column 9] [\"x\"] baz의 1번째 인자로 int 타입이 필요하지만 \"x\" (string 타입)을 사용했습니다",
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return bar()
+	}
+}`,
+			locale:   "fr",
+			expected: "[line 3, column 12] [function bar(  )] undefined function: bar",
+		},
+	}
+
+	for i, tt := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(tt.input)))
+		if err == nil {
+			err = typecheck.Check(contract)
+		}
+		if err == nil {
+			t.Fatalf("test[%d] - expected error, but got nil", i)
+		}
+
+		if msg := diag.Message(err, tt.locale); msg != tt.expected {
+			t.Errorf("test[%d] - Message() wrong result.\nexpected=%s\ngot=%s", i, tt.expected, msg)
+		}
+	}
+}
+
+func TestMessage_keepsError(t *testing.T) {
+	_, err := parse.ParseImports(context.Background(), parse.NewTokenBuffer(parse.NewLexer(`
+import "a.koa"
+contract {
+}`)), parse.Limits{}, nil)
+	if err == nil {
+		t.Fatal("expected error, but got nil")
+	}
+
+	expected := "[line 1, column 14] [STRING] cannot import a.koa without resolver"
+	if msg := diag.Message(err, diag.DefaultLocale); msg != expected {
+		t.Errorf("Message() wrong result. expected=%s, got=%s", expected, msg)
+	}
+	if msg := diag.Message(err, "ko"); msg != "[1행 14열] [STRING] cannot import a.koa without resolver" {
+		t.Errorf("Message() wrong result. got=%s", msg)
+	}
+
+	if err.Error() != expected || !errors.Is(err, parse.ErrSyntax) {
+		t.Errorf("Message() should not change error, got=%v", err)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encoding
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/DE-labtory/koa/crpyto"
+)
+
+// AddressLength is the length of address in bytes
+const AddressLength = 20
+
+type AddressError struct {
+	Address string
+	Reason  string
+}
+
+func (e AddressError) Error() string {
+	return fmt.Sprintf("invalid address [%s] - %s", e.Address, e.Reason)
+}
+
+// ChecksumAddress renders address as hexadecimal string with mixed-case
+// checksum, the letter of each nibble is upper-cased when the nibble at the
+// same position of keccak256(lower-case hex) is 8 or higher
+// ex) 0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed => 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed
+func ChecksumAddress(address []byte) (string, error) {
+	if len(address) != AddressLength {
+		return "", AddressError{hex.EncodeToString(address), fmt.Sprintf("length must be %d bytes", AddressLength)}
+	}
+
+	lower := hex.EncodeToString(address)
+	hash := crpyto.Keccak256([]byte(lower))
+
+	checksum := []byte(lower)
+	for i, c := range checksum {
+		if c < 'a' {
+			continue
+		}
+
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			checksum[i] = c - 'a' + 'A'
+		}
+	}
+
+	return "0x" + string(checksum), nil
+}
+
+// DecodeAddress decodes hexadecimal address with or without 0x prefix.
+// When address is mixed-case, its checksum is validated, all lower-case
+// or all upper-case address is accepted without checksum.
+func DecodeAddress(address string) ([]byte, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X")
+	if len(digits) != AddressLength*2 {
+		return nil, AddressError{address, fmt.Sprintf("length must be %d hexadecimal digits", AddressLength*2)}
+	}
+
+	decoded, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, AddressError{address, "not a hexadecimal string"}
+	}
+
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return decoded, nil
+	}
+
+	checksum, err := ChecksumAddress(decoded)
+	if err != nil {
+		return nil, err
+	}
+	if checksum[2:] != digits {
+		return nil, AddressError{address, "wrong checksum"}
+	}
+
+	return decoded, nil
+}
+
+// ValidateChecksumAddress checks that address is 0x prefixed hexadecimal
+// string with correct mixed-case checksum
+func ValidateChecksumAddress(address string) error {
+	if !strings.HasPrefix(address, "0x") {
+		return AddressError{address, "missing 0x prefix"}
+	}
+
+	decoded, err := DecodeAddress(address)
+	if err != nil {
+		return err
+	}
+
+	checksum, err := ChecksumAddress(decoded)
+	if err != nil {
+		return err
+	}
+	if checksum != address {
+		return AddressError{address, "wrong checksum"}
+	}
+
+	return nil
+}
+
+// Address is the address of account. It is printed with checksum.
+type Address [AddressLength]byte
+
+// BytesToAddress returns address of b, b shorter than AddressLength
+// is padded with zero on the left
+func BytesToAddress(b []byte) (Address, error) {
+	var a Address
+	if len(b) > AddressLength {
+		return a, AddressError{hex.EncodeToString(b), fmt.Sprintf("length must be at most %d bytes", AddressLength)}
+	}
+	copy(a[AddressLength-len(b):], b)
+	return a, nil
+}
+
+// String returns address with mixed-case checksum
+func (a Address) String() string {
+	checksum, _ := ChecksumAddress(a[:])
+	return checksum
+}
+
+// Words splits address to the words which vm keeps it in:
+// first 8 bytes, next 8 bytes and last 4 bytes
+func (a Address) Words() [3]int64 {
+	return [3]int64{
+		int64(binary.BigEndian.Uint64(a[0:8])),
+		int64(binary.BigEndian.Uint64(a[8:16])),
+		int64(binary.BigEndian.Uint32(a[16:20])),
+	}
+}
+
+// WordsToAddress joins the words split by Address.Words
+func WordsToAddress(words [3]int64) Address {
+	var a Address
+	binary.BigEndian.PutUint64(a[0:8], uint64(words[0]))
+	binary.BigEndian.PutUint64(a[8:16], uint64(words[1]))
+	binary.BigEndian.PutUint32(a[16:20], uint32(words[2]))
+	return a
+}
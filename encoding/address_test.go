@@ -0,0 +1,149 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encoding_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/DE-labtory/koa/encoding"
+)
+
+// checksumAddresses are the test vectors of EIP-55
+var checksumAddresses = []string{
+	"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+	"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+	"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+}
+
+func TestChecksumAddress(t *testing.T) {
+	for i, expected := range checksumAddresses {
+		address, err := hex.DecodeString(strings.ToLower(expected[2:]))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checksum, err := encoding.ChecksumAddress(address)
+		if err != nil {
+			t.Errorf("test[%d] - ChecksumAddress() returns unexpected error: %s", i, err)
+		}
+		if checksum != expected {
+			t.Errorf("test[%d] - ChecksumAddress() wrong result. expected=%s, got=%s", i, expected, checksum)
+		}
+	}
+
+	if _, err := encoding.ChecksumAddress([]byte{0x01, 0x02}); err == nil {
+		t.Errorf("ChecksumAddress() should fail on short address")
+	}
+}
+
+func TestDecodeAddress(t *testing.T) {
+	tests := []struct {
+		address     string
+		expectedErr string
+	}{
+		{address: checksumAddresses[0]},
+		{address: strings.ToLower(checksumAddresses[0])},
+		{address: strings.ToUpper(checksumAddresses[0][2:])},
+		{
+			address:     "0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			expectedErr: "invalid address [0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed] - wrong checksum",
+		},
+		{
+			address:     "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA",
+			expectedErr: "invalid address [0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA] - length must be 40 hexadecimal digits",
+		},
+		{
+			address:     "0xzaAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			expectedErr: "invalid address [0xzaAeb6053F3E94C9b9A09f33669435E7Ef1BeAed] - not a hexadecimal string",
+		},
+	}
+
+	for i, test := range tests {
+		address, err := encoding.DecodeAddress(test.address)
+		if test.expectedErr == "" {
+			if err != nil {
+				t.Errorf("test[%d] - DecodeAddress() returns unexpected error: %s", i, err)
+			}
+			if len(address) != encoding.AddressLength {
+				t.Errorf("test[%d] - DecodeAddress() wrong length. expected=%d, got=%d", i, encoding.AddressLength, len(address))
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("test[%d] - DecodeAddress() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+		}
+	}
+}
+
+func TestValidateChecksumAddress(t *testing.T) {
+	for i, address := range checksumAddresses {
+		if err := encoding.ValidateChecksumAddress(address); err != nil {
+			t.Errorf("test[%d] - ValidateChecksumAddress() returns unexpected error: %s", i, err)
+		}
+	}
+
+	invalid := []string{
+		strings.ToLower(checksumAddresses[0]),
+		checksumAddresses[0][2:],
+		"0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	}
+	for i, address := range invalid {
+		if err := encoding.ValidateChecksumAddress(address); err == nil {
+			t.Errorf("test[%d] - ValidateChecksumAddress() should fail on %s", i, address)
+		}
+	}
+}
+
+func TestAddress_Words(t *testing.T) {
+	for i, checksum := range checksumAddresses {
+		decoded, err := encoding.DecodeAddress(checksum)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		address, err := encoding.BytesToAddress(decoded)
+		if err != nil {
+			t.Errorf("test[%d] - BytesToAddress() returns unexpected error: %s", i, err)
+		}
+		if result := encoding.WordsToAddress(address.Words()); result != address {
+			t.Errorf("test[%d] - WordsToAddress() wrong result. expected=%x, got=%x", i, address, result)
+		}
+		if result := address.String(); result != checksum {
+			t.Errorf("test[%d] - String() wrong result. expected=%s, got=%s", i, checksum, result)
+		}
+	}
+}
+
+func TestBytesToAddress(t *testing.T) {
+	address, err := encoding.BytesToAddress([]byte{0x01, 0x02})
+	if err != nil {
+		t.Fatalf("BytesToAddress() returns unexpected error: %s", err)
+	}
+
+	expected := encoding.Address{18: 0x01, 19: 0x02}
+	if address != expected {
+		t.Errorf("BytesToAddress() wrong result. expected=%x, got=%x", expected, address)
+	}
+
+	if _, err := encoding.BytesToAddress(make([]byte, encoding.AddressLength+1)); err == nil {
+		t.Errorf("BytesToAddress() should fail on long address")
+	}
+}
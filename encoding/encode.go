@@ -44,6 +44,9 @@ func EncodeOperand(operand interface{}) ([]byte, error) {
 	case int64:
 		return encodeInt(op)
 
+	case uint64:
+		return encodeInt(int64(op))
+
 	case string:
 		return encodeString(op)
 
@@ -118,3 +121,34 @@ func encodeBytes(operand []byte) ([]byte, error) {
 
 	return copiedBytes, nil
 }
+
+// MaxBytesLength is the maximum length of bytes value, which is kept
+// in a word left-aligned with its length in the last byte
+const MaxBytesLength = 7
+
+// PackBytes keeps bytes value in a word
+// ex) []byte{0xde, 0xad} => 0xdead000000000002
+func PackBytes(b []byte) (int64, error) {
+	if len(b) > MaxBytesLength {
+		return 0, fmt.Errorf("Length of bytes must be at most %d", MaxBytesLength)
+	}
+
+	word := make([]byte, 8)
+	copy(word, b)
+	word[7] = byte(len(b))
+
+	return int64(binary.BigEndian.Uint64(word)), nil
+}
+
+// UnpackBytes returns bytes value kept in word by PackBytes
+// ex) 0xdead000000000002 => []byte{0xde, 0xad}
+func UnpackBytes(word int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(word))
+
+	length := int(b[7])
+	if length > MaxBytesLength {
+		length = MaxBytesLength
+	}
+	return b[:length]
+}
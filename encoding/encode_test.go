@@ -102,3 +102,48 @@ func TestEncodeOperand(t *testing.T) {
 		}
 	}
 }
+
+func TestPackBytes(t *testing.T) {
+	tests := []struct {
+		value        []byte
+		expectedWord int64
+		expectedErr  error
+	}{
+		{
+			value:        []byte{},
+			expectedWord: 0,
+		},
+		{
+			value:        []byte{0x01, 0x02, 0x03},
+			expectedWord: 0x0102030000000003,
+		},
+		{
+			value:        []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+			expectedWord: 0x0102030405060707,
+		},
+		{
+			value:       []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+			expectedErr: errors.New("Length of bytes must be at most 7"),
+		},
+	}
+
+	for i, test := range tests {
+		word, err := encoding.PackBytes(test.value)
+		if test.expectedErr != nil {
+			if err == nil || err.Error() != test.expectedErr.Error() {
+				t.Fatalf("test[%d] - PackBytes() error wrong. expectedErr=%v, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("test[%d] - PackBytes() returns unexpected error: %s", i, err)
+		}
+		if word != test.expectedWord {
+			t.Fatalf("test[%d] - PackBytes() result wrong. expected=%x, got=%x", i, test.expectedWord, word)
+		}
+		if unpacked := encoding.UnpackBytes(word); !bytes.Equal(unpacked, test.value) {
+			t.Fatalf("test[%d] - UnpackBytes() result wrong. expected=%x, got=%x", i, test.value, unpacked)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package generate runs the generators which directives in source
+// ask for. Directive is a line comment at the start of line,
+//
+//	//koa:generate interface token_iface.koa Token
+//
+// which runs the generator registered as interface with the argument
+// Token, and writes the koa source it generates to token_iface.koa
+// next to the source. Output is generated again only when it is older
+// than the source or one of the libraries which source imports.
+package generate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/parse"
+)
+
+const prefix = "//koa:generate"
+
+// Directive asks Generator to write Output with Args, Line
+// is 0-based as parse.Token has
+type Directive struct {
+	Line      int
+	Generator string
+	Output    string
+	Args      []string
+}
+
+// Generator generates koa source from src which has the directive.
+// Libraries which src imports are read with r, in which output of
+// the directive is an empty library, so that src can import it.
+type Generator func(src string, r parse.Resolver, args []string) (string, error)
+
+var generators = map[string]Generator{
+	"interface": Interface,
+}
+
+// Register adds generator of name which directives can invoke,
+// it should be called before Run, e.g. in init function
+func Register(name string, g Generator) {
+	generators[name] = g
+}
+
+// Directives returns generate directives of src in order
+func Directives(src string) ([]Directive, error) {
+	directives := []Directive{}
+	for i, line := range strings.Split(src, "\n") {
+		if !strings.HasPrefix(line, prefix+" ") {
+			continue
+		}
+
+		fields := strings.Fields(line[len(prefix):])
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: %s needs generator and output", i, prefix)
+		}
+		directives = append(directives, Directive{
+			Line:      i,
+			Generator: fields[0],
+			Output:    fields[1],
+			Args:      fields[2:],
+		})
+	}
+	return directives, nil
+}
+
+// Result is the outcome of directive, Skipped is set when
+// output was up to date
+type Result struct {
+	Directive
+	Skipped bool
+}
+
+// Run runs the directives of the file of path in order. Directives
+// whose output is up to date are skipped unless force is set.
+func Run(path string, force bool) ([]Result, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	directives, err := Directives(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("[%s] %s", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	results := make([]Result, 0, len(directives))
+	for _, d := range directives {
+		output := filepath.Join(dir, filepath.FromSlash(d.Output))
+		if !force && upToDate(output, path, dir, d.Output) {
+			results = append(results, Result{Directive: d, Skipped: true})
+			continue
+		}
+
+		g, ok := generators[d.Generator]
+		if !ok {
+			return results, fmt.Errorf("[%s] line %d: unknown generator [%s]", path, d.Line, d.Generator)
+		}
+
+		r := outputResolver{parse.DirResolver(dir), d.Output}
+		gen, err := g(string(src), r, d.Args)
+		if err != nil {
+			return results, fmt.Errorf("[%s] line %d: %s", path, d.Line, err)
+		}
+
+		header := fmt.Sprintf("// Code generated by koa generate from %s; DO NOT EDIT.\n\n", filepath.Base(path))
+		if err := ioutil.WriteFile(output, []byte(header+gen), 0644); err != nil {
+			return results, err
+		}
+		results = append(results, Result{Directive: d})
+	}
+
+	return results, nil
+}
+
+// outputResolver resolves output of directive to an empty library,
+// and the others with Resolver
+type outputResolver struct {
+	parse.Resolver
+	output string
+}
+
+func (r outputResolver) Resolve(path string) (string, error) {
+	if path == r.output {
+		return "", nil
+	}
+	return r.Resolver.Resolve(path)
+}
+
+// upToDate reports whether output is newer than the source of path
+// and every library it imports, except output itself
+func upToDate(output string, path string, dir string, rel string) bool {
+	out, err := os.Stat(output)
+	if err != nil {
+		return false
+	}
+
+	seen := map[string]bool{rel: true}
+	inputs := []string{path}
+	for i := 0; i < len(inputs); i++ {
+		in, err := os.Stat(inputs[i])
+		if err != nil || in.ModTime().After(out.ModTime()) {
+			return false
+		}
+
+		src, err := ioutil.ReadFile(inputs[i])
+		if err != nil {
+			return false
+		}
+		for _, lib := range importsOf(string(src)) {
+			if !seen[lib] {
+				seen[lib] = true
+				inputs = append(inputs, filepath.Join(dir, filepath.FromSlash(lib)))
+			}
+		}
+	}
+	return true
+}
+
+// importsOf returns paths of libraries which src imports
+func importsOf(src string) []string {
+	paths := []string{}
+	l := parse.NewLexer(src)
+	for prev := (parse.Token{}); ; {
+		tok := l.NextToken()
+		if tok.Type == parse.Eof || tok == (parse.Token{}) {
+			return paths
+		}
+		if prev.Type == parse.Import && tok.Type == parse.String {
+			paths = append(paths, strings.Trim(tok.Val, `"`))
+		}
+		prev = tok
+	}
+}
+
+// Interface generates interface of contract in src, which has
+// every function of contract. args has the name of interface.
+func Interface(src string, r parse.Resolver, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("interface generator needs name of interface")
+	}
+
+	contract, err := parse.ParseImports(context.Background(), parse.NewTokenBuffer(parse.NewLexer(src)), parse.Limits{}, r)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	out.WriteString(fmt.Sprintf("interface %s {\n", args[0]))
+	for _, fn := range contract.Functions {
+		out.WriteString("\t" + signatureOf(fn) + "\n")
+	}
+	out.WriteString("}\n")
+
+	return out.String(), nil
+}
+
+// signatureOf returns function as it is declared in interface
+func signatureOf(fn *ast.FunctionLiteral) string {
+	params := make([]string, 0, len(fn.Parameters))
+	for _, p := range fn.Parameters {
+		params = append(params, fmt.Sprintf("%s %s", p.Identifier, p.Type))
+	}
+
+	s := fmt.Sprintf("func %s(%s)", fn.Name, strings.Join(params, ", "))
+	if fn.ReturnType != ast.VoidType {
+		s += " " + fn.ReturnTypeString()
+	}
+	return s
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generate_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DE-labtory/koa/generate"
+	"github.com/DE-labtory/koa/parse"
+)
+
+func TestDirectives(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    []generate.Directive
+		expectedErr string
+	}{
+		{
+			input: `//koa:generate interface token_iface.koa Token
+// koa:generate is not directive with space
+	//koa:generate nor indented one
+contract {
+}
+//koa:generate upper upper.koa`,
+			expected: []generate.Directive{
+				{Line: 0, Generator: "interface", Output: "token_iface.koa", Args: []string{"Token"}},
+				{Line: 5, Generator: "upper", Output: "upper.koa", Args: []string{}},
+			},
+		},
+		{
+			input:       "//koa:generate interface",
+			expectedErr: "line 0: //koa:generate needs generator and output",
+		},
+	}
+
+	for i, test := range tests {
+		directives, err := generate.Directives(test.input)
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Directives() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Directives() returns unexpected error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(directives, test.expected) {
+			t.Errorf("test[%d] - Directives() wrong result.\nexpected=%v\ngot=%v", i, test.expected, directives)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "koa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name string, src string, modified time.Time) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	old := time.Now().Add(-time.Hour)
+	write("math.koa", `
+func double(a int) int {
+	return a * 2
+}`, old)
+	write("token.koa", `//koa:generate interface token_iface.koa Token
+import "math.koa"
+import "token_iface.koa"
+
+contract implements Token {
+	func total() int {
+		return double(1)
+	}
+	func pair(a int, b string) (int, string) {
+		return a, b
+	}
+	func burn(amount int) {
+	}
+}`, old)
+
+	path := filepath.Join(dir, "token.koa")
+	results, err := generate.Run(path, false)
+	if err != nil {
+		t.Fatalf("Run() returns unexpected error: %s", err)
+	}
+	if len(results) != 1 || results[0].Skipped {
+		t.Fatalf("Run() wrong result. got=%v", results)
+	}
+
+	output, err := ioutil.ReadFile(filepath.Join(dir, "token_iface.koa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `// Code generated by koa generate from token.koa; DO NOT EDIT.
+
+interface Token {
+	func double(a int) int
+	func total() int
+	func pair(a int, b string) (int, string)
+	func burn(amount int)
+}
+`
+	if string(output) != expected {
+		t.Errorf("Run() wrong output.\nexpected=%s\ngot=%s", expected, output)
+	}
+
+	// generated source is imported by the contract
+	if _, err := parse.ParseFile(path); err != nil {
+		t.Errorf("ParseFile() of contract with generated source returns error: %s", err)
+	}
+
+	tests := []struct {
+		touch           string
+		force           bool
+		expectedSkipped bool
+	}{
+		{expectedSkipped: true},
+		{force: true, expectedSkipped: false},
+		{touch: "math.koa", expectedSkipped: false},
+		{touch: "token.koa", expectedSkipped: false},
+	}
+
+	for i, test := range tests {
+		os.Chtimes(filepath.Join(dir, "token_iface.koa"), old.Add(time.Minute), old.Add(time.Minute))
+		if test.touch != "" {
+			os.Chtimes(filepath.Join(dir, test.touch), time.Now(), time.Now())
+		}
+
+		results, err := generate.Run(path, test.force)
+		if err != nil {
+			t.Fatalf("test[%d] - Run() returns unexpected error: %s", i, err)
+		}
+		if results[0].Skipped != test.expectedSkipped {
+			t.Errorf("test[%d] - Run() wrong skipped. expected=%t, got=%t", i, test.expectedSkipped, results[0].Skipped)
+		}
+	}
+}
+
+func TestRun_unknownGenerator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "koa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token.koa")
+	if err := ioutil.WriteFile(path, []byte("//koa:generate missing out.koa\ncontract {\n}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = generate.Run(path, false)
+	if err == nil || !strings.HasSuffix(err.Error(), "line 0: unknown generator [missing]") {
+		t.Errorf("Run() wrong error. got=%v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	generate.Register("constant", func(src string, r parse.Resolver, args []string) (string, error) {
+		return "const int " + strings.Join(args, " = ") + "\n", nil
+	})
+
+	dir, err := ioutil.TempDir("", "koa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token.koa")
+	if err := ioutil.WriteFile(path, []byte("//koa:generate constant supply.koa SUPPLY 100\ncontract {\n}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := generate.Run(path, false); err != nil {
+		t.Fatalf("Run() returns unexpected error: %s", err)
+	}
+
+	output, err := ioutil.ReadFile(filepath.Join(dir, "supply.koa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(output), "const int SUPPLY = 100\n") {
+		t.Errorf("Run() wrong output. got=%s", output)
+	}
+}
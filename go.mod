@@ -1,5 +1,7 @@
 module github.com/DE-labtory/koa
 
+go 1.13
+
 require (
 	github.com/ethereum/go-ethereum v1.8.21
 	github.com/fatih/color v1.7.0
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package koa
+
+import (
+	"context"
+	"time"
+
+	"github.com/DE-labtory/koa/abi"
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/translate"
+)
+
+// Hook receives the statistics of every compilation. It is provided by
+// the embedder, e.g. to profile its own build farm, koa itself never
+// collects or sends them anywhere. Stats are anonymous, they have no
+// source, names or values of contract.
+type Hook interface {
+	Report(stats Stats)
+}
+
+// HookFunc adapts function to Hook
+type HookFunc func(stats Stats)
+
+func (f HookFunc) Report(stats Stats) {
+	f(stats)
+}
+
+// Stats describes a compilation
+type Stats struct {
+	// Passes are the passes which ran, in order. Passes after the one
+	// which failed are not run.
+	Passes []PassStats
+
+	// Features counts the declarations and statements contract uses
+	// by kind, e.g. "modifier", "for". It is empty when contract
+	// can't be parsed.
+	Features map[string]int
+
+	// Failed reports whether compilation returned error
+	Failed bool
+}
+
+// PassStats is the duration of a compiler pass,
+// Name is one of "parse", "typecheck", "translate" and "abi"
+type PassStats struct {
+	Name     string
+	Duration time.Duration
+}
+
+// CompileWithHook is like CompileLimited but reports the statistics
+// of compilation to hook when it is done, whether it succeeds or not
+func CompileWithHook(ctx context.Context, input string, limits Limits, hook Hook) (translate.Asm, abi.ABI, error) {
+	stats := Stats{Passes: []PassStats{}, Features: map[string]int{}}
+	asm, a, err := compile(ctx, input, limits, &stats)
+	stats.Failed = err != nil
+	hook.Report(stats)
+	return asm, a, err
+}
+
+// timer starts pass, calling the returned function ends it
+func (s *Stats) timer(name string) func() {
+	if s == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		s.Passes = append(s.Passes, PassStats{Name: name, Duration: time.Since(start)})
+	}
+}
+
+// count records features of contract
+func (s *Stats) count(c *ast.Contract) {
+	if s == nil {
+		return
+	}
+
+	s.Features["interface"] += len(c.Interfaces)
+	s.Features["implements"] += len(c.Implements)
+	s.Features["const"] += len(c.Constants)
+	s.Features["enum"] += len(c.Enums)
+	s.Features["error"] += len(c.Errors)
+	s.Features["modifier"] += len(c.Modifiers)
+	s.Features["func"] += len(c.Functions)
+	for _, fn := range c.Functions {
+		s.countBlock(fn.Body)
+	}
+}
+
+func (s *Stats) countBlock(b *ast.BlockStatement) {
+	if b == nil {
+		return
+	}
+	for _, stmt := range b.Statements {
+		s.countStatement(stmt)
+	}
+}
+
+func (s *Stats) countStatement(stmt ast.Statement) {
+	switch st := stmt.(type) {
+	case *ast.IfStatement:
+		s.Features["if"]++
+		s.countBlock(st.Consequence)
+		s.countBlock(st.Alternative)
+	case *ast.ForStatement:
+		s.Features["for"]++
+		s.countBlock(st.Body)
+	case *ast.DoWhileStatement:
+		s.Features["do"]++
+		s.countBlock(st.Body)
+	case *ast.BlockStatement:
+		s.countBlock(st)
+	case *ast.RevertStatement:
+		s.Features["revert"]++
+	case *ast.TupleAssignStatement:
+		s.Features["tuple"]++
+	}
+}
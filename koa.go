@@ -1,29 +1,83 @@
 package koa
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
 
 	"github.com/DE-labtory/koa/abi"
 	"github.com/DE-labtory/koa/parse"
 	"github.com/DE-labtory/koa/translate"
+	"github.com/DE-labtory/koa/typecheck"
 	"github.com/DE-labtory/koa/vm"
 )
 
 func Compile(input string) (translate.Asm, abi.ABI, error) {
-	ast, err := parse.Parse(
+	return CompileContext(context.Background(), input)
+}
+
+// ErrCodeSizeExceeded is returned when compiled bytecode is bigger
+// than Limits.MaxCodeSize
+var ErrCodeSizeExceeded = errors.New("code size exceeds limit")
+
+// Limits bounds the resources used to compile untrusted source.
+// Zero value of each field means no limit.
+type Limits struct {
+	parse.Limits
+
+	// MaxCodeSize is the maximum size of compiled bytecode in bytes
+	MaxCodeSize int
+}
+
+// CompileContext is like Compile but checks ctx at every pass,
+// so that a compilation which is no longer needed can be cancelled
+func CompileContext(ctx context.Context, input string) (translate.Asm, abi.ABI, error) {
+	return CompileLimited(ctx, input, Limits{})
+}
+
+// CompileLimited is like CompileContext but fails as soon as
+// contract exceeds one of limits
+func CompileLimited(ctx context.Context, input string, limits Limits) (translate.Asm, abi.ABI, error) {
+	return compile(ctx, input, limits, nil)
+}
+
+// compile runs the passes of compiler, recording them to stats
+// unless it is nil
+func compile(ctx context.Context, input string, limits Limits, stats *Stats) (translate.Asm, abi.ABI, error) {
+	done := stats.timer("parse")
+	ast, err := parse.ParseLimited(ctx,
 		parse.NewTokenBuffer(
-			parse.NewLexer(input)))
+			parse.NewLexer(input)), limits.Limits)
+	done()
+
+	if err != nil {
+		return translate.Asm{}, abi.ABI{}, err
+	}
+	stats.count(ast)
 
+	done = stats.timer("typecheck")
+	err = typecheck.CheckContext(ctx, ast)
+	done()
 	if err != nil {
 		return translate.Asm{}, abi.ABI{}, err
 	}
 
-	asm, err := translate.CompileContract(*ast)
+	done = stats.timer("translate")
+	asm, err := translate.CompileContractContext(ctx, *ast)
+	done()
 	if err != nil {
 		return asm, abi.ABI{}, err
 	}
 
+	if size := len(asm.ToRawByteCode()); limits.MaxCodeSize > 0 && size > limits.MaxCodeSize {
+		return translate.Asm{}, abi.ABI{}, fmt.Errorf("%w: %d bytes, limit %d", ErrCodeSizeExceeded, size, limits.MaxCodeSize)
+	}
+
+	done = stats.timer("abi")
 	a, err := translate.ExtractAbi(*ast)
+	done()
 	if err != nil {
 		return asm, abi.ABI{}, err
 	}
@@ -31,20 +85,61 @@ func Compile(input string) (translate.Asm, abi.ABI, error) {
 	return asm, *a, nil
 }
 
+// Env describes the environment which contract is executed in
+type Env struct {
+	// ChainID is returned by chainid() builtin, so that contracts can
+	// separate data signed for different chains
+	ChainID int64
+
+	// BlockNumber and Timestamp are returned by block.number and
+	// block.timestamp
+	BlockNumber int64
+	Timestamp   int64
+
+	// Caller is the address which calls contract, returned by msg.sender
+	Caller []byte
+}
+
 func Execute(rawByteCode []byte, function []byte, args []byte) ([]byte, error) {
+	return ExecuteEnv(Env{}, rawByteCode, function, args)
+}
+
+// ExecuteEnv is like Execute but executes contract in env
+func ExecuteEnv(env Env, rawByteCode []byte, function []byte, args []byte) ([]byte, error) {
+	return ExecuteLimited(env, vm.Limits{}, rawByteCode, function, args)
+}
+
+// ExecuteLimited is like ExecuteEnv but fails as soon as contract
+// exceeds one of limits. Pass vm.Sandbox to execute untrusted contract.
+func ExecuteLimited(env Env, limits vm.Limits, rawByteCode []byte, function []byte, args []byte) ([]byte, error) {
 	callFunc := &vm.CallFunc{
-		Func: function,
-		Args: args,
+		Func:    function,
+		Args:    args,
+		ChainID: env.ChainID,
+		Block: vm.Block{
+			Number:    env.BlockNumber,
+			Timestamp: env.Timestamp,
+		},
+		Caller: env.Caller,
 	}
 
-	stack, err := vm.Execute(rawByteCode, vm.NewMemory(), callFunc)
+	stack, err := vm.ExecuteLimited(rawByteCode, vm.NewMemory(), callFunc, limits)
 	if err != nil {
 		return nil, err
 	}
 
-	output := Bytes(int64(stack.Pop()))
+	if stack.Len() == 0 {
+		return nil, vm.ErrStackUnderflow
+	}
+
+	// function returning multiple values leaves all of them,
+	// each is output as a word in order
+	words := make([][]byte, stack.Len())
+	for i := len(words) - 1; i >= 0; i-- {
+		words[i] = Bytes(int64(stack.Pop()))
+	}
 
-	return output, nil
+	return bytes.Join(words, nil), nil
 }
 
 func Bytes(item int64) []byte {
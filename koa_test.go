@@ -17,15 +17,24 @@
 package koa
 
 import (
+	"context"
 	"errors"
 	"os"
+	"reflect"
+	"sync"
 	"testing"
 
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 
 	"github.com/DE-labtory/koa/abi"
+	"github.com/DE-labtory/koa/encoding"
+	"github.com/DE-labtory/koa/opcode"
+	"github.com/DE-labtory/koa/parse"
 	"github.com/DE-labtory/koa/translate"
+	"github.com/DE-labtory/koa/typecheck"
+	"github.com/DE-labtory/koa/vm"
 )
 
 type testData struct {
@@ -60,7 +69,9 @@ func defineAsm() []testData {
 		{
 			fileName: "test/jun.koa",
 			asm:      nil,
-			err:      errors.New("[junbeomlee] definition doesn't exist"),
+			err: errors.New("[line 2, column 33] [junbeomlee] junbeomlee is not a variable\n" +
+				"[line 8, column 18] [return 5] cannot return int in function junbeomlee (return type string)\n" +
+				"[line 11, column 14] [return money] cannot return int in function junbeomlee (return type string)"),
 		},
 		//{
 		//	fileName: "test/add1.koa",
@@ -458,3 +469,1234 @@ func TestExecute(t *testing.T) {
 		}
 	}
 }
+
+func TestCompile_errorKind(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr error
+	}{
+		{
+			input: `contract {
+	func foo( {
+	}
+}`,
+			expectedErr: parse.ErrSyntax,
+		},
+		{
+			input: `contract {
+	func foo() int {
+		return true
+	}
+}`,
+			expectedErr: typecheck.ErrType,
+		},
+		{
+			// reason is reverted with as a single word
+			input: `contract {
+	func foo(a int) int {
+		require(a > 0, "positive")
+		return a
+	}
+}`,
+			expectedErr: typecheck.ErrType,
+		},
+		{
+			input: `contract {
+	func foo() int {
+		bytes b = 0x"0102030405060708"
+		return b[0]
+	}
+}`,
+			expectedErr: translate.ErrCompile,
+		},
+		{
+			// internal calls aren't compiled yet, they must not
+			// compile to nothing and underflow at runtime
+			input: `contract {
+	func add(a int, b int) int {
+		return a + b
+	}
+	func foo() int {
+		return add(1, 2)
+	}
+}`,
+			expectedErr: translate.ErrCompile,
+		},
+		{
+			input: `contract {
+	func bar() {
+	}
+	func foo() {
+		bar()
+	}
+}`,
+			expectedErr: translate.ErrCompile,
+		},
+	}
+
+	for i, test := range tests {
+		_, _, err := Compile(test.input)
+		if !errors.Is(err, test.expectedErr) {
+			t.Errorf("test[%d] - Compile() wrong error kind. expected=%v, got=%v", i, test.expectedErr, err)
+		}
+	}
+
+	_, _, err := Compile(`contract {
+	func foo() int {
+		return true
+	}
+}`)
+	var typeErr typecheck.Error
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Compile() error should be typecheck.Error, got=%T", err)
+	}
+	if typeErr.Reason != "cannot return bool in function foo (return type int)" {
+		t.Errorf("wrong reason of type error, got=%s", typeErr.Reason)
+	}
+}
+
+func TestExecute_errorKind(t *testing.T) {
+	_, err := Execute([]byte{uint8(opcode.Add)}, nil, nil)
+	if !errors.Is(err, vm.ErrStackUnderflow) {
+		t.Errorf("Execute() wrong error kind. expected=%v, got=%v", vm.ErrStackUnderflow, err)
+	}
+}
+
+func TestCompileContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := CompileContext(ctx, `contract {
+	func foo() int {
+		return 1
+	}
+}`)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("CompileContext() with cancelled context wrong error. expected=%v, got=%v", context.Canceled, err)
+	}
+}
+
+func TestCompileLimited(t *testing.T) {
+	input := `contract {
+	func foo() int {
+		return 1
+	}
+}`
+
+	if _, _, err := CompileLimited(context.Background(), input, Limits{MaxCodeSize: 1024}); err != nil {
+		t.Fatalf("CompileLimited() returns unexpected error: %s", err)
+	}
+
+	_, _, err := CompileLimited(context.Background(), input, Limits{MaxCodeSize: 8})
+	if !errors.Is(err, ErrCodeSizeExceeded) {
+		t.Errorf("CompileLimited() wrong error. expected=%v, got=%v", ErrCodeSizeExceeded, err)
+	}
+
+	_, _, err = CompileLimited(context.Background(), input, Limits{Limits: parse.Limits{MaxNodes: 1}})
+	if !errors.Is(err, parse.ErrLimitExceeded) {
+		t.Errorf("CompileLimited() wrong error. expected=%v, got=%v", parse.ErrLimitExceeded, err)
+	}
+}
+
+func TestCompileAndExecute_forStatement(t *testing.T) {
+	input := `contract {
+	func sum(n int) int {
+		int total = 0
+		for (int i = 1; i <= n; i = i + 1) {
+			total = total + i
+		}
+		return total
+	}
+
+	func countdown(n int) int {
+		int steps = 0
+		for (n > 0) {
+			n = n - 1
+			steps = steps + 1
+		}
+		return steps
+	}
+}`
+
+	asm, _, err := Compile(input)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		function string
+		arg      int
+		expected []byte
+	}{
+		{"sum(int)", 10, Bytes(55)},
+		{"sum(int)", 0, Bytes(0)},
+		{"countdown(int)", 7, Bytes(7)},
+	}
+
+	for i, test := range tests {
+		args, err := abi.Encode(test.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := Execute(asm.ToRawByteCode(), abi.Selector(test.function), args)
+		if err != nil {
+			t.Errorf("test[%d] - Execute() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - Execute() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestCompileAndExecute_constStatement(t *testing.T) {
+	input := `contract {
+	const int FEE = 100
+	const int DOUBLE_FEE = FEE * 2
+	const bool ENABLED = DOUBLE_FEE > FEE
+
+	func charge(amount int) int {
+		if (ENABLED) {
+			return amount + DOUBLE_FEE
+		}
+		return amount
+	}
+}`
+
+	asm, _, err := Compile(input)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	args, err := abi.Encode(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := Execute(asm.ToRawByteCode(), abi.Selector("charge(int)"), args)
+	if err != nil {
+		t.Fatalf("Execute() returns unexpected error: %s", err)
+	}
+	if expected := Bytes(205); !bytes.Equal(output, expected) {
+		t.Errorf("Execute() wrong output. expected=%x, got=%x", expected, output)
+	}
+}
+
+func TestCompileAndExecute_uint(t *testing.T) {
+	input := `contract {
+	func wrap(a uint) uint {
+		uint max = a - 1
+		return max / 2
+	}
+
+	func exceeds(a uint) bool {
+		uint max = a - 1
+		return max > 5
+	}
+
+	func signed(a int) bool {
+		int min = a - 1
+		return min > 5
+	}
+}`
+
+	asm, _, err := Compile(input)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		function string
+		arg      interface{}
+		expected []byte
+	}{
+		{"wrap(uint)", uint64(0), Bytes(int64(^uint64(0) >> 1))},
+		{"exceeds(uint)", uint64(0), Bytes(1)},
+		{"signed(int)", 0, Bytes(0)},
+	}
+
+	for i, test := range tests {
+		args, err := abi.Encode(test.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := Execute(asm.ToRawByteCode(), abi.Selector(test.function), args)
+		if err != nil {
+			t.Errorf("test[%d] - Execute() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - Execute() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestCompileAndExecute_sizedInteger(t *testing.T) {
+	input := `contract {
+	func inc(a int8) int8 {
+		return a + 1
+	}
+
+	func neg(a int8) int8 {
+		return -a
+	}
+
+	func widen(a int8) int {
+		int16 b = a
+		return b * 300
+	}
+}`
+
+	asm, _, err := Compile(input)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		function string
+		arg      int
+		expected []byte
+	}{
+		{"inc(int8)", 1, Bytes(2)},
+		{"inc(int8)", 127, Bytes(-128)},
+		{"inc(int8)", 255, Bytes(0)},
+		{"neg(int8)", -128, Bytes(-128)},
+		{"widen(int8)", 100, Bytes(30000)},
+		{"widen(int8)", 127, Bytes(-27436)},
+	}
+
+	for i, test := range tests {
+		args, err := abi.Encode(test.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := Execute(asm.ToRawByteCode(), abi.Selector(test.function), args)
+		if err != nil {
+			t.Errorf("test[%d] - Execute() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - Execute() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestCompileAndExecute_require(t *testing.T) {
+	asm, _, err := Compile(`contract {
+	func withdraw(amount int) int {
+		require(amount > 0, "amount")
+		assert(amount != 13)
+		return amount * 2
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		arg         int
+		expected    []byte
+		expectedErr string
+	}{
+		{5, Bytes(10), ""},
+		{0, nil, `Execution reverted: "amount"`},
+		{13, nil, "Execution reverted"},
+	}
+
+	for i, test := range tests {
+		args, err := abi.Encode(test.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := Execute(asm.ToRawByteCode(), abi.Selector("withdraw(int)"), args)
+		if test.expectedErr != "" {
+			if !errors.Is(err, vm.ErrRevert) || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Execute() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Execute() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - Execute() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestExecuteEnv_chainID(t *testing.T) {
+	asm, _, err := Compile(`contract {
+	func chain() int {
+		return chainid()
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		env      Env
+		expected []byte
+	}{
+		{Env{}, Bytes(0)},
+		{Env{ChainID: 1001}, Bytes(1001)},
+	}
+
+	for i, test := range tests {
+		output, err := ExecuteEnv(test.env, asm.ToRawByteCode(), abi.Selector("chain()"), nil)
+		if err != nil {
+			t.Errorf("test[%d] - ExecuteEnv() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - ExecuteEnv() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestExecuteEnv_block(t *testing.T) {
+	asm, _, err := Compile(`contract {
+	func unlocked(at int) bool {
+		return block.timestamp >= at
+	}
+
+	func height() int {
+		return block.number
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	at, err := abi.Encode(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		env      Env
+		function string
+		args     []byte
+		expected []byte
+	}{
+		{Env{Timestamp: 999}, "unlocked(int)", at, Bytes(0)},
+		{Env{Timestamp: 1000}, "unlocked(int)", at, Bytes(1)},
+		{Env{BlockNumber: 42}, "height()", nil, Bytes(42)},
+	}
+
+	for i, test := range tests {
+		output, err := ExecuteEnv(test.env, asm.ToRawByteCode(), abi.Selector(test.function), test.args)
+		if err != nil {
+			t.Errorf("test[%d] - ExecuteEnv() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - ExecuteEnv() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestExecuteEnv_caller(t *testing.T) {
+	asm, _, err := Compile(`contract {
+	func isOwner(owner address) bool {
+		address sender = msg.sender
+		return sender == owner
+	}
+
+	func isNotOwner(owner address) bool {
+		return msg.sender != owner
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	owner, err := hex.DecodeString("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := hex.DecodeString("5aaeb6053f3e94c9b9a09f33669435e7ef1beaee")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pointer to the size, size and value of address argument
+	args := append(Bytes(8), Bytes(int64(len(owner)))...)
+	args = append(args, owner...)
+
+	tests := []struct {
+		env      Env
+		function string
+		expected []byte
+	}{
+		{Env{Caller: owner}, "isOwner(address)", Bytes(1)},
+		{Env{Caller: other}, "isOwner(address)", Bytes(0)},
+		{Env{}, "isOwner(address)", Bytes(0)},
+		{Env{Caller: owner}, "isNotOwner(address)", Bytes(0)},
+		{Env{Caller: other}, "isNotOwner(address)", Bytes(1)},
+	}
+
+	for i, test := range tests {
+		output, err := ExecuteEnv(test.env, asm.ToRawByteCode(), abi.Selector(test.function), args)
+		if err != nil {
+			t.Errorf("test[%d] - ExecuteEnv() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - ExecuteEnv() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestCompileAndExecute_bytes(t *testing.T) {
+	asm, _, err := Compile(`contract {
+	func at(data bytes, i int) int {
+		return data[i]
+	}
+
+	func size(data bytes) int {
+		return len(data)
+	}
+
+	func literal() int {
+		bytes hash = 0x"dead"
+		return hash[1] + len(hash)
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	data := []byte{0xde, 0xad, 0xbe, 0xef}
+	first, err := abi.Encode(data, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := abi.Encode(data, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := abi.Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		function string
+		args     []byte
+		expected []byte
+	}{
+		{"at(bytes,int)", first, Bytes(0xde)},
+		{"at(bytes,int)", last, Bytes(0xef)},
+		{"size(bytes)", size, Bytes(4)},
+		{"literal()", nil, Bytes(0xad + 2)},
+	}
+
+	for i, test := range tests {
+		output, err := Execute(asm.ToRawByteCode(), abi.Selector(test.function), test.args)
+		if err != nil {
+			t.Errorf("test[%d] - Execute() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - Execute() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+
+	outOfRange, err := abi.Encode(data, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Execute(asm.ToRawByteCode(), abi.Selector("at(bytes,int)"), outOfRange); !errors.Is(err, vm.ErrIndexOutOfRange) {
+		t.Errorf("Execute() wrong error. expected=%v, got=%v", vm.ErrIndexOutOfRange, err)
+	}
+}
+
+func TestCompileAndExecute_multipleValues(t *testing.T) {
+	asm, a, err := Compile(`contract {
+	func pair(n int) (int, bool) {
+		int doubled, bool positive = n * 2, n > 0
+		return doubled, positive
+	}
+
+	func unused() (int, bool) {
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tuples := 0
+	for _, m := range a.Methods {
+		if m.Output.Type.Type != abi.Tuple {
+			continue
+		}
+		tuples++
+		if len(m.Outputs) != 2 || m.Outputs[0].Type.Type != abi.Integer || m.Outputs[1].Type.Type != abi.Boolean {
+			t.Errorf("Compile() wrong abi outputs of %s. got=%v", m.Name, m.Outputs)
+		}
+	}
+	if tuples != 2 {
+		t.Errorf("Compile() wrong number of functions returning tuple. expected=2, got=%d", tuples)
+	}
+
+	args, err := abi.Encode(21)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := Execute(asm.ToRawByteCode(), abi.Selector("pair(int)"), args)
+	if err != nil {
+		t.Fatalf("Execute() returns unexpected error: %s", err)
+	}
+
+	expected := append(Bytes(42), Bytes(1)...)
+	if !bytes.Equal(output, expected) {
+		t.Errorf("Execute() wrong output. expected=%x, got=%x", expected, output)
+	}
+}
+
+func TestCompileAndExecute_modifier(t *testing.T) {
+	asm, _, err := Compile(`contract {
+	modifier unlocked {
+		require(block.number > 100, "locked")
+		_
+	}
+
+	func withdraw(amount int) unlocked int {
+		return amount
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	args, err := abi.Encode(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ExecuteEnv(Env{BlockNumber: 100}, asm.ToRawByteCode(), abi.Selector("withdraw(int)"), args); !errors.Is(err, vm.ErrRevert) {
+		t.Errorf("ExecuteEnv() wrong error. expected=%v, got=%v", vm.ErrRevert, err)
+	}
+
+	output, err := ExecuteEnv(Env{BlockNumber: 101}, asm.ToRawByteCode(), abi.Selector("withdraw(int)"), args)
+	if err != nil {
+		t.Fatalf("ExecuteEnv() returns unexpected error: %s", err)
+	}
+	if !bytes.Equal(output, Bytes(7)) {
+		t.Errorf("ExecuteEnv() wrong output. expected=%x, got=%x", Bytes(7), output)
+	}
+}
+
+func TestCompileAndExecute_supportsInterface(t *testing.T) {
+	input := `
+pragma erc165
+interface Balance {
+	func balance(owner int) int
+}
+
+contract implements Balance {
+	func balance(owner int) int {
+		return 0
+	}
+}`
+	asm, _, err := Compile(input)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(input)))
+	if err != nil {
+		t.Fatalf("Parse() returns unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		id       []byte
+		expected []byte
+	}{
+		{translate.InterfaceID(*contract.Interfaces[0]), Bytes(1)},
+		{abi.Selector("supportsInterface(int)"), Bytes(1)},
+		{abi.Selector("transfer(int,int)"), Bytes(0)},
+	}
+
+	for i, test := range tests {
+		args, err := abi.Encode(int(binary.BigEndian.Uint32(test.id)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := Execute(asm.ToRawByteCode(), abi.Selector("supportsInterface(int)"), args)
+		if err != nil {
+			t.Fatalf("test[%d] - Execute() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - Execute() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestCompileAndExecute_blankIdentifier(t *testing.T) {
+	asm, _, err := Compile(`
+contract {
+	func foo(_ int, b int) int {
+		int _ = b * 2
+		_ = b + 1
+		return b
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	args, err := abi.Encode(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := Execute(asm.ToRawByteCode(), abi.Selector("foo(int,int)"), args)
+	if err != nil {
+		t.Fatalf("Execute() returns unexpected error: %s", err)
+	}
+	if !bytes.Equal(output, Bytes(2)) {
+		t.Errorf("Execute() wrong output. expected=%x, got=%x", Bytes(2), output)
+	}
+}
+
+func TestCompileAndExecute_doWhileStatement(t *testing.T) {
+	asm, _, err := Compile(`contract {
+	func digits(n int) int {
+		int count = 0
+		do {
+			n = n / 10
+			count = count + 1
+		} while (n > 0)
+		return count
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		arg      int
+		expected []byte
+	}{
+		{12345, Bytes(5)},
+		{7, Bytes(1)},
+		// body runs once even though condition is false
+		{0, Bytes(1)},
+	}
+
+	for i, test := range tests {
+		args, err := abi.Encode(test.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := Execute(asm.ToRawByteCode(), abi.Selector("digits(int)"), args)
+		if err != nil {
+			t.Errorf("test[%d] - Execute() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - Execute() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestExecuteLimited_sandbox(t *testing.T) {
+	asm, _, err := Compile(`
+contract {
+	func spin() int {
+		int i = 0
+		for (;;) {
+			i = i + 1
+		}
+		return i
+	}
+
+	func one() int {
+		return 1
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	_, err = ExecuteLimited(Env{}, vm.Sandbox, asm.ToRawByteCode(), abi.Selector("spin()"), nil)
+	if !errors.Is(err, vm.ErrStepLimit) {
+		t.Errorf("ExecuteLimited() wrong error. expected=%v, got=%v", vm.ErrStepLimit, err)
+	}
+
+	output, err := ExecuteLimited(Env{}, vm.Sandbox, asm.ToRawByteCode(), abi.Selector("one()"), nil)
+	if err != nil {
+		t.Fatalf("ExecuteLimited() returns unexpected error: %s", err)
+	}
+	if !bytes.Equal(output, Bytes(1)) {
+		t.Errorf("ExecuteLimited() wrong output. expected=%x, got=%x", Bytes(1), output)
+	}
+}
+
+func TestExecuteLimited_hostileArgs(t *testing.T) {
+	asm, _, err := Compile(`contract {
+	func f(a int) int {
+		return a
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tests := [][]byte{
+		nil,
+		{0x01, 0x02},
+		Bytes(1 << 40),
+		append(Bytes(8), Bytes(1<<40)...),
+		append(Bytes(8), Bytes(-8)...),
+		append(append(Bytes(8), Bytes(2)...), 0x01, 0x02),
+	}
+
+	for i, args := range tests {
+		_, err := ExecuteLimited(Env{}, vm.Sandbox, asm.ToRawByteCode(), abi.Selector("f(int)"), args)
+		if !errors.Is(err, vm.ErrInvalidData) {
+			t.Errorf("test[%d] - ExecuteLimited() wrong error. expected=%v, got=%v", i, vm.ErrInvalidData, err)
+		}
+	}
+}
+
+func TestCompileAndExecute_conversion(t *testing.T) {
+	asm, _, err := Compile(`
+contract {
+	func next(n int) int {
+		string s = itoa(n)
+		return atoi(s) + 1
+	}
+
+	func isFF(n int) bool {
+		return hex(n) == "ff"
+	}
+
+	func parse() int {
+		return atoi("-42")
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		function string
+		args     []interface{}
+		expected []byte
+	}{
+		{"next(int)", []interface{}{41}, Bytes(42)},
+		{"isFF(int)", []interface{}{255}, Bytes(1)},
+		{"isFF(int)", []interface{}{254}, Bytes(0)},
+		{"isFF(int)", []interface{}{0xffffff}, Bytes(0)},
+		{"next(int)", []interface{}{999999}, Bytes(1000000)},
+		{"parse()", nil, Bytes(-42)},
+	}
+
+	for i, test := range tests {
+		args, err := abi.Encode(test.args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := Execute(asm.ToRawByteCode(), abi.Selector(test.function), args)
+		if err != nil {
+			t.Fatalf("test[%d] - Execute() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - Execute() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+
+	// itoa and hex make strings of at most 6 characters
+	overflows := []struct {
+		function string
+		arg      int
+	}{
+		{"next(int)", 1000000},
+		{"next(int)", 10000000},
+		{"isFF(int)", 0x1000000},
+	}
+
+	for i, test := range overflows {
+		args, err := abi.Encode(test.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Execute(asm.ToRawByteCode(), abi.Selector(test.function), args); !errors.Is(err, vm.ErrConversion) {
+			t.Errorf("test[%d] - Execute() wrong error. expected=%v, got=%v", i, vm.ErrConversion, err)
+		}
+	}
+}
+
+func TestCompileAndExecute_customError(t *testing.T) {
+	asm, ab, err := Compile(`
+contract {
+	error Insufficient(needed int, got int)
+
+	func withdraw(amount int) int {
+		if (amount > 100) {
+			revert Insufficient(amount, 100)
+		}
+		return amount
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	if len(ab.Errors) != 1 || ab.Errors[0].Name != "Insufficient" || len(ab.Errors[0].Arguments) != 2 {
+		t.Errorf("Compile() wrong abi errors. got=%v", ab.Errors)
+	}
+
+	args, err := abi.Encode(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := Execute(asm.ToRawByteCode(), abi.Selector("withdraw(int)"), args)
+	if err != nil {
+		t.Fatalf("Execute() returns unexpected error: %s", err)
+	}
+	if !bytes.Equal(output, Bytes(7)) {
+		t.Errorf("Execute() wrong output. expected=%x, got=%x", Bytes(7), output)
+	}
+
+	args, err = abi.Encode(150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, execErr := Execute(asm.ToRawByteCode(), abi.Selector("withdraw(int)"), args)
+	if !errors.Is(execErr, vm.ErrRevert) {
+		t.Fatalf("Execute() wrong error. expected=%v, got=%v", vm.ErrRevert, execErr)
+	}
+
+	payload, err := abi.Encode(150, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := append(abi.Selector("Insufficient(int,int)"), payload...)
+
+	var revert vm.RevertError
+	if !errors.As(execErr, &revert) || !bytes.Equal(revert.Data, expected) {
+		t.Errorf("Execute() wrong revert data. expected=%x, got=%v", expected, execErr)
+	}
+}
+
+func TestCompileAndExecute_enum(t *testing.T) {
+	asm, _, err := Compile(`
+contract {
+	enum Status { Pending, Active, Closed }
+	const int LAST = Status.Closed
+
+	func next(s int) int {
+		if (s == Status.Closed) {
+			return Status.Pending
+		}
+		return s + 1
+	}
+
+	func last() int {
+		return LAST
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		function string
+		args     []interface{}
+		expected []byte
+	}{
+		{"next(int)", []interface{}{0}, Bytes(1)},
+		{"next(int)", []interface{}{2}, Bytes(0)},
+		{"last()", nil, Bytes(2)},
+	}
+
+	for i, test := range tests {
+		args, err := abi.Encode(test.args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := Execute(asm.ToRawByteCode(), abi.Selector(test.function), args)
+		if err != nil {
+			t.Fatalf("test[%d] - Execute() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - Execute() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestCompileAndExecute_shortCircuit(t *testing.T) {
+	asm, _, err := Compile(`
+contract {
+	func divisible(n int) bool {
+		return n != 0 && 12 % n == 0
+	}
+
+	func small(n int) bool {
+		return n == 0 || 12 / n > 3
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		function string
+		arg      int
+		expected []byte
+	}{
+		{"divisible(int)", 0, Bytes(0)},
+		{"divisible(int)", 4, Bytes(1)},
+		{"divisible(int)", 5, Bytes(0)},
+		{"small(int)", 0, Bytes(1)},
+		{"small(int)", 2, Bytes(1)},
+		{"small(int)", 4, Bytes(0)},
+	}
+
+	for i, test := range tests {
+		args, err := abi.Encode(test.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// right operand divides by zero unless it is skipped
+		output, err := Execute(asm.ToRawByteCode(), abi.Selector(test.function), args)
+		if err != nil {
+			t.Fatalf("test[%d] - Execute() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - Execute() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+func TestCompileAndExecute_incDec(t *testing.T) {
+	asm, _, err := Compile(`
+contract {
+	func count(n int) int {
+		int c = 0
+		for (int i = 0; i < n; i++) {
+			c++
+			c++
+		}
+		c--
+		return c
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	args, err := abi.Encode(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := Execute(asm.ToRawByteCode(), abi.Selector("count(int)"), args)
+	if err != nil {
+		t.Fatalf("Execute() returns unexpected error: %s", err)
+	}
+	if !bytes.Equal(output, Bytes(9)) {
+		t.Errorf("Execute() wrong output. expected=%x, got=%x", Bytes(9), output)
+	}
+}
+
+func TestCompileWithHook(t *testing.T) {
+	var stats []Stats
+	hook := HookFunc(func(s Stats) {
+		stats = append(stats, s)
+	})
+
+	_, _, err := CompileWithHook(context.Background(), `
+contract {
+	const int FEE = 1
+	modifier positive {
+		require(FEE > 0, "fee")
+		_
+	}
+	func sum(n int) positive int {
+		int s = 0
+		for (int i = 0; i < n; i++) {
+			if (i > 1) {
+				s = s + i
+			}
+		}
+		return s
+	}
+}`, Limits{}, hook)
+	if err != nil {
+		t.Fatalf("CompileWithHook() returns unexpected error: %s", err)
+	}
+
+	if _, _, err := CompileWithHook(context.Background(), `
+contract {
+	func foo() int {
+		return true
+	}
+}`, Limits{}, hook); err == nil {
+		t.Fatal("CompileWithHook() returns no error for wrong contract")
+	}
+
+	if len(stats) != 2 {
+		t.Fatalf("hook reported %d times, expected 2", len(stats))
+	}
+
+	passes := func(s Stats) []string {
+		names := []string{}
+		for _, p := range s.Passes {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	if names := passes(stats[0]); !reflect.DeepEqual(names, []string{"parse", "typecheck", "translate", "abi"}) || stats[0].Failed {
+		t.Errorf("wrong stats of compilation. passes=%v, failed=%v", names, stats[0].Failed)
+	}
+	expected := map[string]int{
+		"interface": 0, "implements": 0, "const": 1, "enum": 0, "error": 0,
+		"modifier": 1, "func": 1, "for": 1, "if": 1,
+	}
+	if !reflect.DeepEqual(stats[0].Features, expected) {
+		t.Errorf("wrong features. expected=%v, got=%v", expected, stats[0].Features)
+	}
+
+	if names := passes(stats[1]); !reflect.DeepEqual(names, []string{"parse", "typecheck"}) || !stats[1].Failed {
+		t.Errorf("wrong stats of failed compilation. passes=%v, failed=%v", names, stats[1].Failed)
+	}
+}
+
+func TestExecuteEnv_addressLiteral(t *testing.T) {
+	asm, _, err := Compile(`contract {
+	const address ADMIN = 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed
+
+	func isAdmin() bool {
+		return msg.sender == ADMIN
+	}
+
+	func getAdmin() address {
+		return ADMIN
+	}
+
+	func isSame(owner address) bool {
+		return owner == 0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359
+	}
+}`)
+	if err != nil {
+		t.Fatalf("Compile() returns unexpected error: %s", err)
+	}
+
+	admin, err := encoding.DecodeAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := encoding.DecodeAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := ExecuteEnv(Env{Caller: admin}, asm.ToRawByteCode(), abi.Selector("isAdmin()"), nil)
+	if err != nil || !bytes.Equal(output, Bytes(1)) {
+		t.Errorf("ExecuteEnv() isAdmin wrong output. expected=%x, got=%x, err=%v", Bytes(1), output, err)
+	}
+
+	output, err = ExecuteEnv(Env{}, asm.ToRawByteCode(), abi.Selector("getAdmin()"), nil)
+	if err != nil {
+		t.Fatalf("ExecuteEnv() returns unexpected error: %s", err)
+	}
+	if len(output) != 24 {
+		t.Fatalf("ExecuteEnv() wrong output length. expected=24, got=%d", len(output))
+	}
+	var words [3]int64
+	for i := range words {
+		words[i] = int64(binary.BigEndian.Uint64(output[i*8 : i*8+8]))
+	}
+	if result := encoding.WordsToAddress(words).String(); result != "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed" {
+		t.Errorf("ExecuteEnv() getAdmin wrong output. got=%s", result)
+	}
+
+	for i, test := range []struct {
+		arg      []byte
+		expected []byte
+	}{
+		{other, Bytes(1)},
+		{admin, Bytes(0)},
+	} {
+		address, err := encoding.BytesToAddress(test.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		args, err := abi.Encode(address)
+		if err != nil {
+			t.Fatalf("test[%d] - Encode() returns unexpected error: %s", i, err)
+		}
+
+		output, err := ExecuteEnv(Env{}, asm.ToRawByteCode(), abi.Selector("isSame(address)"), args)
+		if err != nil {
+			t.Errorf("test[%d] - ExecuteEnv() returns unexpected error: %s", i, err)
+		}
+		if !bytes.Equal(output, test.expected) {
+			t.Errorf("test[%d] - ExecuteEnv() wrong output. expected=%x, got=%x", i, test.expected, output)
+		}
+	}
+}
+
+// TestCompile_concurrent compiles contracts concurrently, which
+// server compiling untrusted sources does. Run with -race.
+func TestCompile_concurrent(t *testing.T) {
+	sources := []string{`
+contract {
+	modifier positive {
+		_
+	}
+	func add(a int, b int) positive int {
+		int sum = a + b
+		return sum
+	}
+}`, `
+pragma erc165
+interface Balance {
+	func balance(owner int) int
+}
+contract implements Balance {
+	const int FEE = 1
+	func balance(owner int) int {
+		return owner - FEE
+	}
+}`,
+	}
+
+	expected := make([][]byte, len(sources))
+	for i, src := range sources {
+		asm, _, err := Compile(src)
+		if err != nil {
+			t.Fatalf("test[%d] - Compile() returns unexpected error: %s", i, err)
+		}
+		expected[i] = asm.ToRawByteCode()
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		for i, src := range sources {
+			wg.Add(1)
+			go func(i int, src string) {
+				defer wg.Done()
+
+				asm, _, err := Compile(src)
+				if err != nil {
+					t.Errorf("test[%d] - Compile() returns unexpected error: %s", i, err)
+					return
+				}
+				if !bytes.Equal(asm.ToRawByteCode(), expected[i]) {
+					t.Errorf("test[%d] - Compile() differs when compiled concurrently", i)
+				}
+			}(i, src)
+		}
+	}
+	wg.Wait()
+}
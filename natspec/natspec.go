@@ -0,0 +1,255 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package natspec extracts the documentation of functions from the
+// comments right above them. Lines of comment are tagged as
+//
+//	// @notice Transfers amount to the receiver
+//	// @param to receiver of amount
+//	// @param amount amount to transfer
+//	// @return whether amount is transferred
+//	func transfer(to address, amount int) bool {
+//
+// Lines without tag continue the tag above, or are the notice when no
+// tag is above them. Block comments are tagged in the same way.
+//
+// Comment right above contract documents the contract itself, with
+// @title, @author and @license tags besides @notice.
+package natspec
+
+import (
+	"strings"
+
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/parse"
+)
+
+// Doc is the documentation of function or contract
+type Doc struct {
+	Title   string            `json:",omitempty"`
+	Author  string            `json:",omitempty"`
+	License string            `json:",omitempty"`
+	Notice  string            `json:",omitempty"`
+	Params  map[string]string `json:",omitempty"`
+	Return  string            `json:",omitempty"`
+}
+
+// UserDoc is the documentation for users of contract, which
+// explorers and wallets show to them. Methods are keyed by
+// signatures of functions.
+type UserDoc struct {
+	Notice  string                   `json:"notice,omitempty"`
+	Methods map[string]MethodUserDoc `json:"methods"`
+}
+
+type MethodUserDoc struct {
+	Notice string `json:"notice"`
+}
+
+// DevDoc is the documentation for developers of contract. Methods
+// are keyed by signatures of functions.
+type DevDoc struct {
+	Title   string                  `json:"title,omitempty"`
+	Author  string                  `json:"author,omitempty"`
+	License string                  `json:"license,omitempty"`
+	Methods map[string]MethodDevDoc `json:"methods"`
+}
+
+type MethodDevDoc struct {
+	Params map[string]string `json:"params,omitempty"`
+	Return string            `json:"return,omitempty"`
+}
+
+// comment is comment of source with the lines it starts and ends
+type comment struct {
+	text  string
+	start int
+	end   int
+}
+
+// Extract returns the documentation of functions in src by their
+// names. Functions without comment right above them are not in the
+// result.
+func Extract(src string) map[string]Doc {
+	tokens, comments := lex(src)
+
+	docs := make(map[string]Doc)
+	prev := parse.Token{}
+	for _, tok := range tokens {
+		if prev.Type == parse.Function && tok.Type == parse.Ident {
+			if text, ok := commentAbove(comments, prev.Line); ok {
+				docs[tok.Val] = parseDoc(text)
+			}
+		}
+		prev = tok
+	}
+	return docs
+}
+
+// Contract returns the documentation of contract in src, which is
+// the comment right above contract keyword
+func Contract(src string) (Doc, bool) {
+	tokens, comments := lex(src)
+	for _, tok := range tokens {
+		if tok.Type != parse.Contract {
+			continue
+		}
+
+		text, ok := commentAbove(comments, tok.Line)
+		if !ok {
+			return Doc{}, false
+		}
+		return parseDoc(text), true
+	}
+	return Doc{}, false
+}
+
+// Build returns the user and developer documentation of contract
+// parsed from src
+func Build(src string, contract *ast.Contract) (UserDoc, DevDoc) {
+	user := UserDoc{Methods: map[string]MethodUserDoc{}}
+	dev := DevDoc{Methods: map[string]MethodDevDoc{}}
+
+	if doc, ok := Contract(src); ok {
+		user.Notice = doc.Notice
+		dev.Title = doc.Title
+		dev.Author = doc.Author
+		dev.License = doc.License
+	}
+
+	docs := Extract(src)
+	for _, fn := range contract.Functions {
+		doc, ok := docs[fn.Name.Name]
+		if !ok {
+			continue
+		}
+
+		if doc.Notice != "" {
+			user.Methods[fn.Signature()] = MethodUserDoc{Notice: doc.Notice}
+		}
+		if len(doc.Params) != 0 || doc.Return != "" {
+			dev.Methods[fn.Signature()] = MethodDevDoc{Params: doc.Params, Return: doc.Return}
+		}
+	}
+
+	return user, dev
+}
+
+// commentAbove returns the text of consecutive comments which end at
+// the line right above line
+func commentAbove(comments []comment, line int) (string, bool) {
+	texts := []string{}
+	for i := len(comments) - 1; i >= 0; i-- {
+		c := comments[i]
+		if c.end != line-1 {
+			if c.end < line-1 {
+				break
+			}
+			continue
+		}
+		texts = append([]string{c.text}, texts...)
+		line = c.start
+	}
+
+	if len(texts) == 0 {
+		return "", false
+	}
+	return strings.Join(texts, "\n"), true
+}
+
+// lex returns the tokens of src except comments, and the comments
+// which lexer emits among them in order. Lines are 0-based as
+// parse.Token has.
+func lex(src string) ([]parse.Token, []comment) {
+	tokens := []parse.Token{}
+	comments := []comment{}
+	l := parse.NewCommentLexer(src)
+	for {
+		tok := l.NextToken()
+		if tok.Type == parse.Eof || tok == (parse.Token{}) {
+			return tokens, comments
+		}
+
+		if tok.Type != parse.Comment {
+			tokens = append(tokens, tok)
+			continue
+		}
+
+		// token is at the line comment ends, block comment starts
+		// the lines it has above
+		text := strings.TrimPrefix(tok.Val, "//")
+		if strings.HasPrefix(tok.Val, "/*") {
+			text = strings.TrimSuffix(strings.TrimPrefix(tok.Val, "/*"), "*/")
+		}
+		start := tok.Line - strings.Count(tok.Val, "\n")
+		comments = append(comments, comment{text: text, start: start, end: tok.Line})
+	}
+}
+
+// parseDoc parses tagged lines of comment text
+func parseDoc(text string) Doc {
+	doc := Doc{}
+	tag, param := "@notice", ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "/*"))
+		if line == "" {
+			continue
+		}
+
+		switch word, rest := splitWord(line); word {
+		case "@title", "@author", "@license", "@notice", "@return":
+			tag, line = word, rest
+		case "@param":
+			tag = word
+			param, line = splitWord(rest)
+			if doc.Params == nil {
+				doc.Params = make(map[string]string)
+			}
+		}
+
+		switch tag {
+		case "@title":
+			doc.Title = joinLine(doc.Title, line)
+		case "@author":
+			doc.Author = joinLine(doc.Author, line)
+		case "@license":
+			doc.License = joinLine(doc.License, line)
+		case "@notice":
+			doc.Notice = joinLine(doc.Notice, line)
+		case "@return":
+			doc.Return = joinLine(doc.Return, line)
+		case "@param":
+			doc.Params[param] = joinLine(doc.Params[param], line)
+		}
+	}
+	return doc
+}
+
+// splitWord splits the first word of s from the rest of it
+func splitWord(s string) (string, string) {
+	i := strings.IndexAny(s, " \t")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i+1:])
+}
+
+func joinLine(text string, line string) string {
+	if text == "" || line == "" {
+		return text + line
+	}
+	return text + " " + line
+}
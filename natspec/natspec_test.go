@@ -0,0 +1,178 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package natspec_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DE-labtory/koa/natspec"
+	"github.com/DE-labtory/koa/parse"
+)
+
+func TestExtract(t *testing.T) {
+	input := `
+contract {
+	// @notice Transfers amount to the receiver,
+	// failing when balance is not enough
+	// @param to receiver of amount
+	// @param amount amount to transfer
+	// @return whether amount is transferred
+	func transfer(to address, amount int) bool {
+		string s = "// not comment"
+		return true
+	}
+
+	/**
+	 * Returns the total supply
+	 * @return total supply
+	 */
+	func total() int {
+		return 0
+	}
+
+	// comment separated by blank line
+
+	func undocumented() int {
+		return 0 // trailing comment
+	}
+	func next() int {
+		return 1
+	}
+}`
+
+	expected := map[string]natspec.Doc{
+		"transfer": {
+			Notice: "Transfers amount to the receiver, failing when balance is not enough",
+			Params: map[string]string{
+				"to":     "receiver of amount",
+				"amount": "amount to transfer",
+			},
+			Return: "whether amount is transferred",
+		},
+		"total": {
+			Notice: "Returns the total supply",
+			Return: "total supply",
+		},
+	}
+
+	if docs := natspec.Extract(input); !reflect.DeepEqual(docs, expected) {
+		t.Errorf("Extract() wrong result.\nexpected=%+v\ngot=%+v", expected, docs)
+	}
+}
+
+func TestContract(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected natspec.Doc
+		ok       bool
+	}{
+		{
+			input: `
+import "math.koa"
+
+// @title Token
+// @author De-labtory
+// @license Apache-2.0
+// @notice Keeps balances of accounts,
+// which can transfer them
+contract {
+	// @notice not contract doc
+	func foo() {
+	}
+}`,
+			expected: natspec.Doc{
+				Title:   "Token",
+				Author:  "De-labtory",
+				License: "Apache-2.0",
+				Notice:  "Keeps balances of accounts, which can transfer them",
+			},
+			ok: true,
+		},
+		{
+			input: `
+// separated by blank line
+
+contract {
+}`,
+		},
+	}
+
+	for i, tt := range tests {
+		doc, ok := natspec.Contract(tt.input)
+		if ok != tt.ok || !reflect.DeepEqual(doc, tt.expected) {
+			t.Errorf("test[%d] - Contract() wrong result.\nexpected=%+v, %t\ngot=%+v, %t", i, tt.expected, tt.ok, doc, ok)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	input := `
+/**
+ * @title Token
+ * @author De-labtory
+ * @notice Keeps balances of accounts
+ */
+contract {
+	// @notice Transfers amount to the receiver
+	// @param to receiver of amount
+	// @return whether amount is transferred
+	func transfer(to address, amount int) bool {
+		return true
+	}
+
+	// @return total supply
+	func total() int {
+		return 0
+	}
+
+	func undocumented() {
+	}
+}`
+
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, dev := natspec.Build(input, contract)
+
+	expectedUser := natspec.UserDoc{
+		Notice: "Keeps balances of accounts",
+		Methods: map[string]natspec.MethodUserDoc{
+			"transfer(address,int)": {Notice: "Transfers amount to the receiver"},
+		},
+	}
+	if !reflect.DeepEqual(user, expectedUser) {
+		t.Errorf("Build() wrong user doc.\nexpected=%+v\ngot=%+v", expectedUser, user)
+	}
+
+	expectedDev := natspec.DevDoc{
+		Title:  "Token",
+		Author: "De-labtory",
+		Methods: map[string]natspec.MethodDevDoc{
+			"transfer(address,int)": {
+				Params: map[string]string{"to": "receiver of amount"},
+				Return: "whether amount is transferred",
+			},
+			"total()": {Return: "total supply"},
+		},
+	}
+	if !reflect.DeepEqual(dev, expectedDev) {
+		t.Errorf("Build() wrong dev doc.\nexpected=%+v\ngot=%+v", expectedDev, dev)
+	}
+}
@@ -92,6 +92,26 @@ const (
 	//
 	Or Type = 0x07
 
+	// Pop the first two items in the stack.
+	// Divide popped two items as unsigned integers and push to the stack.
+	//
+	// Ex)
+	// [a]
+	// [b]  ==> [a/b]
+	// [x]      [x]
+	//
+	UDiv Type = 0x08
+
+	// Pop the first two items in the stack.
+	// Mod popped two items as unsigned integers and push to the stack.
+	//
+	// Ex)
+	// [a]
+	// [b]  ==> [a%b]
+	// [x]      [x]
+	//
+	UMod Type = 0x09
+
 	// Pop the first two items in the stack.
 	// Check if the left operand(first popped item) is less than the right operand(second popped item).
 	// If it is true, push true to the stack. If not push false to the stack.
@@ -167,6 +187,46 @@ const (
 	//
 	Minus Type = 0x16
 
+	// Pop the first two items in the stack.
+	// Same as LT, but compares popped items as unsigned integers.
+	//
+	// Ex)
+	// [a]
+	// [b]  ==> [a<b]
+	// [x]      [x]
+	//
+	ULT Type = 0x17
+
+	// Pop the first two items in the stack.
+	// Same as LTE, but compares popped items as unsigned integers.
+	//
+	// Ex)
+	// [a]
+	// [b]  ==> [a<=b]
+	// [x]      [x]
+	//
+	ULTE Type = 0x18
+
+	// Pop the first two items in the stack.
+	// Same as GT, but compares popped items as unsigned integers.
+	//
+	// Ex)
+	// [a]
+	// [b]  ==> [a>b]
+	// [x]      [x]
+	//
+	UGT Type = 0x19
+
+	// Pop the first two items in the stack.
+	// Same as GTE, but compares popped items as unsigned integers.
+	//
+	// Ex)
+	// [a]
+	// [b]  ==> [a>=b]
+	// [x]      [x]
+	//
+	UGTE Type = 0x1a
+
 	// Pop the first item in the stack.
 	//
 	// Ex)
@@ -277,69 +337,164 @@ const (
 
 	// Jump to last position (Terminate the contract)
 	Exit Type = 0x33
+
+	// Push chain id of the environment which contract is executed in.
+	//
+	// Ex)
+	//           [chain id]
+	// [x]  ==>  [x]
+	// [y]       [y]
+	ChainID Type = 0x34
+
+	// Abort the contract with the reason at the top of the stack.
+	//
+	// Ex)
+	// [reason]
+	// [x]       ==>  (aborted)
+	Revert Type = 0x35
+
+	// Push timestamp of the block which contract is executed in.
+	//
+	// Ex)
+	//           [timestamp]
+	// [x]  ==>  [x]
+	Timestamp Type = 0x36
+
+	// Push number of the block which contract is executed in.
+	//
+	// Ex)
+	//           [number]
+	// [x]  ==>  [x]
+	Number Type = 0x37
+
+	// Pop the first item in the stack.
+	// Format it as decimal string and push it to the stack. String
+	// is quoted in the word, so it has at most 6 characters, and
+	// larger number fails with conversion error.
+	//
+	// Ex)
+	// [a]       [itoa(a)]
+	// [x]  ==>  [x]
+	Itoa Type = 0x38
+
+	// Pop the first item in the stack.
+	// Parse it as decimal string and push the integer to the stack.
+	//
+	// Ex)
+	// [s]       [atoi(s)]
+	// [x]  ==>  [x]
+	Atoi Type = 0x39
+
+	// Pop the first item in the stack.
+	// Format it as hexadecimal string and push it to the stack. Like
+	// Itoa, string has at most 6 characters.
+	//
+	// Ex)
+	// [a]       [hex(a)]
+	// [x]  ==>  [x]
+	Hex Type = 0x3a
+
+	// Abort the contract with custom error. Pop the number of arguments,
+	// the selector of error and the arguments, then revert with the
+	// selector followed by the arguments encoded as abi.
+	//
+	// Ex)
+	// [n]
+	// [selector]
+	// [arg1]
+	// ...
+	// [argn]
+	// [x]         ==>  (aborted)
+	Raise Type = 0x3b
+
+	// Push address of the caller of contract. Address doesn't fit in an
+	// item, so it is pushed as three words: first 8 bytes, next 8 bytes
+	// and last 4 bytes.
+	//
+	// Ex)
+	//           [caller[16:20]]
+	//           [caller[8:16]]
+	//           [caller[0:8]]
+	// [x]  ==>  [x]
+	Caller Type = 0x3c
+
+	// Pop the first item in the stack.
+	// Push the address argument of the index as three words like Caller.
+	//
+	// Ex)
+	//                  [arg[16:20]]
+	//                  [arg[8:16]]
+	// [index]          [arg[0:8]]
+	// [x]        ==>   [x]
+	LoadAddress Type = 0x3d
+
+	// Pop two addresses of three words in the stack.
+	// If they are same, push 1, otherwise push 0.
+	//
+	// Ex)
+	// [b[16:20]]
+	// [b[8:16]]
+	// [b[0:8]]
+	// [a[16:20]]
+	// [a[8:16]]
+	// [a[0:8]]        [a == b]
+	// [x]        ==>  [x]
+	AddressEQ Type = 0x3e
+
+	// Pop index and bytes in the stack. Bytes are kept in an item
+	// left-aligned with their length in the last byte.
+	// Push the byte at the index as integer.
+	//
+	// Ex)
+	// [index]
+	// [b]        [b[index]]
+	// [x]   ==>  [x]
+	ByteAt Type = 0x3f
+
+	// Pop bytes in the stack and push their length.
+	//
+	// Ex)
+	// [b]        [len(b)]
+	// [x]   ==>  [x]
+	Len Type = 0x40
+
+	// Pop the first item in the stack.
+	// Push the bytes argument of the index kept in an item like ByteAt.
+	//
+	// Ex)
+	// [index]        [arg]
+	// [x]       ==>  [x]
+	LoadBytes Type = 0x41
+
+	// Pop the number of values n and the values. Jump to position at
+	// which function was called like Returning, with all of the values.
+	//
+	// Ex)
+	// [n]
+	// [value n]
+	// ...
+	// [value 1]        [value n]
+	// [funcSel]        ...
+	// [position]  ==>  [value 1]
+	// [y]              [y]
+	Returns Type = 0x42
 )
 
 // Change the bytecode of an opcode to string.
 func (p Type) String() (string, error) {
-	switch p {
-	case 0x01:
-		return "Add", nil
-	case 0x02:
-		return "Mul", nil
-	case 0x03:
-		return "Sub", nil
-	case 0x04:
-		return "Div", nil
-	case 0x05:
-		return "Mod", nil
-	case 0x06:
-		return "And", nil
-	case 0x07:
-		return "Or", nil
-	case 0x10:
-		return "LT", nil
-	case 0x11:
-		return "LTE", nil
-	case 0x12:
-		return "GT", nil
-	case 0x13:
-		return "GTE", nil
-	case 0x14:
-		return "EQ", nil
-	case 0x15:
-		return "NOT", nil
-	case 0x16:
-		return "Minus", nil
-	case 0x20:
-		return "Pop", nil
-	case 0x21:
-		return "Push", nil
-	case 0x22:
-		return "Mload", nil
-	case 0x23:
-		return "Mstore", nil
-	case 0x24:
-		return "Msize", nil
-	case 0x25:
-		return "LoadFunc", nil
-	case 0x26:
-		return "LoadArgs", nil
-	case 0x27:
-		return "Returning", nil
-	case 0x28:
-		return "Jump", nil
-	case 0x29:
-		return "JumpDst", nil
-	case 0x30:
-		return "Jumpi", nil
-	case 0x31:
-		return "DUP", nil
-	case 0x32:
-		return "SWAP", nil
-	case 0x33:
-		return "Exit", nil
-
-	default:
+	spec, ok := SpecOf(p)
+	if !ok {
 		return "", errors.New("String() error - Not defined opcode")
 	}
+	return spec.Name, nil
+}
+
+// Lookup returns opcode type of which String() is name
+func Lookup(name string) (Type, error) {
+	for _, spec := range specs {
+		if spec.Name == name {
+			return spec.Type, nil
+		}
+	}
+	return 0, errors.New("Lookup() error - Not defined opcode " + name)
 }
@@ -159,3 +159,28 @@ func TestType_String(t *testing.T) {
 		}
 	}
 }
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    opcode.Type
+		expectedErr bool
+	}{
+		{"Add", opcode.Add, false},
+		{"Push", opcode.Push, false},
+		{"JumpDst", opcode.JumpDst, false},
+		{"Exit", opcode.Exit, false},
+		{"push", 0, true},
+		{"Unknown", 0, true},
+	}
+
+	for i, test := range tests {
+		op, err := opcode.Lookup(test.input)
+		if test.expectedErr != (err != nil) {
+			t.Errorf("test[%d] - Lookup() wrong error. expected error=%v, got=%v", i, test.expectedErr, err)
+		}
+		if op != test.expected {
+			t.Errorf("test[%d] - Lookup() wrong result. expected=%x, got=%x", i, test.expected, op)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// gendoc writes the opcode reference document generated from the opcode table.
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/DE-labtory/koa/opcode"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: gendoc <output>")
+	}
+
+	if err := ioutil.WriteFile(os.Args[1], []byte(opcode.Reference()), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
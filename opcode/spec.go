@@ -0,0 +1,150 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opcode
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Spec describes an opcode. It is the single source of the opcode's
+// name, operand and stack effect; the vm dispatch, the vm stack check,
+// the assembler and the reference document are all derived from it.
+type Spec struct {
+	Type Type
+	Name string
+
+	// Operand is the size in bytes of the immediate operand which
+	// follows the opcode in the bytecode
+	Operand int
+
+	// Pops is the number of stack items the opcode consumes and
+	// Pushes is the number of stack items it produces
+	Pops   int
+	Pushes int
+
+	Description string
+}
+
+//go:generate go run ./internal/gendoc ../docs/opcodes.md
+
+var specs = []Spec{
+	// 0x0 range
+	{Type: Add, Name: "Add", Pops: 2, Pushes: 1, Description: "a + b"},
+	{Type: Mul, Name: "Mul", Pops: 2, Pushes: 1, Description: "a * b"},
+	{Type: Sub, Name: "Sub", Pops: 2, Pushes: 1, Description: "a - b"},
+	{Type: Div, Name: "Div", Pops: 2, Pushes: 1, Description: "a / b"},
+	{Type: Mod, Name: "Mod", Pops: 2, Pushes: 1, Description: "a % b"},
+	{Type: And, Name: "And", Pops: 2, Pushes: 1, Description: "a & b"},
+	{Type: Or, Name: "Or", Pops: 2, Pushes: 1, Description: "a | b"},
+	{Type: UDiv, Name: "UDiv", Pops: 2, Pushes: 1, Description: "unsigned a / b"},
+	{Type: UMod, Name: "UMod", Pops: 2, Pushes: 1, Description: "unsigned a % b"},
+
+	// 0x10 range
+	{Type: LT, Name: "LT", Pops: 2, Pushes: 1, Description: "a < b"},
+	{Type: LTE, Name: "LTE", Pops: 2, Pushes: 1, Description: "a <= b"},
+	{Type: GT, Name: "GT", Pops: 2, Pushes: 1, Description: "a > b"},
+	{Type: GTE, Name: "GTE", Pops: 2, Pushes: 1, Description: "a >= b"},
+	{Type: EQ, Name: "EQ", Pops: 2, Pushes: 1, Description: "a == b"},
+	{Type: NOT, Name: "NOT", Pops: 1, Pushes: 1, Description: "logical not of a"},
+	{Type: Minus, Name: "Minus", Pops: 1, Pushes: 1, Description: "-a"},
+	{Type: ULT, Name: "ULT", Pops: 2, Pushes: 1, Description: "unsigned a < b"},
+	{Type: ULTE, Name: "ULTE", Pops: 2, Pushes: 1, Description: "unsigned a <= b"},
+	{Type: UGT, Name: "UGT", Pops: 2, Pushes: 1, Description: "unsigned a > b"},
+	{Type: UGTE, Name: "UGTE", Pops: 2, Pushes: 1, Description: "unsigned a >= b"},
+
+	// 0x20 range
+	{Type: Pop, Name: "Pop", Pops: 1, Pushes: 0, Description: "discard a"},
+	{Type: Push, Name: "Push", Operand: 8, Pops: 0, Pushes: 1, Description: "push the operand"},
+	{Type: Mload, Name: "Mload", Pops: 2, Pushes: 1, Description: "memory[offset:offset+size]"},
+	{Type: Mstore, Name: "Mstore", Pops: 3, Pushes: 0, Description: "memory[offset:offset+size] = value"},
+	{Type: Msize, Name: "Msize", Pops: 1, Pushes: 0, Description: "resize memory to size"},
+	{Type: LoadFunc, Name: "LoadFunc", Pops: 0, Pushes: 1, Description: "function selector of the call"},
+	{Type: LoadArgs, Name: "LoadArgs", Pops: 1, Pushes: 1, Description: "argument at index of the call"},
+	{Type: Returning, Name: "Returning", Pops: 3, Pushes: 1, Description: "jump back to position with value"},
+	{Type: Jump, Name: "Jump", Pops: 1, Pushes: 0, Description: "jump to position"},
+	{Type: JumpDst, Name: "JumpDst", Pops: 0, Pushes: 0, Description: "jump destination"},
+
+	// 0x30 range
+	{Type: Jumpi, Name: "Jumpi", Pops: 2, Pushes: 0, Description: "jump to position if cond is false"},
+	{Type: DUP, Name: "DUP", Pops: 1, Pushes: 2, Description: "duplicate a"},
+	{Type: SWAP, Name: "SWAP", Pops: 2, Pushes: 2, Description: "swap a and b"},
+	{Type: Exit, Name: "Exit", Pops: 0, Pushes: 0, Description: "terminate the contract"},
+	{Type: ChainID, Name: "ChainID", Pops: 0, Pushes: 1, Description: "chain id of the call"},
+	{Type: Revert, Name: "Revert", Pops: 1, Pushes: 0, Description: "abort with reason a"},
+	{Type: Timestamp, Name: "Timestamp", Pops: 0, Pushes: 1, Description: "timestamp of the block"},
+	{Type: Number, Name: "Number", Pops: 0, Pushes: 1, Description: "number of the block"},
+	{Type: Itoa, Name: "Itoa", Pops: 1, Pushes: 1, Description: "decimal string of a, at most 6 characters"},
+	{Type: Atoi, Name: "Atoi", Pops: 1, Pushes: 1, Description: "integer of decimal string s"},
+	{Type: Hex, Name: "Hex", Pops: 1, Pushes: 1, Description: "hexadecimal string of a, at most 6 characters"},
+	{Type: Raise, Name: "Raise", Pops: 2, Pushes: 0, Description: "abort with error selector and n arguments"},
+	{Type: Caller, Name: "Caller", Pops: 0, Pushes: 3, Description: "address of the caller"},
+	{Type: LoadAddress, Name: "LoadAddress", Pops: 1, Pushes: 3, Description: "address argument of index a"},
+	{Type: AddressEQ, Name: "AddressEQ", Pops: 6, Pushes: 1, Description: "1 if address a equals b, otherwise 0"},
+	{Type: ByteAt, Name: "ByteAt", Pops: 2, Pushes: 1, Description: "byte of bytes b at index a"},
+	{Type: Len, Name: "Len", Pops: 1, Pushes: 1, Description: "length of bytes a"},
+	{Type: LoadBytes, Name: "LoadBytes", Pops: 1, Pushes: 1, Description: "bytes argument of index a"},
+	{Type: Returns, Name: "Returns", Pops: 3, Pushes: 0, Description: "return n values to the position"},
+}
+
+// Specs returns the specifications of all opcodes in bytecode order
+func Specs() []Spec {
+	s := make([]Spec, len(specs))
+	copy(s, specs)
+	return s
+}
+
+// specIndex has the specification of each opcode at its byte, so
+// that vm looks up the spec of every executed instruction without
+// searching specs
+var specIndex [256]*Spec
+
+func init() {
+	for i := range specs {
+		specIndex[specs[i].Type] = &specs[i]
+	}
+}
+
+// SpecOf returns the specification of opcode p
+func SpecOf(p Type) (Spec, bool) {
+	spec := specIndex[p]
+	if spec == nil {
+		return Spec{}, false
+	}
+	return *spec, true
+}
+
+// Reference renders the opcode table as markdown document.
+// docs/opcodes.md is generated from it with go generate.
+func Reference() string {
+	var out bytes.Buffer
+
+	out.WriteString("# Opcodes\n\n")
+	out.WriteString("<!-- Code generated by go generate ./opcode; DO NOT EDIT. -->\n\n")
+	out.WriteString("| Opcode | Name | Operand | Pops | Pushes | Description |\n")
+	out.WriteString("|--------|------|---------|------|--------|-------------|\n")
+	for _, spec := range specs {
+		operand := "-"
+		if spec.Operand > 0 {
+			operand = fmt.Sprintf("%d bytes", spec.Operand)
+		}
+		fmt.Fprintf(&out, "| 0x%02x | %s | %s | %d | %d | %s |\n",
+			uint8(spec.Type), spec.Name, operand, spec.Pops, spec.Pushes, spec.Description)
+	}
+
+	return out.String()
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opcode_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/DE-labtory/koa/opcode"
+)
+
+func TestSpecs(t *testing.T) {
+	seen := make(map[opcode.Type]bool)
+	for _, spec := range opcode.Specs() {
+		if seen[spec.Type] {
+			t.Errorf("opcode %s is defined twice", spec.Name)
+		}
+		seen[spec.Type] = true
+
+		if spec.Operand < 0 || spec.Pops < 0 || spec.Pushes < 0 {
+			t.Errorf("opcode %s has negative size", spec.Name)
+		}
+	}
+}
+
+func TestSpecOf(t *testing.T) {
+	spec, ok := opcode.SpecOf(opcode.Push)
+	if !ok {
+		t.Fatal("SpecOf() can't find Push")
+	}
+	if spec.Name != "Push" || spec.Operand != 8 || spec.Pops != 0 || spec.Pushes != 1 {
+		t.Errorf("SpecOf() wrong result. got=%+v", spec)
+	}
+
+	if _, ok := opcode.SpecOf(0x97); ok {
+		t.Error("SpecOf() should not find undefined opcode")
+	}
+
+	for _, want := range opcode.Specs() {
+		if got, ok := opcode.SpecOf(want.Type); !ok || got != want {
+			t.Errorf("SpecOf() wrong result of %s. expected=%+v, got=%+v", want.Name, want, got)
+		}
+	}
+}
+
+func TestReference(t *testing.T) {
+	doc, err := ioutil.ReadFile("../docs/opcodes.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(doc) != opcode.Reference() {
+		t.Error("docs/opcodes.md is out of date, run go generate ./opcode")
+	}
+}
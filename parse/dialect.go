@@ -0,0 +1,56 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse
+
+// Dialect changes the keywords which lexer recognizes, so that
+// embedders can experiment with variants of koa without forking
+// lexer. Zero value of Dialect is the standard koa.
+type Dialect struct {
+	// Keywords adds alternative spellings of keywords, e.g.
+	//
+	//	Keywords: map[string]TokenType{"function": Function}
+	//
+	// standard spelling is still recognized unless it is disabled
+	Keywords map[string]TokenType
+
+	// Disabled keywords are lexed as identifiers
+	Disabled []string
+}
+
+// NewLexer returns lexer which lexes input in the dialect
+func (d Dialect) NewLexer(input string) *Lexer {
+	return newLexer(input, d.keywords())
+}
+
+// keywords returns keyword table of the dialect
+func (d Dialect) keywords() map[string]TokenType {
+	if len(d.Keywords) == 0 && len(d.Disabled) == 0 {
+		return keywords
+	}
+
+	table := make(map[string]TokenType, len(keywords)+len(d.Keywords))
+	for k, t := range keywords {
+		table[k] = t
+	}
+	for k, t := range d.Keywords {
+		table[k] = t
+	}
+	for _, k := range d.Disabled {
+		delete(table, k)
+	}
+	return table
+}
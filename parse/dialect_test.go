@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DE-labtory/koa/parse"
+)
+
+func TestDialect_NewLexer(t *testing.T) {
+	d := parse.Dialect{
+		Keywords: map[string]parse.TokenType{
+			"function": parse.Function,
+			"unless":   parse.If,
+		},
+		Disabled: []string{"func", "enum"},
+	}
+
+	tests := []lexTestCase{
+		{parse.Function, "function"},
+		{parse.Ident, "func"},
+		{parse.Ident, "enum"},
+		{parse.If, "if"},
+		{parse.If, "unless"},
+	}
+
+	l := d.NewLexer("function func enum if unless")
+	for i, test := range tests {
+		compareToken(t, i, l.NextToken(), test)
+	}
+
+	// standard lexer is not affected by dialect
+	l = parse.NewLexer("function func")
+	compareToken(t, 0, l.NextToken(), lexTestCase{parse.Ident, "function"})
+	compareToken(t, 1, l.NextToken(), lexTestCase{parse.Function, "func"})
+}
+
+func TestDialect_parseImports(t *testing.T) {
+	d := parse.Dialect{
+		Keywords: map[string]parse.TokenType{"function": parse.Function},
+		Disabled: []string{"func"},
+	}
+	resolver := mapResolver{
+		"math.koa": `
+function double(a int) int {
+	return a * 2
+}`,
+	}
+
+	input := `
+import "math.koa"
+
+contract {
+	function quad(a int) int {
+		return double(double(a))
+	}
+}`
+
+	contract, err := parse.ParseImports(context.Background(),
+		parse.NewTokenBuffer(d.NewLexer(input)), parse.Limits{}, resolver)
+	if err != nil {
+		t.Fatalf("ParseImports() returns unexpected error: %s", err)
+	}
+
+	expected := `
+contract {
+func double(Parameter : (Identifier: a, Type: int)) int {
+return (a * 2)
+}
+func quad(Parameter : (Identifier: a, Type: int)) int {
+return function double( function double( a ) )
+}
+}`
+	if result := contract.String(); result != expected {
+		t.Errorf("ParseImports() wrong result.\nexpected=%s\ngot=%s", expected, result)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/DE-labtory/koa/ast"
+)
+
+// FileError happens while parsing the contract in file, it wraps
+// the error of parser with the name of file
+type FileError struct {
+	Filename string
+	Err      error
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Filename, e.Err)
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// ParseFile parses the contract in the file of path. Libraries
+// which it imports are read relative to the directory of the file.
+func ParseFile(path string) (*ast.Contract, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseReader(f, path)
+}
+
+// ParseReader parses the contract read from r, errors of parser and
+// positions of nodes are annotated with filename. Libraries which it imports are read
+// relative to the directory of filename.
+func ParseReader(r io.Reader, filename string) (*ast.Contract, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := parseFile(context.Background(),
+		NewTokenBuffer(NewLexer(string(src))), Limits{}, DirResolver(filepath.Dir(filename)), filename)
+	if err != nil {
+		return nil, FileError{filename, err}
+	}
+	return contract, nil
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/parse"
+)
+
+func TestParseFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "koa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"lib.koa": `
+const int A = 1
+
+func one() int {
+	return 1
+}`,
+		"main.koa": `
+import "lib.koa"
+
+contract {
+	func foo() int {
+		return A
+	}
+}`,
+		"bad.koa": `
+contract {
+	func foo( {
+}`,
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	contract, err := parse.ParseFile(filepath.Join(dir, "main.koa"))
+	if err != nil {
+		t.Fatalf("ParseFile() returns unexpected error: %s", err)
+	}
+	if len(contract.Constants) != 1 || len(contract.Functions) != 2 {
+		t.Fatalf("ParseFile() wrong result. got=%s", contract.String())
+	}
+
+	// positions of library nodes reference the library
+	expectedPos := []ast.Pos{
+		{File: "lib.koa", Line: 4, Column: 7},
+		{File: filepath.Join(dir, "main.koa"), Line: 5, Column: 8},
+	}
+	for i, fn := range contract.Functions {
+		pos := fn.Body.Statements[0].(*ast.ReturnStatement).Pos
+		if pos != expectedPos[i] {
+			t.Errorf("ParseFile() wrong position of %s. expected=%v, got=%v", fn.Name, expectedPos[i], pos)
+		}
+	}
+
+	path := filepath.Join(dir, "bad.koa")
+	_, err = parse.ParseFile(path)
+
+	expected := "[" + path + "] [line 2, column 12] Expected [IDENT], but got [LBRACE]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("ParseFile() wrong error. expected=%s, got=%v", expected, err)
+	}
+	if !errors.Is(err, parse.ErrSyntax) {
+		t.Errorf("ParseFile() error %v is not syntax error", err)
+	}
+
+	var fileErr parse.FileError
+	if !errors.As(err, &fileErr) || fileErr.Filename != path {
+		t.Errorf("ParseFile() error %v is not file error of %s", err, path)
+	}
+
+	if _, err := parse.ParseFile(filepath.Join(dir, "missing.koa")); err == nil || errors.Is(err, parse.ErrSyntax) {
+		t.Errorf("ParseFile() of missing file wrong error. got=%v", err)
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	contract, err := parse.ParseReader(strings.NewReader(`
+contract {
+	func foo() int {
+		return 1
+	}
+}`), "foo.koa")
+	if err != nil {
+		t.Fatalf("ParseReader() returns unexpected error: %s", err)
+	}
+	if len(contract.Functions) != 1 {
+		t.Errorf("ParseReader() wrong result. got=%s", contract.String())
+	}
+
+	_, err = parse.ParseReader(strings.NewReader(`
+import "lib.koa"
+contract {
+}`), "<stdin>")
+
+	// the rest of message is from the operating system
+	expected := "[<stdin>] [line 1, column 16] [STRING] open lib.koa"
+	if err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("ParseReader() wrong error. expected=%s..., got=%v", expected, err)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse
+
+import "github.com/DE-labtory/koa/ast"
+
+// ParseExpr parses src as a single expression, so that REPL and
+// tooling can parse fragment of source without whole contract
+func ParseExpr(src string) (ast.Expression, error) {
+	p := newParser(Limits{}, nil)
+
+	buf := NewTokenBuffer(NewLexer(src))
+	expr, err := p.parseExpression(buf, LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expectEnd(buf); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// ParseStmt parses src as a single statement in a new scope, in
+// which no variable is declared yet
+func ParseStmt(src string) (ast.Statement, error) {
+	p := newParser(Limits{}, nil)
+
+	buf := NewTokenBuffer(NewLexer(src))
+	stmt, err := p.parseStatement(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expectEnd(buf); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// expectEnd checks that nothing but semicolons is left in buf
+func expectEnd(buf TokenBuffer) error {
+	consumeSemi(buf)
+
+	if tok := buf.Peek(CURRENT); tok.Type != Eof {
+		return ExpectError{tok, Eof}
+	}
+	return nil
+}
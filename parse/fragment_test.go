@@ -0,0 +1,121 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse_test
+
+import (
+	"testing"
+
+	"github.com/DE-labtory/koa/parse"
+)
+
+func TestParseExpr(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input:    "a + b * 2",
+			expected: "(a + (b * 2))",
+		},
+		{
+			input:    "foo(1, x) == true\n",
+			expected: "(function foo( 1, x ) == true)",
+		},
+		{
+			input:    "!(a || b)",
+			expected: "(!(a || b))",
+		},
+		{
+			input:       "a +",
+			expectedErr: "[line 0, column 3] [EOF] prefix parse function not defined",
+		},
+		{
+			input:       "1 2",
+			expectedErr: "[line 0, column 3] Expected [EOF], but got [INT]",
+		},
+	}
+
+	for i, test := range tests {
+		expr, err := parse.ParseExpr(test.input)
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - ParseExpr() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - ParseExpr() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := expr.String(); result != test.expected {
+			t.Errorf("test[%d] - ParseExpr() wrong result. expected=%s, got=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestParseStmt(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input:    "int a = 1",
+			expected: "int a = 1",
+		},
+		{
+			input:    "if (a > 1) { return a }",
+			expected: "if ( (a > 1) ) { return a }",
+		},
+		{
+			input:    "for (int i = 0; i < 10; i++) { foo(i) }",
+			expected: "for ( int i = 0; (i < 10); i = (i + 1) ) { function foo( i ) }",
+		},
+		{
+			input:       "a = 1",
+			expectedErr: "[line 0, column 1] symbol [a] is not exist",
+		},
+		{
+			input:       "a + 1",
+			expectedErr: "[line 0, column 1] [IDENT] expression is not a statement, expected call, assignment, increment or decrement",
+		},
+		{
+			input:       "int a = 1 int b = 2",
+			expectedErr: "[line 0, column 13] Expected [EOF], but got [INT_TYPE]",
+		},
+	}
+
+	for i, test := range tests {
+		stmt, err := parse.ParseStmt(test.input)
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - ParseStmt() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - ParseStmt() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := stmt.String(); result != test.expected {
+			t.Errorf("test[%d] - ParseStmt() wrong result. expected=%s, got=%s", i, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/DE-labtory/koa/ast"
+)
+
+// Library is a source file which contract imports with
+//
+//	import "math.koa"
+//
+// It has imports, interfaces, constants, modifiers and functions
+// without contract block, which are merged to the contract importing
+// it as if they were declared in it. Library imported more than once
+// is merged only once. Import path is slash separated on every platform,
+// so that the same contract compiles on Linux, macOS and Windows.
+
+// Resolver reads the source of imported library
+type Resolver interface {
+	Resolve(path string) (string, error)
+}
+
+// DirResolver reads imported library from the file relative to
+// the directory. Path which is absolute or escapes the directory
+// after cleaning, e.g. "../../etc/x", is rejected.
+type DirResolver string
+
+func (d DirResolver) Resolve(path string) (string, error) {
+	name := filepath.FromSlash(path)
+	if filepath.IsAbs(name) || strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("import path %s must be relative", path)
+	}
+
+	root := filepath.Clean(string(d))
+	file := filepath.Join(root, name)
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("import path %s is outside of %s", path, string(d))
+	}
+
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
+
+// ImportError happens while parsing imported library, Source is
+// the path token of the import directive
+type ImportError struct {
+	Source Token
+	Path   string
+	Err    error
+}
+
+func (e ImportError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Path, e.Err)
+}
+
+func (e ImportError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+func (e ImportError) Unwrap() error {
+	return e.Err
+}
+
+// parseImports parse import directives at the start of file
+func (p *parser) parseImports(ctx context.Context, buf TokenBuffer, contract *ast.Contract) error {
+	for curTokenIs(buf, Import) {
+		if err := p.parseImport(ctx, buf, contract); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseImport parse import directive, and merges declarations of the
+// library to contract. e.g. import "math.koa"
+func (p *parser) parseImport(ctx context.Context, buf TokenBuffer, contract *ast.Contract) error {
+	if err := expectNext(buf, Import); err != nil {
+		return err
+	}
+
+	token := buf.Read()
+	if token.Type != String {
+		return ExpectError{token, String}
+	}
+	consumeSemi(buf)
+
+	name := strings.Trim(token.Val, `"`)
+	if strings.Contains(name, `\`) {
+		return Error{token, fmt.Sprintf("import path %s must be slash separated", name)}
+	}
+
+	path := path.Clean(name)
+	if p.resolver == nil {
+		return Error{token, fmt.Sprintf("cannot import %s without resolver", path)}
+	}
+
+	for i, imp := range p.importing {
+		if imp == path {
+			cycle := append(append([]string{}, p.importing[i:]...), path)
+			return Error{token, fmt.Sprintf("import cycle %s", strings.Join(cycle, " -> "))}
+		}
+	}
+
+	if p.imported[path] {
+		return nil
+	}
+
+	src, err := p.resolver.Resolve(path)
+	if err != nil {
+		return Error{token, err.Error()}
+	}
+
+	p.importing = append(p.importing, path)
+	err = p.parseLibrary(ctx, NewTokenBuffer(lexerOf(buf, src)), contract)
+	p.importing = p.importing[:len(p.importing)-1]
+	if err != nil {
+		return ImportError{token, path, err}
+	}
+
+	p.imported[path] = true
+	return nil
+}
+
+// lexerOf returns lexer of library imported from buf, which lexes
+// library in the same dialect as buf
+func lexerOf(buf TokenBuffer, src string) *Lexer {
+	if b, ok := buf.(*DefaultTokenBuffer); ok {
+		return newLexer(src, b.l.keywords)
+	}
+	return NewLexer(src)
+}
+
+// parseLibrary parse declarations of library to contract
+func (p *parser) parseLibrary(ctx context.Context, buf TokenBuffer, contract *ast.Contract) error {
+	if err := p.parsePragmas(buf, contract); err != nil {
+		return err
+	}
+
+	if err := p.parseImports(ctx, buf, contract); err != nil {
+		return err
+	}
+
+	for !curTokenIs(buf, Eof) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !curTokenIs(buf, Interface) {
+			if err := p.parseDeclaration(buf, contract); err != nil {
+				return err
+			}
+			continue
+		}
+
+		i, err := p.parseInterface(buf, contract.Interfaces)
+		if err != nil {
+			return err
+		}
+		contract.Interfaces = append(contract.Interfaces, i)
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DE-labtory/koa/parse"
+)
+
+// mapResolver resolves imported path to the source in map
+type mapResolver map[string]string
+
+func (m mapResolver) Resolve(path string) (string, error) {
+	src, ok := m[path]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return src, nil
+}
+
+func TestParseImports(t *testing.T) {
+	resolver := mapResolver{
+		"math.koa": `
+import "base.koa"
+
+const int SCALE = 100
+
+func scale(a int) int {
+	return a * SCALE
+}`,
+		"base.koa": `
+modifier positive {
+	require(SCALE > 0, "scale")
+	_
+}`,
+		"token.koa": `
+import "base.koa"
+import "math.koa"
+
+interface Token {
+	func total() int
+}`,
+		"a.koa":   `import "b.koa"`,
+		"b.koa":   `import "a.koa"`,
+		"bad.koa": `func foo( {`,
+		"dup.koa": `const int SCALE = 10`,
+	}
+
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+import "token.koa"
+import "math.koa"
+
+contract implements Token {
+	func total() positive int {
+		return scale(2)
+	}
+}`,
+			expected: `
+interface Token {
+func total() int
+}
+contract implements Token {
+const int SCALE = 100
+modifier positive {
+function require( (SCALE > 0), "scale" )
+_
+}
+func scale(Parameter : (Identifier: a, Type: int)) int {
+return (a * SCALE)
+}
+func total() positive int {
+function require( (SCALE > 0), "scale" )
+return function scale( 2 )
+}
+}`,
+		},
+		{
+			input: `
+import "a.koa"
+contract {
+}`,
+			expectedErr: "[a.koa] [b.koa] [line 0, column 14] [STRING] import cycle a.koa -> b.koa -> a.koa",
+		},
+		{
+			input: `
+import "./math.koa"
+import "lib/../math.koa"
+contract {
+}`,
+			expected: `
+contract {
+const int SCALE = 100
+modifier positive {
+function require( (SCALE > 0), "scale" )
+_
+}
+func scale(Parameter : (Identifier: a, Type: int)) int {
+return (a * SCALE)
+}
+}`,
+		},
+		{
+			input: `
+import "lib\math.koa"
+contract {
+}`,
+			expectedErr: "[line 1, column 21] [STRING] import path lib\\math.koa must be slash separated",
+		},
+		{
+			input: `
+import "missing.koa"
+contract {
+}`,
+			expectedErr: "[line 1, column 20] [STRING] not found",
+		},
+		{
+			input: `
+import "bad.koa"
+contract {
+}`,
+			expectedErr: "[bad.koa] [line 0, column 11] Expected [IDENT], but got [LBRACE]",
+		},
+		{
+			input: `
+import "dup.koa"
+contract {
+	const int SCALE = 100
+}`,
+			expectedErr: "[line 3, column 16] symbol [SCALE] already exist",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.ParseImports(context.Background(),
+			parse.NewTokenBuffer(parse.NewLexer(test.input)), parse.Limits{}, resolver)
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - ParseImports() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			if err != nil && !errors.Is(err, parse.ErrSyntax) {
+				t.Errorf("test[%d] - ParseImports() error %v is not syntax error", i, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - ParseImports() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.String(); result != test.expected {
+			t.Errorf("test[%d] - ParseImports() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestParse_importWithoutResolver(t *testing.T) {
+	_, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(`
+import "math.koa"
+contract {
+}`)))
+
+	expected := "[line 1, column 17] [STRING] cannot import math.koa without resolver"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Parse() wrong error. expected=%s, got=%v", expected, err)
+	}
+}
+
+func TestDirResolver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "koa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "lib.koa"), []byte("const int A = 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := parse.DirResolver(dir).Resolve("lib.koa")
+	if err != nil || src != "const int A = 1" {
+		t.Errorf("Resolve() wrong result. expected=%s, got=%s (%v)", "const int A = 1", src, err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "sub", "lib.koa"), []byte("const int B = 2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err = parse.DirResolver(dir).Resolve("sub/lib.koa")
+	if err != nil || src != "const int B = 2" {
+		t.Errorf("Resolve() wrong result. expected=%s, got=%s (%v)", "const int B = 2", src, err)
+	}
+
+	if _, err := parse.DirResolver(dir).Resolve("missing.koa"); err == nil {
+		t.Errorf("Resolve() of missing file returns no error")
+	}
+
+	src, err = parse.DirResolver(filepath.Join(dir, "sub")).Resolve("../sub/./lib.koa")
+	if err != nil || src != "const int B = 2" {
+		t.Errorf("Resolve() wrong result. expected=%s, got=%s (%v)", "const int B = 2", src, err)
+	}
+
+	tests := []struct {
+		path        string
+		expectedErr string
+	}{
+		{"../lib.koa", "import path ../lib.koa is outside of " + filepath.Join(dir, "sub")},
+		{"../../etc/x", "import path ../../etc/x is outside of " + filepath.Join(dir, "sub")},
+		{"lib/../../lib.koa", "import path lib/../../lib.koa is outside of " + filepath.Join(dir, "sub")},
+		{"/etc/x", "import path /etc/x must be relative"},
+	}
+
+	for i, test := range tests {
+		_, err := parse.DirResolver(filepath.Join(dir, "sub")).Resolve(test.path)
+		if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("test[%d] - Resolve() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+		}
+	}
+}
@@ -28,13 +28,37 @@ type emitter interface {
 }
 
 type Lexer struct {
-	tokench chan Token
+	tokench  chan Token
+	keywords map[string]TokenType
+
+	// comments makes lexer emit comments as Comment tokens
+	comments bool
 }
 
 func NewLexer(input string) *Lexer {
+	return newLexer(input, keywords)
+}
 
+// NewCommentLexer is like NewLexer but also emits comments as Comment
+// tokens, whose Val has the delimiters of comment. Line and Column are
+// where comment ends as other tokens have. Parser doesn't accept
+// comments, they are for the tools reading source such as natspec.
+func NewCommentLexer(input string) *Lexer {
 	l := &Lexer{
-		tokench: make(chan Token, 2),
+		tokench:  make(chan Token, 2),
+		keywords: keywords,
+		comments: true,
+	}
+
+	go l.run(input)
+	return l
+}
+
+func newLexer(input string, keywords map[string]TokenType) *Lexer {
+
+	l := &Lexer{
+		tokench:  make(chan Token, 2),
+		keywords: keywords,
 	}
 
 	go l.run(input)
@@ -45,7 +69,9 @@ func NewLexer(input string) *Lexer {
 func (l *Lexer) run(input string) {
 
 	state := &state{
-		input: input,
+		input:    input,
+		keywords: l.keywords,
+		comments: l.comments,
 	}
 
 	for stateFn := defaultStateFn; stateFn != nil; {
@@ -69,27 +95,49 @@ func (l *Lexer) NextToken() Token {
 // DefaultTokenBuffer is implementation for TokenBuffer interface
 // providing buffers to the client.
 //
-// cur, next work as buffer. Each store token as below
+// ring works as buffer. It stores tokens read from lexer but not yet
+// read by client, starting from the current token at cur
 //
-// ----------------------------------------------
-//    client   <- |  cur  |  next | <-  lexer
-//    ======   <- | token | token | <-  =====
-// ----------------------------------------------
+// ------------------------------------------------------------
+//    client   <- | marked |  cur  |  next | ... | <-  lexer
+//    ======   <- | token  | token | token | ... | <-  =====
+// ------------------------------------------------------------
 //
+// ring grows when client peeks further than it can hold, so that
+// parser can look ahead as many tokens as grammar needs. Tokens already
+// read are kept from head while there are marks, so that parser can
+// reset buffer to the mark.
 type DefaultTokenBuffer struct {
 	l *Lexer
 
-	cur  Token
-	next Token
+	ring []Token
+	head int
+	size int
+
+	// cur is the offset of current token from head, and pos is the
+	// number of tokens read from the start of buffer
+	cur   int
+	pos   int
+	marks []checkpoint
+
+	// last is the last token read except semicolon
+	last Token
+}
+
+// checkpoint is the mark of buffer with the last token read before it,
+// which is restored when buffer is reset to the mark
+type checkpoint struct {
+	pos  int
+	last Token
 }
 
 func NewTokenBuffer(l *Lexer) *DefaultTokenBuffer {
 	buf := &DefaultTokenBuffer{
-		l: l,
+		l:    l,
+		ring: make([]Token, 4),
 	}
 	// read for filling cur, next token
-	buf.Read()
-	buf.Read()
+	buf.fill(NEXT)
 
 	return buf
 }
@@ -97,30 +145,110 @@ func NewTokenBuffer(l *Lexer) *DefaultTokenBuffer {
 // Read returns current token, then read from lexer
 // then change the cur, next token value
 func (b *DefaultTokenBuffer) Read() Token {
-	tok := b.l.NextToken()
-	out := b.cur
+	out := b.ring[(b.head+b.cur)%len(b.ring)]
+
+	if out.Type != Semicolon {
+		b.last = out
+	}
 
-	b.cur = b.next
-	b.next = tok
+	b.cur++
+	b.pos++
+	b.discard()
+	b.fill(b.cur + NEXT)
 
 	return out
 }
 
-// Peek returns token based on the peekNumber, this doesn't
-// change token value
-func (b *DefaultTokenBuffer) Peek(n peekNumber) Token {
-	if !n.isValid() {
+// Peek returns n-th token from the current token, this doesn't
+// change token value. Peek returns empty token when n is negative
+func (b *DefaultTokenBuffer) Peek(n int) Token {
+	if n < 0 {
 		return Token{}
 	}
 
-	switch n {
-	case CURRENT:
-		return b.cur
-	case NEXT:
-		return b.next
+	b.fill(b.cur + n)
+	return b.ring[(b.head+b.cur+n)%len(b.ring)]
+}
+
+// Last returns the last token read except semicolon, which is
+// the end of the node parser has just parsed
+func (b *DefaultTokenBuffer) Last() Token {
+	return b.last
+}
+
+// Mark returns the checkpoint of current token. Tokens read after mark
+// are kept until the mark is reset or released.
+func (b *DefaultTokenBuffer) Mark() int {
+	b.marks = append(b.marks, checkpoint{b.pos, b.last})
+	return b.pos
+}
+
+// Reset rewinds buffer to the mark, so that tokens read after mark
+// are read again, then releases the mark
+func (b *DefaultTokenBuffer) Reset(mark int) {
+	if first := b.pos - b.cur; mark < first || mark > b.pos {
+		return
+	}
+
+	for _, m := range b.marks {
+		if m.pos == mark {
+			b.last = m.last
+		}
+	}
+
+	b.cur -= b.pos - mark
+	b.pos = mark
+	b.Release(mark)
+}
+
+// Release drops the mark without rewinding buffer
+func (b *DefaultTokenBuffer) Release(mark int) {
+	for i := len(b.marks) - 1; i >= 0; i-- {
+		if b.marks[i].pos == mark {
+			b.marks = append(b.marks[:i], b.marks[i+1:]...)
+			break
+		}
+	}
+	b.discard()
+}
+
+// discard drops tokens read before the oldest mark, or every token
+// read if there's no mark
+func (b *DefaultTokenBuffer) discard() {
+	oldest := b.pos
+	for _, m := range b.marks {
+		if m.pos < oldest {
+			oldest = m.pos
+		}
 	}
 
-	return Token{}
+	n := oldest - (b.pos - b.cur)
+	b.head = (b.head + n) % len(b.ring)
+	b.size -= n
+	b.cur -= n
+}
+
+// fill reads from lexer until ring holds n-th token from head,
+// growing ring if it is too small
+func (b *DefaultTokenBuffer) fill(n int) {
+	if n >= len(b.ring) {
+		size := len(b.ring)
+		for n >= size {
+			size *= 2
+		}
+
+		ring := make([]Token, size)
+		for i := 0; i < b.size; i++ {
+			ring[i] = b.ring[(b.head+i)%len(b.ring)]
+		}
+		b.ring = ring
+		b.head = 0
+	}
+
+	for b.size <= n {
+		b.ring[(b.head+b.size)%len(b.ring)] = b.l.NextToken()
+		b.size++
+	}
 }
 
 // The process of generating a token from an input string(codes) is generally implemented
@@ -168,6 +296,26 @@ type state struct {
 	insertSemi bool //if true, insert semicolon
 	column     Pos
 	columnBuf  Pos // save column when '\n' comes
+
+	// keywords is the keyword table of dialect being lexed,
+	// standard keywords are used when it is nil
+	keywords map[string]TokenType
+
+	// comments makes commentStateFn emit comment instead of
+	// skipping it
+	comments bool
+}
+
+// lookupIdent returns token type of ident in the keyword
+// table of state
+func (s *state) lookupIdent(ident string) TokenType {
+	if s.keywords == nil {
+		return LookupIdent(ident)
+	}
+	if tok, ok := s.keywords[ident]; ok {
+		return tok
+	}
+	return Ident
 }
 
 // Pos represents a byte position in the original input text from which
@@ -176,7 +324,7 @@ type Pos int
 
 // Cut return a token and set start position to pos
 func (s *state) cut(t TokenType) Token {
-	token := Token{t, s.input[s.start:s.end], s.column, s.line}
+	token := Token{t, s.input[s.start:s.end], s.column, s.line, s.start}
 	s.start = s.end
 
 	return token
@@ -254,7 +402,8 @@ func (s *state) acceptRun(valid string) {
 //	EQ     // ==
 //	NOT_EQ // !=
 //
-//	Comma // ,
+//	Comma     // ,
+//	Semicolon // ;
 //
 //	Lparen // (
 //	Rparen // )
@@ -357,8 +506,17 @@ func defaultStateFn(s *state, e emitter) stateFn {
 		insertSemi = true
 	case ch == '{':
 		e.emit(s.cut(Lbrace))
+	case ch == ']':
+		e.emit(s.cut(Rbracket))
+		insertSemi = true
+	case ch == '[':
+		e.emit(s.cut(Lbracket))
 	case ch == ',':
 		e.emit(s.cut(Comma))
+	case ch == '.':
+		e.emit(s.cut(Dot))
+	case ch == ';':
+		e.emit(s.cut(Semicolon))
 	case ch == '"':
 		s.backup()
 		return stringStateFn
@@ -393,7 +551,8 @@ func defaultStateFn(s *state, e emitter) stateFn {
 	return defaultStateFn
 }
 
-// commentStateFn scans a comment line or block
+// commentStateFn scans a comment line or block, which is emitted
+// as Comment token only when state keeps comments
 // comment format : // or /**/
 func commentStateFn(s *state, e emitter) stateFn {
 	switch second := s.next(); {
@@ -404,7 +563,6 @@ func commentStateFn(s *state, e emitter) stateFn {
 			}
 			s.next()
 		}
-		s.cut(Illegal)
 	case second == '*':
 		for s.peek() != eof {
 			if s.next() == '*' && s.peek() == '/' {
@@ -412,9 +570,13 @@ func commentStateFn(s *state, e emitter) stateFn {
 				break
 			}
 		}
-		s.cut(Illegal)
 	}
 
+	if s.comments {
+		e.emit(s.cut(Comment))
+	} else {
+		s.cut(Illegal)
+	}
 	return defaultStateFn
 }
 
@@ -428,7 +590,7 @@ func stringStateFn(s *state, e emitter) stateFn {
 	for s.next() != '"' {
 		ch := s.peek()
 		if ch == '\n' || ch == eof {
-			e.emit(Token{Illegal, "String not terminated", s.end, s.line})
+			e.emit(Token{Illegal, "String not terminated", s.end, s.line, s.start})
 			break
 		}
 	}
@@ -437,19 +599,50 @@ func stringStateFn(s *state, e emitter) stateFn {
 	return defaultStateFn
 }
 
-// NumberStateFn scans an alphanumeric. ex) 123, 4001, 232
+// bytesStateFn scans a byte string after its 0x prefix
+// After reading a byte string, it returns defaultStateFn.
+// bytes_literal = "0" ( "x" | "X" ) `"` { hex_digit hex_digit } `"`
+func bytesStateFn(s *state, e emitter) stateFn {
+	s.next() //accept '"'
+	s.acceptRun("0123456789abcdefABCDEF")
+
+	if !s.accept(`"`) {
+		e.emit(Token{Illegal, "Byte string not terminated", s.end, s.line, s.start})
+		return defaultStateFn
+	}
+
+	e.emit(s.cut(Bytes))
+	return defaultStateFn
+}
+
+// NumberStateFn scans an alphanumeric. ex) 123, 4001, 232, 0x1F, 0b1010
 // After reading Number, it returns DefaultStateFn.
-// number = { decimal_digit }
+// Digits can be separated by '_', ex) 1_000_000
+// number = decimal_lit | hex_lit | binary_lit
+// decimal_lit = decimal_digit { [ "_" ] decimal_digit }
+// hex_lit = "0" ( "x" | "X" ) { [ "_" ] hex_digit }
+// binary_lit = "0" ( "b" | "B" ) { [ "_" ] binary_digit }
 func numberStateFn(s *state, e emitter) stateFn {
 	s.insertSemi = true
-	const digits = "0123456789"
+	digits := "0123456789"
 
 	if !s.accept(digits) {
-		e.emit(Token{Illegal, "Invalid function call: numberStateFn", s.end, s.line})
+		e.emit(Token{Illegal, "Invalid function call: numberStateFn", s.end, s.line, s.start})
 		return defaultStateFn
 	}
 
-	for s.accept(digits) {
+	if s.input[s.start:s.end] == "0" {
+		if s.accept("xX") {
+			if s.peek() == '"' {
+				return bytesStateFn
+			}
+			digits = "0123456789abcdefABCDEF"
+		} else if s.accept("bB") {
+			digits = "01"
+		}
+	}
+
+	for s.accept(digits + "_") {
 	}
 
 	e.emit(s.cut(Int))
@@ -466,7 +659,7 @@ func numberStateFn(s *state, e emitter) stateFn {
 func identifierStateFn(s *state, e emitter) stateFn {
 	s.insertSemi = true
 	if !(unicode.IsLetter(s.peek()) || s.peek() == '_') {
-		errToken := Token{Illegal, "Invalid function call: identifierStateFn", s.end, s.line}
+		errToken := Token{Illegal, "Invalid function call: identifierStateFn", s.end, s.line, s.start}
 		e.emit(errToken)
 		return defaultStateFn
 	}
@@ -477,7 +670,7 @@ func identifierStateFn(s *state, e emitter) stateFn {
 	}
 
 	//lookup keywords map and return tokenType
-	tok := LookupIdent(s.input[s.start:s.end])
+	tok := s.lookupIdent(s.input[s.start:s.end])
 	e.emit(s.cut(tok))
 	return defaultStateFn
 }
@@ -489,7 +682,7 @@ func spaceStateFn(s *state, e emitter) stateFn {
 	const spaceChars = " \t\r"
 
 	if !s.accept(spaceChars) {
-		errToken := Token{Illegal, "Invalid function call: spaceStateFn", s.end, s.line}
+		errToken := Token{Illegal, "Invalid function call: spaceStateFn", s.end, s.line, s.start}
 		e.emit(errToken)
 		return defaultStateFn
 	}
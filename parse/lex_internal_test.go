@@ -293,6 +293,7 @@ func TestLex_defaultStateFn(t *testing.T) {
 	//	NOT_EQ // !=
 	//
 	//	Comma // ,
+	//	Dot   // .
 	//
 	//	Lparen // (
 	//	Rparen // )
@@ -313,6 +314,8 @@ func TestLex_defaultStateFn(t *testing.T) {
 		{"==", EQ},
 		{"!=", NOT_EQ},
 		{",", Comma},
+		{".", Dot},
+		{";", Semicolon},
 		{"(", Lparen},
 		{")", Rparen},
 		{"{", Lbrace},
@@ -382,6 +385,20 @@ func TestCommentStateFn(t *testing.T) {
 
 	}
 }
+
+func TestCommentStateFn_comments(t *testing.T) {
+	s := &state{input: "//comment\n", start: 0, end: 1, comments: true}
+	var emitted []Token
+	e := MockEmitter{emitFunc: func(t Token) {
+		emitted = append(emitted, t)
+	}}
+
+	commentStateFn(s, e)
+
+	if len(emitted) != 1 || emitted[0].Type != Comment || emitted[0].Val != "//comment" {
+		t.Errorf("commentStateFn() wrong tokens. expected=[COMMENT, //comment], got=%v", emitted)
+	}
+}
 func TestErrorStringStateFn(t *testing.T) {
 	tests := []struct {
 		input              string
@@ -436,6 +453,14 @@ func TestNumberStateFn(t *testing.T) {
 		{"909", Int, "909"},
 		{"909", Int, "909"},
 		{"012", Int, "012"}, //accept 0122
+		{"1_000", Int, "1_000"},
+		{"0x1F", Int, "0x1F"},
+		{"0XdeadBEEF", Int, "0XdeadBEEF"},
+		{"0xff_ff", Int, "0xff_ff"},
+		{"0b1010", Int, "0b1010"},
+		{"0B1_0", Int, "0B1_0"},
+		{"0b12", Int, "0b1"},
+		{"1x2", Int, "1"},
 		{"_121", Illegal, "Invalid function call: numberStateFn"},
 		{"+-121", Illegal, "Invalid function call: numberStateFn"},
 		{"+_11", Illegal, "Invalid function call: numberStateFn"},
@@ -456,6 +481,43 @@ func TestNumberStateFn(t *testing.T) {
 	}
 }
 
+func TestBytesStateFn(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedType TokenType
+		expectedVal  string
+	}{
+		{`0x"deadbeef"`, Bytes, `0x"deadbeef"`},
+		{`0X""`, Bytes, `0X""`},
+		{`0x"00FF" + 1`, Bytes, `0x"00FF"`},
+		{`0x"dead`, Illegal, "Byte string not terminated"},
+		{`0x"xyz"`, Illegal, "Byte string not terminated"},
+	}
+
+	for i, test := range tests {
+		s := &state{input: test.input}
+		e := MockEmitter{}
+		emitted := false
+		e.emitFunc = func(tok Token) {
+			emitted = true
+			if tok.Type != test.expectedType {
+				t.Errorf("tests[%d] - Wrong token type", i)
+			}
+			if tok.Val != test.expectedVal {
+				t.Errorf("tests[%d] - rune wrong. Expected=%s, got=%s", i, test.expectedVal, tok.Val)
+			}
+		}
+
+		// numberStateFn reads 0x prefix then hands over to bytesStateFn
+		next := numberStateFn(s, e)
+		if emitted {
+			t.Errorf("tests[%d] - numberStateFn should not emit byte string", i)
+			continue
+		}
+		next(s, e)
+	}
+}
+
 func TestIdentifierStateFn(t *testing.T) {
 	tests := []struct {
 		input        string
@@ -474,6 +536,11 @@ func TestIdentifierStateFn(t *testing.T) {
 		{"if", If, "if"},
 		{"else", Else, "else"},
 		{"int", IntType, "int"},
+		{"int8", Int8Type, "int8"},
+		{"int16", Int16Type, "int16"},
+		{"int32", Int32Type, "int32"},
+		{"int64", IntType, "int64"},
+		{"address", AddressType, "address"},
 		{"string", StringType, "string"},
 		{"return", Return, "return"},
 		{"true", True, "true"},
@@ -567,13 +634,41 @@ func TestNewTokenBuffer(t *testing.T) {
 		t.Errorf("NewTokenBuffer has wrong lexer")
 	}
 
-	if buf.cur.Type != Contract {
+	if buf.size != 2 {
+		t.Errorf("NewTokenBuffer has wrong number of tokens Expected=%d, got=%d",
+			2, buf.size)
+	}
+
+	if cur := buf.ring[buf.head]; cur.Type != Contract {
 		t.Errorf("NewTokenBuffer has wrong cur token Expected=%v, got=%v",
-			Eol, buf.cur.Type)
+			Contract, cur.Type)
 	}
 
-	if buf.next.Type != Lbrace {
+	if next := buf.ring[buf.head+1]; next.Type != Lbrace {
 		t.Errorf("NewTokenBuffer has wrong next token Expected=%v, got=%v",
-			Contract, buf.next.Type)
+			Lbrace, next.Type)
+	}
+}
+
+func TestTokenBuffer_grow(t *testing.T) {
+	input := `a b c d e f g h i j`
+	buf := NewTokenBuffer(NewLexer(input))
+
+	// rotate head so that grown ring has to unwrap held tokens
+	buf.Read()
+	buf.Read()
+	buf.Read()
+
+	if tok := buf.Peek(6); tok.Val != "j" {
+		t.Fatalf("Peek() wrong token Expected=%s, got=%s", "j", tok.Val)
+	}
+	if len(buf.ring) <= 6 {
+		t.Fatalf("ring did not grow, len=%d", len(buf.ring))
+	}
+
+	for _, expected := range []string{"d", "e", "f", "g", "h", "i", "j"} {
+		if tok := buf.Read(); tok.Val != expected {
+			t.Errorf("Read() wrong token Expected=%s, got=%s", expected, tok.Val)
+		}
 	}
 }
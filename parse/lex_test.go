@@ -138,6 +138,44 @@ func TestLexer_NextToken(t *testing.T) {
 	}
 }
 
+func TestCommentLexer_NextToken(t *testing.T) {
+	input := `// @notice doc
+/* block
+comment */
+contract { // trailing
+	string s = "// not comment"
+}`
+
+	tests := []struct {
+		lexTestCase
+		expectedLine int
+	}{
+		{lexTestCase{parse.Comment, "// @notice doc"}, 0},
+		{lexTestCase{parse.Comment, "/* block\ncomment */"}, 2},
+		{lexTestCase{parse.Contract, "contract"}, 3},
+		{lexTestCase{parse.Lbrace, "{"}, 3},
+		{lexTestCase{parse.Comment, "// trailing"}, 3},
+		{lexTestCase{parse.StringType, "string"}, 4},
+		{lexTestCase{parse.Ident, "s"}, 4},
+		{lexTestCase{parse.Assign, "="}, 4},
+		{lexTestCase{parse.String, `"// not comment"`}, 4},
+		{lexTestCase{parse.Semicolon, "\n"}, 5},
+		{lexTestCase{parse.Rbrace, "}"}, 5},
+		{lexTestCase{parse.Semicolon, ""}, 5},
+		{lexTestCase{parse.Eof, ""}, 5},
+	}
+
+	l := parse.NewCommentLexer(input)
+	for i, test := range tests {
+		token := l.NextToken()
+
+		compareToken(t, i, token, test.lexTestCase)
+		if token.Line != test.expectedLine {
+			t.Errorf("tests[%d] - wrong line. expected=%d, got=%d", i, test.expectedLine, token.Line)
+		}
+	}
+}
+
 func TestTokenBuffer(t *testing.T) {
 	input := `
 	contract { //lexer does not return this comment as token
@@ -181,9 +219,28 @@ func TestTokenBuffer(t *testing.T) {
 	tok = buf.Read()
 	compareToken(t, 6, tok, lexTestCase{parse.Ident, "name"})
 
-	// invalid peekNumber
+	// peek further than next token
 	tok = buf.Peek(3)
-	compareToken(t, 7, tok, lexTestCase{})
+	compareToken(t, 7, tok, lexTestCase{parse.Rparen, ")"})
+
+	tok = buf.Peek(2)
+	compareToken(t, 8, tok, lexTestCase{parse.IntType, "int"})
+
+	tok = buf.Read()
+	compareToken(t, 9, tok, lexTestCase{parse.Lparen, "("})
+
+	// peek further than buffer holds, then read up to the peeked token
+	peeked := buf.Peek(9)
+	for i := 0; i < 9; i++ {
+		buf.Read()
+	}
+	tok = buf.Read()
+	compareToken(t, 10, tok, lexTestCase{peeked.Type, peeked.Val})
+	compareToken(t, 11, tok, lexTestCase{parse.Ident, "a"})
+
+	// invalid peek count
+	tok = buf.Peek(-1)
+	compareToken(t, 12, tok, lexTestCase{})
 }
 
 func compareToken(t *testing.T, i int, tok parse.Token, tt lexTestCase) {
@@ -199,3 +256,39 @@ func compareToken(t *testing.T, i int, tok parse.Token, tt lexTestCase) {
 			i, tt.expectedValue, tok.Val)
 	}
 }
+
+func TestTokenBuffer_markAndReset(t *testing.T) {
+	input := `a b c d e f g h i j`
+	buf := parse.NewTokenBuffer(parse.NewLexer(input))
+
+	read := func(expected ...string) {
+		t.Helper()
+		for _, e := range expected {
+			compareToken(t, 0, buf.Read(), lexTestCase{parse.Ident, e})
+		}
+	}
+
+	read("a")
+
+	outer := buf.Mark()
+	read("b", "c")
+
+	// nested mark is reset while outer mark is still kept
+	inner := buf.Mark()
+	read("d", "e", "f", "g", "h")
+	buf.Reset(inner)
+	compareToken(t, 1, buf.Peek(parse.CURRENT), lexTestCase{parse.Ident, "d"})
+
+	read("d", "e")
+	buf.Reset(outer)
+	compareToken(t, 2, buf.Last(), lexTestCase{parse.Ident, "a"})
+	read("b", "c", "d")
+	compareToken(t, 3, buf.Last(), lexTestCase{parse.Ident, "d"})
+
+	// released mark does not rewind buffer
+	m := buf.Mark()
+	read("e", "f")
+	buf.Release(m)
+	buf.Reset(m)
+	read("g", "h", "i", "j")
+}
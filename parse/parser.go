@@ -17,12 +17,17 @@
 package parse
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/DE-labtory/koa/symbol"
 
 	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/encoding"
 )
 
 // OperatorTypeMap maps TokenType with OperatorType. By doing this
@@ -50,7 +55,14 @@ var datastructureMap = map[TokenType]ast.DataStructure{
 	IntType:    ast.IntType,
 	StringType: ast.StringType,
 	BoolType:   ast.BoolType,
-	VoidType:   ast.VoidType,
+	BytesType:  ast.BytesType,
+	UintType:   ast.UintType,
+	Int8Type:   ast.Int8Type,
+	Int16Type:  ast.Int16Type,
+	Int32Type:  ast.Int32Type,
+
+	AddressType: ast.AddressType,
+	VoidType:    ast.VoidType,
 }
 
 // precedence determine which token is going to be grouped first when
@@ -71,6 +83,8 @@ const (
 	PRODUCT     // *
 	PREFIX      // -X or !X
 	CALL        // function(X)
+	INDEX       // b[X]
+	SELECTOR    // msg.X
 )
 
 var precedenceMap = map[TokenType]precedence{
@@ -89,25 +103,22 @@ var precedenceMap = map[TokenType]precedence{
 	EQ:     EQUALS,
 	NOT_EQ: EQUALS,
 
-	Lparen: CALL,
+	Lparen:   CALL,
+	Lbracket: INDEX,
+	Dot:      SELECTOR,
 
 	Eol:  LOWEST,
 	Land: LAND,
 	Lor:  LOR,
 }
 
-// PeekNumber restrict peek count from the TokenBuffer
-type peekNumber int
-
+// CURRENT and NEXT are the peek count of current and next token
+// from the TokenBuffer
 const (
-	CURRENT peekNumber = iota
+	CURRENT = iota
 	NEXT
 )
 
-func (p peekNumber) isValid() bool {
-	return p == CURRENT || p == NEXT
-}
-
 // TokenBuffer provide tokenized token, we can read from this buffer
 // or just peek the token
 type TokenBuffer interface {
@@ -115,9 +126,22 @@ type TokenBuffer interface {
 	// buffer states
 	Read() Token
 
-	// Peek take token as many as n from buffer but not change the
-	// buffer states
-	Peek(n peekNumber) Token
+	// Peek take n-th token from the current token of buffer but
+	// not change the buffer states
+	Peek(n int) Token
+
+	// Last returns the last token read from buffer except semicolon,
+	// which parser uses to find where node ends
+	Last() Token
+
+	// Mark returns the checkpoint of current token, so that parser
+	// can attempt one production and fall back to another
+	Mark() int
+
+	// Reset rewinds buffer to the checkpoint of mark, and Release
+	// drops the mark once parser commits to the production
+	Reset(mark int)
+	Release(mark int)
 }
 
 func curTokenIs(buf TokenBuffer, t TokenType) bool {
@@ -162,6 +186,11 @@ func consumeSemi(buf TokenBuffer) {
 	}
 }
 
+// ErrSyntax is matched by every error which parser returns,
+// use errors.Is(err, ErrSyntax) to distinguish them from the errors
+// of other phases
+var ErrSyntax = errors.New("syntax error")
+
 // Error contains error which happened during
 // parsing tokens
 type Error struct {
@@ -174,6 +203,10 @@ func (e Error) Error() string {
 		e.Source.Line, e.Source.Column, TokenTypeMap[e.Source.Type], e.Reason)
 }
 
+func (e Error) Is(target error) bool {
+	return target == ErrSyntax
+}
+
 // ExpectError happens during parsing expectNext
 type ExpectError struct {
 	Source   Token
@@ -185,6 +218,10 @@ func (e ExpectError) Error() string {
 		e.Source.Line, e.Source.Column, TokenTypeMap[e.Expected], TokenTypeMap[e.Source.Type])
 }
 
+func (e ExpectError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
 // dupSymError occur when there is duplicated symbol
 type DupSymError struct {
 	Source Token
@@ -195,6 +232,10 @@ func (e DupSymError) Error() string {
 		e.Source.Line, e.Source.Column, e.Source.Val)
 }
 
+func (e DupSymError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
 // prefixError occur when there is invalid prefix type
 type PrefixError struct {
 	Source Token
@@ -206,6 +247,10 @@ func (e PrefixError) Error() string {
 		e.Source.Line, e.Source.Column, e.Right.String())
 }
 
+func (e PrefixError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
 // NotExistSymError occur when there is no target symbol
 type NotExistSymError struct {
 	Source Token
@@ -216,36 +261,185 @@ func (e NotExistSymError) Error() string {
 		e.Source.Line, e.Source.Column, e.Source.Val)
 }
 
+func (e NotExistSymError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+// ConstAssignError occur when statement assigns new value to constant
+type ConstAssignError struct {
+	Source Token
+}
+
+func (e ConstAssignError) Error() string {
+	return fmt.Sprintf("[line %d, column %d] cannot assign to constant [%s]",
+		e.Source.Line, e.Source.Column, e.Source.Val)
+}
+
+func (e ConstAssignError) Is(target error) bool {
+	return target == ErrSyntax
+}
+
+// ErrLimitExceeded is matched by LimitError
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// LimitError occur when contract is bigger than Limits allow
+type LimitError struct {
+	Source Token
+	Limit  string
+	Max    int
+}
+
+func (e LimitError) Error() string {
+	return fmt.Sprintf("[line %d, column %d] number of %s exceeds limit %d",
+		e.Source.Line, e.Source.Column, e.Limit, e.Max)
+}
+
+func (e LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// Limits bounds the size of contract which parser accepts, so that
+// untrusted source can be parsed without unbounded memory.
+// Zero value of each field means no limit.
+type Limits struct {
+	// MaxFunctions is the maximum number of functions in contract
+	MaxFunctions int
+
+	// MaxNodes is the maximum number of statements and expressions
+	// in contract
+	MaxNodes int
+}
+
 type (
-	prefixParseFn func(TokenBuffer) (ast.Expression, error)
-	infixParseFn  func(TokenBuffer, ast.Expression) (ast.Expression, error)
+	prefixParseFn func(*parser, TokenBuffer) (ast.Expression, error)
+	infixParseFn  func(*parser, TokenBuffer, ast.Expression) (ast.Expression, error)
 )
 
+// prefixParseFnMap and infixParseFnMap are built once by
+// initParseFnMap, and only read while parsing
 var prefixParseFnMap = map[TokenType]prefixParseFn{}
 var infixParseFnMap = map[TokenType]infixParseFn{}
 
-// scope keeps symbols that shows on tokens, every time scope meet symbol,
-// trying to check whether symbol with same name already exist, if true
-// then throw error, if not, add that symbol to scope.
-var scope *symbol.Scope
+func init() {
+	initParseFnMap()
+}
+
+// parser has the state of parsing one source. Each parse has its own,
+// so that contracts can be parsed concurrently.
+type parser struct {
+	// scope keeps symbols that shows on tokens, every time scope meet symbol,
+	// trying to check whether symbol with same name already exist, if true
+	// then throw error, if not, add that symbol to scope.
+	scope *symbol.Scope
+
+	// limits is applied to the contract being parsed,
+	// nodeCount is the number of nodes parsed so far
+	limits    Limits
+	nodeCount int
+
+	// file is the name of file being parsed, or empty when
+	// source has no file
+	file string
+
+	// modifiers are declared in the contract being parsed,
+	// inModifier is true while parsing modifier body where placeholder
+	// statement is allowed
+	modifiers  map[string]*ast.ModifierLiteral
+	inModifier bool
+
+	// resolver reads libraries of the contract being parsed,
+	// imported has the libraries already merged and importing is the chain
+	// of libraries being parsed, which is used to detect import cycle
+	resolver  Resolver
+	imported  map[string]bool
+	importing []string
+
+	// experiments are experimental features enabled by the contract
+	// being parsed, including its libraries, gated are the keywords
+	// whose syntax needs one of the registered experimental features
+	experiments map[string]bool
+	gated       map[TokenType]string
+}
+
+// countNode counts a statement or expression which is about to be
+// parsed, and fails when it exceeds limits
+func (p *parser) countNode(buf TokenBuffer) error {
+	p.nodeCount++
+	if p.limits.MaxNodes > 0 && p.nodeCount > p.limits.MaxNodes {
+		return LimitError{buf.Peek(CURRENT), "nodes", p.limits.MaxNodes}
+	}
+	return nil
+}
+
+// posOf returns position of token in the file being parsed
+func (p *parser) posOf(token Token) ast.Pos {
+	pos := ast.Pos{File: p.file, Line: token.Line, Column: int(token.Column)}
+	if len(p.importing) != 0 {
+		pos.File = p.importing[len(p.importing)-1]
+	}
+	return pos
+}
+
+// spanOf returns byte range of token in the file being parsed
+func spanOf(token Token) ast.Span {
+	return ast.Span{Start: int(token.Offset), End: int(token.Offset) + len(token.Val)}
+}
+
+// identOf returns identifier of token with its byte range and position
+func (p *parser) identOf(token Token) *ast.Identifier {
+	ident := &ast.Identifier{Name: token.Val, Span: spanOf(token)}
+	ident.SetPosition(p.posOf(token))
+	return ident
+}
+
+// setRange records byte range of node from start token to the last
+// token read from buf, and position of start token. Range which is
+// already recorded is kept, so that node parsed by inner production
+// keeps its own range.
+func (p *parser) setRange(buf TokenBuffer, n ast.Node, start Token) {
+	if pn, ok := n.(ast.Positioned); ok && !pn.Position().IsValid() {
+		pn.SetPosition(p.posOf(start))
+	}
+
+	r, ok := n.(ast.Ranged)
+	if !ok || r.Range().IsValid() {
+		return
+	}
+
+	last := buf.Last()
+	r.SetRange(ast.Span{Start: int(start.Offset), End: spanOf(last).End})
+}
 
 // updateScopeSymbol checks whether token value is exist in scope first,
 // if exist, then throw error, if not, make symbol with token value then add
 // to scope
-func updateScopeSymbol(ident Token, keyword Token) error {
-	if s := scope.Get(ident.Val); s != nil {
+func (p *parser) updateScopeSymbol(ident Token, keyword Token) error {
+	if isBlank(ident) {
+		return nil
+	}
+
+	if s := p.scope.Get(ident.Val); s != nil {
 		return DupSymError{ident}
 	}
 
 	switch keyword.Type {
 	case IntType:
-		scope.Set(ident.Val, &symbol.Integer{Name: &ast.Identifier{Name: ident.Val}})
+		p.scope.Set(ident.Val, &symbol.Integer{Name: &ast.Identifier{Name: ident.Val}})
+	case Int8Type, Int16Type, Int32Type:
+		width := datastructureMap[keyword.Type].Width()
+		p.scope.Set(ident.Val, &symbol.SizedInteger{Name: &ast.Identifier{Name: ident.Val}, Width: width})
 	case BoolType:
-		scope.Set(ident.Val, &symbol.Boolean{Name: &ast.Identifier{Name: ident.Val}})
+		p.scope.Set(ident.Val, &symbol.Boolean{Name: &ast.Identifier{Name: ident.Val}})
 	case StringType:
-		scope.Set(ident.Val, &symbol.String{Name: &ast.Identifier{Name: ident.Val}})
+		p.scope.Set(ident.Val, &symbol.String{Name: &ast.Identifier{Name: ident.Val}})
+	case BytesType:
+		p.scope.Set(ident.Val, &symbol.Bytes{Name: &ast.Identifier{Name: ident.Val}})
+	case UintType:
+		p.scope.Set(ident.Val, &symbol.Uint{Name: &ast.Identifier{Name: ident.Val}})
+	case AddressType:
+		p.scope.Set(ident.Val, &symbol.Address{Name: &ast.Identifier{Name: ident.Val}})
 	case Function:
-		scope.Set(ident.Val, &symbol.Function{Name: ident.Val})
+		p.scope.Set(ident.Val, &symbol.Function{Name: ident.Val})
 	default:
 		return Error{
 			keyword,
@@ -256,60 +450,309 @@ func updateScopeSymbol(ident Token, keyword Token) error {
 	return nil
 }
 
+// checkReassignable checks whether symbol of token exists and
+// is not a constant, so that it can be reassigned
+func (p *parser) checkReassignable(token Token) error {
+	if isBlank(token) {
+		return nil
+	}
+
+	s := p.scope.Get(token.Val)
+	if s == nil {
+		return NotExistSymError{token}
+	}
+	if _, ok := s.(*symbol.Constant); ok {
+		return ConstAssignError{token}
+	}
+	return nil
+}
+
 // enterScope creates new scope than converts it to existing scope
-func enterScope() {
+func (p *parser) enterScope() {
 	innerScope := symbol.NewScope()
-	innerScope.SetOuter(scope)
+	innerScope.SetOuter(p.scope)
 
-	scope.AppendInner(innerScope)
-	scope = innerScope
+	p.scope.AppendInner(innerScope)
+	p.scope = innerScope
 }
 
 // leaveScope converts current scope's outer to existing scope
-func leaveScope() {
-	outerScope := scope.GetOuter()
-	scope = outerScope
+func (p *parser) leaveScope() {
+	outerScope := p.scope.GetOuter()
+	p.scope = outerScope
+}
+
+// newParser returns parser for parsing new source
+func newParser(l Limits, r Resolver) *parser {
+	return &parser{
+		scope:       symbol.NewScope(),
+		limits:      l,
+		modifiers:   map[string]*ast.ModifierLiteral{},
+		resolver:    r,
+		imported:    map[string]bool{},
+		experiments: map[string]bool{},
+		gated:       gatedKeywords(),
+	}
 }
 
 // Parse creates an abstract syntax tree
 func Parse(buf TokenBuffer) (*ast.Contract, error) {
-	initParseFnMap()
+	return ParseContext(context.Background(), buf)
+}
 
-	scope = symbol.NewScope()
+// ParseContext is like Parse but stops before parsing the next
+// function once ctx is done, returning ctx.Err()
+func ParseContext(ctx context.Context, buf TokenBuffer) (*ast.Contract, error) {
+	return ParseLimited(ctx, buf, Limits{})
+}
+
+// ParseLimited is like ParseContext but fails with LimitError as soon as
+// contract exceeds one of limits
+func ParseLimited(ctx context.Context, buf TokenBuffer, l Limits) (*ast.Contract, error) {
+	return ParseImports(ctx, buf, l, nil)
+}
+
+// ParseImports is like ParseLimited but reads the libraries contract
+// imports with r, their declarations are merged to the contract
+func ParseImports(ctx context.Context, buf TokenBuffer, l Limits, r Resolver) (*ast.Contract, error) {
+	return parseFile(ctx, buf, l, r, "")
+}
+
+// parseFile parses contract of the file, whose name is recorded
+// to the positions of nodes
+func parseFile(ctx context.Context, buf TokenBuffer, l Limits, r Resolver, filename string) (*ast.Contract, error) {
+	p := newParser(l, r)
+	p.file = filename
+	return p.parseContract(ctx, buf)
+}
 
+// parseContract parses contract with its pragmas, imports and
+// interfaces
+func (p *parser) parseContract(ctx context.Context, buf TokenBuffer) (*ast.Contract, error) {
+	start := buf.Peek(CURRENT)
 	contract := &ast.Contract{}
 	contract.Functions = []*ast.FunctionLiteral{}
 
-	if err := parseContractStart(buf); err != nil {
+	if err := p.parsePragmas(buf, contract); err != nil {
+		return nil, err
+	}
+
+	if err := p.parseImports(ctx, buf, contract); err != nil {
 		return nil, err
 	}
 
-	for buf.Peek(CURRENT).Type == Function {
-		fn, err := parseFunctionLiteral(buf)
+	for curTokenIs(buf, Interface) {
+		i, err := p.parseInterface(buf, contract.Interfaces)
 		if err != nil {
 			return nil, err
 		}
 
-		contract.Functions = append(contract.Functions, fn)
+		contract.Interfaces = append(contract.Interfaces, i)
+	}
+
+	implements, err := p.parseContractStart(buf)
+	if err != nil {
+		return nil, err
+	}
+	contract.Implements = implements
+
+	for curTokenIs(buf, Function) || curTokenIs(buf, Const) || curTokenIs(buf, Enum) || curTokenIs(buf, ErrorDecl) || curTokenIs(buf, Modifier) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := p.parseDeclaration(buf, contract); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := parseContractEnd(buf); err != nil {
 		return nil, err
 	}
+	p.setRange(buf, contract, start)
 
 	return contract, nil
 }
 
+// parseDeclaration parse constant, error, modifier or function in contract
+// or library, and adds it to contract
+func (p *parser) parseDeclaration(buf TokenBuffer, contract *ast.Contract) error {
+	if err := p.checkGated(buf.Peek(CURRENT)); err != nil {
+		return err
+	}
+
+	switch tok := buf.Peek(CURRENT); tok.Type {
+	case Const:
+		c, err := p.parseConstStatement(buf)
+		if err != nil {
+			return err
+		}
+		p.setRange(buf, c, tok)
+		contract.Constants = append(contract.Constants, c)
+
+	case Enum:
+		e, err := p.parseEnumLiteral(buf)
+		if err != nil {
+			return err
+		}
+		p.setRange(buf, e, tok)
+		contract.Enums = append(contract.Enums, e)
+
+	case ErrorDecl:
+		e, err := p.parseErrorLiteral(buf)
+		if err != nil {
+			return err
+		}
+		p.setRange(buf, e, tok)
+		contract.Errors = append(contract.Errors, e)
+
+	case Modifier:
+		m, err := p.parseModifierLiteral(buf)
+		if err != nil {
+			return err
+		}
+		p.setRange(buf, m, tok)
+		contract.Modifiers = append(contract.Modifiers, m)
+
+	case Function:
+		if p.limits.MaxFunctions > 0 && len(contract.Functions) >= p.limits.MaxFunctions {
+			return LimitError{tok, "functions", p.limits.MaxFunctions}
+		}
+
+		fn, err := p.parseFunctionLiteral(buf)
+		if err != nil {
+			return err
+		}
+		p.setRange(buf, fn, tok)
+		contract.Functions = append(contract.Functions, fn)
+
+	default:
+		return Error{tok, "expected const, enum, error, modifier or function declaration"}
+	}
+
+	return nil
+}
+
 // parseContractStart validates whether given token stream is
-// starts with "contract" keyword with left-brace, otherwise throw error
-func parseContractStart(buf TokenBuffer) error {
+// starts with "contract" keyword with left-brace, otherwise throw error.
+// It returns the interfaces contract implements.
+// e.g. contract implements Token, Ownable {
+func (p *parser) parseContractStart(buf TokenBuffer) ([]*ast.Identifier, error) {
 	if err := expectNext(buf, Contract); err != nil {
-		return err
+		return nil, err
+	}
+
+	implements := []*ast.Identifier{}
+	if curTokenIs(buf, Implements) {
+		buf.Read()
+		for {
+			ident, err := p.parseIdentifier(buf)
+			if err != nil {
+				return nil, err
+			}
+			implements = append(implements, ident.(*ast.Identifier))
+
+			if !curTokenIs(buf, Comma) {
+				break
+			}
+			buf.Read()
+		}
 	}
+
 	if err := expectNext(buf, Lbrace); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return implements, nil
+}
+
+// parseInterface parse interface which has function signatures
+// without body. e.g. interface Token { func balance(owner int) int }
+func (p *parser) parseInterface(buf TokenBuffer, declared []*ast.Interface) (*ast.Interface, error) {
+	start := buf.Peek(CURRENT)
+	if err := p.checkGated(start); err != nil {
+		return nil, err
+	}
+	if err := expectNext(buf, Interface); err != nil {
+		return nil, err
+	}
+
+	token := buf.Read()
+	if token.Type != Ident {
+		return nil, ExpectError{token, Ident}
+	}
+	for _, i := range declared {
+		if i.Name.Name == token.Val {
+			return nil, Error{token, fmt.Sprintf("interface [%s] already exist", token.Val)}
+		}
+	}
+
+	if err := expectNext(buf, Lbrace); err != nil {
+		return nil, err
+	}
+	consumeSemi(buf)
+
+	i := &ast.Interface{Name: p.identOf(token)}
+	for curTokenIs(buf, Function) {
+		fn, err := p.parseFunctionSignature(buf)
+		if err != nil {
+			return nil, err
+		}
+		i.Functions = append(i.Functions, fn)
+		consumeSemi(buf)
+	}
+
+	if err := expectNext(buf, Rbrace); err != nil {
+		return nil, err
+	}
+	p.setRange(buf, i, start)
+	consumeSemi(buf)
+
+	return i, nil
+}
+
+// parseFunctionSignature parse function without body in interface,
+// it is not added to the scope of contract
+func (p *parser) parseFunctionSignature(buf TokenBuffer) (*ast.FunctionLiteral, error) {
+	p.enterScope()
+	defer p.leaveScope()
+
+	start := buf.Peek(CURRENT)
+	if err := expectNext(buf, Function); err != nil {
+		return nil, err
+	}
+
+	token := buf.Read()
+	if token.Type != Ident {
+		return nil, ExpectError{token, Ident}
+	}
+
+	lit := &ast.FunctionLiteral{Name: p.identOf(token)}
+	var err error
+
+	if err = expectNext(buf, Lparen); err != nil {
+		return nil, err
+	}
+
+	if lit.Parameters, err = p.parseFunctionParameterList(buf); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case curTokenIs(buf, Lparen):
+		if lit.ReturnTypes, err = parseFunctionReturnTypeList(buf); err != nil {
+			return nil, err
+		}
+		lit.ReturnType = ast.TupleType
+	case curTokenIs(buf, Semicolon), curTokenIs(buf, Rbrace):
+		lit.ReturnType = ast.VoidType
+	default:
+		if lit.ReturnType, err = parseFunctionReturnType(buf); err != nil {
+			return nil, err
+		}
+	}
+	p.setRange(buf, lit, start)
+
+	return lit, nil
 }
 
 // parseContractEnd validates whether contracts finish with
@@ -331,50 +774,90 @@ func parseContractEnd(buf TokenBuffer) error {
 //   - prefix-parsing function
 //
 func initParseFnMap() {
-	prefixParseFnMap[Ident] = parseIdentifier
-	prefixParseFnMap[Int] = parseIntegerLiteral
-	prefixParseFnMap[String] = parseStringLiteral
-	prefixParseFnMap[Bang] = parsePrefixExpression
-	prefixParseFnMap[Minus] = parsePrefixExpression
-	prefixParseFnMap[True] = parseBooleanLiteral
-	prefixParseFnMap[False] = parseBooleanLiteral
-	prefixParseFnMap[Lparen] = parseGroupedExpression
-
-	infixParseFnMap[Plus] = parseInfixExpression
-	infixParseFnMap[Minus] = parseInfixExpression
-	infixParseFnMap[Asterisk] = parseInfixExpression
-	infixParseFnMap[Slash] = parseInfixExpression
-	infixParseFnMap[Mod] = parseInfixExpression
-	infixParseFnMap[EQ] = parseInfixExpression
-	infixParseFnMap[NOT_EQ] = parseInfixExpression
-	infixParseFnMap[LT] = parseInfixExpression
-	infixParseFnMap[GT] = parseInfixExpression
-	infixParseFnMap[LTE] = parseInfixExpression
-	infixParseFnMap[GTE] = parseInfixExpression
-	infixParseFnMap[Land] = parseInfixExpression
-	infixParseFnMap[Lor] = parseInfixExpression
-	infixParseFnMap[Lparen] = parseCallExpression
+	prefixParseFnMap[Ident] = (*parser).parseIdentifier
+	prefixParseFnMap[Int] = (*parser).parseIntegerLiteral
+	prefixParseFnMap[String] = (*parser).parseStringLiteral
+	prefixParseFnMap[Bytes] = (*parser).parseBytesLiteral
+	prefixParseFnMap[Bang] = (*parser).parsePrefixExpression
+	prefixParseFnMap[Minus] = (*parser).parsePrefixExpression
+	prefixParseFnMap[True] = (*parser).parseBooleanLiteral
+	prefixParseFnMap[False] = (*parser).parseBooleanLiteral
+	prefixParseFnMap[Lparen] = (*parser).parseGroupedExpression
+
+	infixParseFnMap[Plus] = (*parser).parseInfixExpression
+	infixParseFnMap[Minus] = (*parser).parseInfixExpression
+	infixParseFnMap[Asterisk] = (*parser).parseInfixExpression
+	infixParseFnMap[Slash] = (*parser).parseInfixExpression
+	infixParseFnMap[Mod] = (*parser).parseInfixExpression
+	infixParseFnMap[EQ] = (*parser).parseInfixExpression
+	infixParseFnMap[NOT_EQ] = (*parser).parseInfixExpression
+	infixParseFnMap[LT] = (*parser).parseInfixExpression
+	infixParseFnMap[GT] = (*parser).parseInfixExpression
+	infixParseFnMap[LTE] = (*parser).parseInfixExpression
+	infixParseFnMap[GTE] = (*parser).parseInfixExpression
+	infixParseFnMap[Land] = (*parser).parseInfixExpression
+	infixParseFnMap[Lor] = (*parser).parseInfixExpression
+	infixParseFnMap[Lparen] = (*parser).parseCallExpression
+	infixParseFnMap[Lbracket] = (*parser).parseIndexExpression
+	infixParseFnMap[Dot] = (*parser).parseSelectorExpression
 }
 
 // parseStatement parse statement which don't produce value
-func parseStatement(buf TokenBuffer) (ast.Statement, error) {
+func (p *parser) parseStatement(buf TokenBuffer) (ast.Statement, error) {
+	if err := p.countNode(buf); err != nil {
+		return nil, err
+	}
+
+	start := buf.Peek(CURRENT)
+	stmt, err := p.parseStatementOf(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	p.setRange(buf, stmt, start)
+	return stmt, nil
+}
+
+// parseStatementOf parse statement with the production of its
+// current token
+func (p *parser) parseStatementOf(buf TokenBuffer) (ast.Statement, error) {
+	if err := p.checkGated(buf.Peek(CURRENT)); err != nil {
+		return nil, err
+	}
+
 	switch tt := buf.Peek(CURRENT).Type; tt {
 	case IntType:
-		return parseAssignStatement(buf)
+		return p.parseVariableStatement(buf)
 	case BoolType:
-		return parseAssignStatement(buf)
+		return p.parseVariableStatement(buf)
 	case StringType:
-		return parseAssignStatement(buf)
+		return p.parseVariableStatement(buf)
+	case BytesType:
+		return p.parseVariableStatement(buf)
+	case UintType, Int8Type, Int16Type, Int32Type, AddressType:
+		return p.parseVariableStatement(buf)
 	case If:
-		return parseIfStatement(buf)
+		return p.parseIfStatement(buf)
+	case For:
+		return p.parseForStatement(buf)
+	case Do:
+		return p.parseDoWhileStatement(buf)
 	case Return:
-		return parseReturnStatement(buf)
+		return p.parseReturnStatement(buf)
+	case Revert:
+		return p.parseRevertStatement(buf)
 	default:
+		if isPlaceholder(buf.Peek(CURRENT)) && !nextTokenIs(buf, Assign) {
+			return p.parsePlaceholderStatement(buf)
+		}
+
 		switch buf.Peek(NEXT).Type {
 		case Assign:
-			return parseReassignStatement(buf)
+			return p.parseReassignStatement(buf)
+		case Inc, Dec:
+			return p.parseIncDecStatement(buf)
 		default:
-			return parseExpressionStatement(buf)
+			return p.parseExpressionStatement(buf)
 		}
 	}
 }
@@ -385,12 +868,15 @@ func parseStatement(buf TokenBuffer) (ast.Statement, error) {
 // Parsing expression is done in Pratt Parsing way. So each
 // token has its own parsing function. And each token has its
 // parsing precedence.
-func parseExpression(buf TokenBuffer, pre precedence) (ast.Expression, error) {
-	exp, err := makePrefixExpression(buf)
+func (p *parser) parseExpression(buf TokenBuffer, pre precedence) (ast.Expression, error) {
+	start := buf.Peek(CURRENT)
+	exp, err := p.makePrefixExpression(buf)
 	if err != nil {
 		return exp, err
 	}
-	exp, err = makeInfixExpression(buf, exp, pre)
+	p.setRange(buf, exp, start)
+
+	exp, err = p.makeInfixExpression(buf, exp, pre, start)
 	if err != nil {
 		return exp, err
 	}
@@ -400,8 +886,15 @@ func parseExpression(buf TokenBuffer, pre precedence) (ast.Expression, error) {
 
 // ParseExpAsPrefix retrieves prefix parse function from
 // map, then parse expression with that function if exist.
-func makePrefixExpression(buf TokenBuffer) (ast.Expression, error) {
+func (p *parser) makePrefixExpression(buf TokenBuffer) (ast.Expression, error) {
+	if err := p.countNode(buf); err != nil {
+		return nil, err
+	}
+
 	curTok := buf.Peek(CURRENT)
+	if err := p.checkGated(curTok); err != nil {
+		return nil, err
+	}
 
 	fn := prefixParseFnMap[curTok.Type]
 
@@ -411,7 +904,7 @@ func makePrefixExpression(buf TokenBuffer) (ast.Expression, error) {
 			"prefix parse function not defined",
 		}
 	}
-	exp, err := fn(buf)
+	exp, err := fn(p, buf)
 	if err != nil {
 		return nil, err
 	}
@@ -420,8 +913,9 @@ func makePrefixExpression(buf TokenBuffer) (ast.Expression, error) {
 }
 
 // MakeInfixExpression retrieves infix parse function from map
-// then parse expression with that function if exist.
-func makeInfixExpression(buf TokenBuffer, exp ast.Expression, pre precedence) (ast.Expression, error) {
+// then parse expression with that function if exist. Each expression
+// made ranges from start, which is the first token of exp.
+func (p *parser) makeInfixExpression(buf TokenBuffer, exp ast.Expression, pre precedence, start Token) (ast.Expression, error) {
 	var err error
 	expression := exp
 	for !curTokenIs(buf, Semicolon) && pre < curPrecedence(buf) {
@@ -434,10 +928,15 @@ func makeInfixExpression(buf TokenBuffer, exp ast.Expression, pre precedence) (a
 			}
 		}
 
-		expression, err = fn(buf, expression)
+		if err := p.countNode(buf); err != nil {
+			return nil, err
+		}
+
+		expression, err = fn(p, buf, expression)
 		if err != nil {
 			return nil, err
 		}
+		p.setRange(buf, expression, start)
 	}
 	return expression, nil
 }
@@ -447,7 +946,7 @@ func makeInfixExpression(buf TokenBuffer, exp ast.Expression, pre precedence) (a
 //
 // Infix parsing is based on a precedence of given token which is defined
 // in precedenceMap
-func parseInfixExpression(buf TokenBuffer, left ast.Expression) (ast.Expression, error) {
+func (p *parser) parseInfixExpression(buf TokenBuffer, left ast.Expression) (ast.Expression, error) {
 	var err error
 	curTok := buf.Read()
 
@@ -457,7 +956,7 @@ func parseInfixExpression(buf TokenBuffer, left ast.Expression) (ast.Expression,
 	}
 
 	precedence := precedenceMap[curTok.Type]
-	expression.Right, err = parseExpression(buf, precedence)
+	expression.Right, err = p.parseExpression(buf, precedence)
 	if err != nil {
 		return nil, err
 	}
@@ -470,11 +969,11 @@ func parseInfixExpression(buf TokenBuffer, left ast.Expression) (ast.Expression,
 //
 // Prefix parsing is based on a precedence of given token which is defined
 // in precedenceMap.
-func parsePrefixExpression(buf TokenBuffer) (ast.Expression, error) {
+func (p *parser) parsePrefixExpression(buf TokenBuffer) (ast.Expression, error) {
 	token := buf.Read()
 	op := operatorMap[token.Type]
 
-	right, err := parseExpression(buf, PREFIX)
+	right, err := p.parseExpression(buf, PREFIX)
 	if err != nil {
 		return nil, err
 	}
@@ -506,33 +1005,64 @@ func parsePrefixExpression(buf TokenBuffer) (ast.Expression, error) {
 }
 
 // parseIdentifier parse identifier.
-func parseIdentifier(buf TokenBuffer) (ast.Expression, error) {
+func (p *parser) parseIdentifier(buf TokenBuffer) (ast.Expression, error) {
 	token := buf.Read()
 	if token.Type != Ident {
 		return nil, ExpectError{token, Ident}
 	}
 
-	return &ast.Identifier{Name: token.Val}, nil
+	return p.identOf(token), nil
 }
 
 // parseIntegerLiteral parse integer literal.
-func parseIntegerLiteral(buf TokenBuffer) (ast.Expression, error) {
+func (p *parser) parseIntegerLiteral(buf TokenBuffer) (ast.Expression, error) {
 	token := buf.Read()
 	if token.Type != Int {
 		return nil, ExpectError{token, Int}
 	}
 
+	if isAddressLiteral(token.Val) {
+		return parseAddressLiteral(token)
+	}
+
 	value, err := strconv.ParseInt(token.Val, 0, 64)
 	if err != nil {
-		return nil, err
+		return nil, Error{token, err.Error()}
 	}
 
 	lit := &ast.IntegerLiteral{Value: value}
 	return lit, nil
 }
 
+// isAddressLiteral reports whether hexadecimal literal has as many
+// digits as address, which is address literal instead of integer
+func isAddressLiteral(lit string) bool {
+	if len(lit) != 2+encoding.AddressLength*2 || !(strings.HasPrefix(lit, "0x") || strings.HasPrefix(lit, "0X")) {
+		return false
+	}
+	_, err := hex.DecodeString(lit[2:])
+	return err == nil
+}
+
+// parseAddressLiteral parse address literal, which should have
+// mixed-case checksum. e.g. 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed
+func parseAddressLiteral(token Token) (ast.Expression, error) {
+	if err := encoding.ValidateChecksumAddress(token.Val); err != nil {
+		return nil, Error{token, err.Error()}
+	}
+
+	decoded, err := encoding.DecodeAddress(token.Val)
+	if err != nil {
+		return nil, Error{token, err.Error()}
+	}
+
+	lit := &ast.AddressLiteral{}
+	copy(lit.Value[:], decoded)
+	return lit, nil
+}
+
 // parseBooleanLiteral parse boolean literal.
-func parseBooleanLiteral(buf TokenBuffer) (ast.Expression, error) {
+func (p *parser) parseBooleanLiteral(buf TokenBuffer) (ast.Expression, error) {
 	token := buf.Read()
 	if token.Type != True && token.Type != False {
 		return nil, ExpectError{token, BoolType}
@@ -540,7 +1070,7 @@ func parseBooleanLiteral(buf TokenBuffer) (ast.Expression, error) {
 
 	val, err := strconv.ParseBool(token.Val)
 	if err != nil {
-		return nil, err
+		return nil, Error{token, err.Error()}
 	}
 
 	lit := &ast.BooleanLiteral{Value: val}
@@ -549,7 +1079,7 @@ func parseBooleanLiteral(buf TokenBuffer) (ast.Expression, error) {
 
 // parseStringLiteral parse string value which is
 // going to be assigned to variable
-func parseStringLiteral(buf TokenBuffer) (ast.Expression, error) {
+func (p *parser) parseStringLiteral(buf TokenBuffer) (ast.Expression, error) {
 	token := buf.Read()
 	if token.Type != String {
 		return nil, ExpectError{token, String}
@@ -558,10 +1088,26 @@ func parseStringLiteral(buf TokenBuffer) (ast.Expression, error) {
 	return &ast.StringLiteral{Value: token.Val}, nil
 }
 
+// parseBytesLiteral parse byte string literal. e.g. 0x"deadbeef"
+func (p *parser) parseBytesLiteral(buf TokenBuffer) (ast.Expression, error) {
+	token := buf.Read()
+	if token.Type != Bytes {
+		return nil, ExpectError{token, Bytes}
+	}
+
+	// strip 0x" and "
+	value, err := hex.DecodeString(token.Val[3 : len(token.Val)-1])
+	if err != nil {
+		return nil, Error{token, err.Error()}
+	}
+
+	return &ast.BytesLiteral{Value: value}, nil
+}
+
 // parseFunctionLiteral parse functional expression
 // first parse name, and parse parameter, body
-func parseFunctionLiteral(buf TokenBuffer) (*ast.FunctionLiteral, error) {
-	enterScope()
+func (p *parser) parseFunctionLiteral(buf TokenBuffer) (*ast.FunctionLiteral, error) {
+	p.enterScope()
 
 	lit := &ast.FunctionLiteral{}
 	var err error
@@ -576,41 +1122,334 @@ func parseFunctionLiteral(buf TokenBuffer) (*ast.FunctionLiteral, error) {
 		return nil, ExpectError{token, Ident}
 	}
 
-	if err := updateScopeSymbol(token, keyword); err != nil {
+	if err := p.updateScopeSymbol(token, keyword); err != nil {
 		return nil, err
 	}
 
-	lit.Name = &ast.Identifier{Name: token.Val}
+	lit.Name = p.identOf(token)
 
 	if err = expectNext(buf, Lparen); err != nil {
 		return nil, err
 	}
 
-	if lit.Parameters, err = parseFunctionParameterList(buf); err != nil {
+	if lit.Parameters, err = p.parseFunctionParameterList(buf); err != nil {
+		return nil, err
+	}
+
+	applied := []*ast.ModifierLiteral{}
+	appliedTokens := []Token{}
+	for curTokenIs(buf, Ident) {
+		token := buf.Read()
+		m, ok := p.modifiers[token.Val]
+		if !ok {
+			return nil, Error{token, fmt.Sprintf("undefined modifier [%s]", token.Val)}
+		}
+		lit.Modifiers = append(lit.Modifiers, m.Name)
+		applied = append(applied, m)
+		appliedTokens = append(appliedTokens, token)
+	}
+
+	if curTokenIs(buf, Lparen) {
+		if lit.ReturnTypes, err = parseFunctionReturnTypeList(buf); err != nil {
+			return nil, err
+		}
+		lit.ReturnType = ast.TupleType
+	} else if lit.ReturnType, err = parseFunctionReturnType(buf); err != nil {
 		return nil, err
 	}
 
-	if lit.ReturnType, err = parseFunctionReturnType(buf); err != nil {
+	p.recordFunctionSignature(token, lit)
+
+	if lit.Body, err = p.parseBlockStatement(buf); err != nil {
 		return nil, err
 	}
 
-	if lit.Body, err = parseBlockStatement(buf); err != nil {
+	if err := checkModifierLocals(lit, applied, appliedTokens); err != nil {
 		return nil, err
 	}
 
+	// the first modifier is the outermost
+	for i := len(applied) - 1; i >= 0; i-- {
+		lit.Body = applied[i].Wrap(lit.Body)
+	}
+
 	consumeSemi(buf)
-	leaveScope()
+	p.leaveScope()
 
 	return lit, nil
 }
 
-// parseFunctionReturnType parse function's return data structure type
-func parseFunctionReturnType(buf TokenBuffer) (ast.DataStructure, error) {
-	peekTok := buf.Peek(CURRENT)
+// parseErrorLiteral parse custom error declaration.
+// e.g. error InsufficientBalance(needed int, got int)
+func (p *parser) parseErrorLiteral(buf TokenBuffer) (*ast.ErrorLiteral, error) {
+	p.enterScope()
+	defer p.leaveScope()
 
-	ds, ok := datastructureMap[peekTok.Type]
-	if !ok && peekTok.Type != Lbrace {
-		return 0, Error{
+	if err := expectNext(buf, ErrorDecl); err != nil {
+		return nil, err
+	}
+
+	token := buf.Read()
+	if token.Type != Ident {
+		return nil, ExpectError{token, Ident}
+	}
+
+	if err := expectNext(buf, Lparen); err != nil {
+		return nil, err
+	}
+
+	params, err := p.parseFunctionParameterList(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, param := range params {
+		if param.Default != nil {
+			return nil, Error{token, fmt.Sprintf("parameter [%s] of error can't have default value", param.Identifier.Name)}
+		}
+	}
+	consumeSemi(buf)
+
+	return &ast.ErrorLiteral{Name: p.identOf(token), Parameters: params}, nil
+}
+
+// parseEnumLiteral parse enum declaration, whose name is added to
+// scope. e.g. enum Status { Pending, Active, Closed }
+func (p *parser) parseEnumLiteral(buf TokenBuffer) (*ast.EnumLiteral, error) {
+	if err := expectNext(buf, Enum); err != nil {
+		return nil, err
+	}
+
+	token := buf.Read()
+	if token.Type != Ident {
+		return nil, ExpectError{token, Ident}
+	}
+	if s := p.scope.Get(token.Val); s != nil {
+		return nil, DupSymError{token}
+	}
+
+	if err := expectNext(buf, Lbrace); err != nil {
+		return nil, err
+	}
+	consumeSemi(buf)
+
+	lit := &ast.EnumLiteral{Name: p.identOf(token), Members: []*ast.Identifier{}}
+	members := []string{}
+	for !curTokenIs(buf, Rbrace) {
+		member := buf.Read()
+		if member.Type != Ident {
+			return nil, ExpectError{member, Ident}
+		}
+		for _, m := range members {
+			if m == member.Val {
+				return nil, Error{member, fmt.Sprintf("member [%s] of enum already exist", member.Val)}
+			}
+		}
+		members = append(members, member.Val)
+		lit.Members = append(lit.Members, p.identOf(member))
+
+		if !curTokenIs(buf, Comma) {
+			consumeSemi(buf)
+			break
+		}
+		buf.Read()
+		consumeSemi(buf)
+	}
+
+	if err := expectNext(buf, Rbrace); err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, Error{token, fmt.Sprintf("enum [%s] has no member", token.Val)}
+	}
+	consumeSemi(buf)
+
+	p.scope.Set(token.Val, &symbol.Enum{Name: lit.Name, Members: members})
+	return lit, nil
+}
+
+// parseModifierLiteral parse modifier which wraps function bodies.
+// e.g. modifier positive { require(FEE > 0, "fee") _ }
+func (p *parser) parseModifierLiteral(buf TokenBuffer) (*ast.ModifierLiteral, error) {
+	if err := expectNext(buf, Modifier); err != nil {
+		return nil, err
+	}
+
+	token := buf.Read()
+	if token.Type != Ident {
+		return nil, ExpectError{token, Ident}
+	}
+	if _, ok := p.modifiers[token.Val]; ok {
+		return nil, Error{token, fmt.Sprintf("modifier [%s] already exist", token.Val)}
+	}
+
+	p.inModifier = true
+	body, err := p.parseBlockStatement(buf)
+	p.inModifier = false
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasPlaceholder(body) {
+		return nil, Error{token, fmt.Sprintf("modifier [%s] has no placeholder _", token.Val)}
+	}
+
+	m := &ast.ModifierLiteral{Name: p.identOf(token), Body: body}
+	p.modifiers[token.Val] = m
+
+	consumeSemi(buf)
+	return m, nil
+}
+
+// checkModifierLocals checks that local variables of modifiers applied
+// to function are not declared in function or in the other modifiers.
+// Modifier body is inlined to function, so that locals of the same
+// name would share memory.
+func checkModifierLocals(lit *ast.FunctionLiteral, applied []*ast.ModifierLiteral, tokens []Token) error {
+	owners := map[string]string{}
+	for _, p := range lit.Parameters {
+		owners[p.Identifier.Name] = fmt.Sprintf("function [%s]", lit.Name.Name)
+	}
+	for _, name := range localsOf(lit.Body) {
+		owners[name] = fmt.Sprintf("function [%s]", lit.Name.Name)
+	}
+
+	for i, m := range applied {
+		locals := localsOf(m.Body)
+		for _, name := range locals {
+			if owner, ok := owners[name]; ok {
+				return Error{tokens[i], fmt.Sprintf("local [%s] of modifier [%s] is also declared in %s", name, m.Name.Name, owner)}
+			}
+		}
+		for _, name := range locals {
+			owners[name] = fmt.Sprintf("modifier [%s]", m.Name.Name)
+		}
+	}
+	return nil
+}
+
+// localsOf returns names of variables declared in block, except
+// blank identifier
+func localsOf(block *ast.BlockStatement) []string {
+	if block == nil {
+		return nil
+	}
+
+	names := []string{}
+	for _, s := range block.Statements {
+		names = append(names, localsOfStatement(s)...)
+	}
+	return names
+}
+
+func localsOfStatement(s ast.Statement) []string {
+	names := []string{}
+	switch stmt := s.(type) {
+	case *ast.AssignStatement:
+		names = append(names, stmt.Variable.Name)
+	case *ast.TupleAssignStatement:
+		for _, v := range stmt.Variables {
+			names = append(names, v.Name)
+		}
+	case *ast.BlockStatement:
+		names = append(names, localsOf(stmt)...)
+	case *ast.IfStatement:
+		names = append(names, localsOf(stmt.Consequence)...)
+		names = append(names, localsOf(stmt.Alternative)...)
+	case *ast.ForStatement:
+		if stmt.Init != nil {
+			names = append(names, localsOfStatement(stmt.Init)...)
+		}
+		names = append(names, localsOf(stmt.Body)...)
+	case *ast.DoWhileStatement:
+		names = append(names, localsOf(stmt.Body)...)
+	}
+
+	locals := names[:0]
+	for _, name := range names {
+		if name != "_" {
+			locals = append(locals, name)
+		}
+	}
+	return locals
+}
+
+// hasPlaceholder reports whether placeholder statement is in block
+func hasPlaceholder(block *ast.BlockStatement) bool {
+	if block == nil {
+		return false
+	}
+
+	for _, s := range block.Statements {
+		switch stmt := s.(type) {
+		case *ast.PlaceholderStatement:
+			return true
+		case *ast.BlockStatement:
+			if hasPlaceholder(stmt) {
+				return true
+			}
+		case *ast.IfStatement:
+			if hasPlaceholder(stmt.Consequence) || hasPlaceholder(stmt.Alternative) {
+				return true
+			}
+		case *ast.ForStatement:
+			if hasPlaceholder(stmt.Body) {
+				return true
+			}
+		case *ast.DoWhileStatement:
+			if hasPlaceholder(stmt.Body) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isPlaceholder(token Token) bool {
+	return token.Type == Ident && token.Val == "_"
+}
+
+// isBlank reports whether token is blank identifier _, which discards
+// value assigned to it, so it is never added to scope
+func isBlank(token Token) bool {
+	return token.Type == Ident && token.Val == "_"
+}
+
+// parsePlaceholderStatement parse _ which marks where modifier
+// places function body
+func (p *parser) parsePlaceholderStatement(buf TokenBuffer) (ast.Statement, error) {
+	token := buf.Read()
+	if !p.inModifier {
+		return nil, Error{token, "placeholder _ is only allowed in modifier"}
+	}
+
+	consumeSemi(buf)
+	return &ast.PlaceholderStatement{}, nil
+}
+
+// recordFunctionSignature saves parameter types and return type of
+// function literal to its function symbol
+func (p *parser) recordFunctionSignature(ident Token, lit *ast.FunctionLiteral) {
+	fn, ok := p.scope.Get(ident.Val).(*symbol.Function)
+	if !ok {
+		return
+	}
+
+	fn.Parameters = make([]ast.DataStructure, 0, len(lit.Parameters))
+	for _, p := range lit.Parameters {
+		fn.Parameters = append(fn.Parameters, p.Type)
+	}
+	fn.ReturnType = lit.ReturnType
+	fn.ReturnTypes = lit.ReturnTypes
+}
+
+// parseFunctionReturnType parse function's return data structure type
+func parseFunctionReturnType(buf TokenBuffer) (ast.DataStructure, error) {
+	peekTok := buf.Peek(CURRENT)
+
+	ds, ok := datastructureMap[peekTok.Type]
+	if !ok && peekTok.Type != Lbrace {
+		return 0, Error{
 			peekTok,
 			"invalid function return type",
 		}
@@ -625,15 +1464,54 @@ func parseFunctionReturnType(buf TokenBuffer) (ast.DataStructure, error) {
 	return ds, nil
 }
 
+// parseFunctionReturnTypeList parse parenthesized return types of
+// function which returns multiple values. e.g. (int, bool)
+func parseFunctionReturnTypeList(buf TokenBuffer) ([]ast.DataStructure, error) {
+	if err := expectNext(buf, Lparen); err != nil {
+		return nil, err
+	}
+
+	types := []ast.DataStructure{}
+	for {
+		token := buf.Read()
+		ds, ok := datastructureMap[token.Type]
+		if !ok || ds == ast.VoidType {
+			return nil, Error{
+				token,
+				"invalid function return type",
+			}
+		}
+		types = append(types, ds)
+
+		if !curTokenIs(buf, Comma) {
+			break
+		}
+		buf.Read()
+	}
+
+	if err := expectNext(buf, Rparen); err != nil {
+		return nil, err
+	}
+
+	if len(types) < 2 {
+		return nil, Error{
+			buf.Peek(CURRENT),
+			"parenthesized return type needs at least two types",
+		}
+	}
+
+	return types, nil
+}
+
 // parseFunctionParameters parse function's parameters which
 // separated by comma
-func parseFunctionParameterList(buf TokenBuffer) ([]*ast.ParameterLiteral, error) {
+func (p *parser) parseFunctionParameterList(buf TokenBuffer) ([]*ast.ParameterLiteral, error) {
 	identifiers := []*ast.ParameterLiteral{}
 	if err := expectNext(buf, Rparen); err == nil {
 		return identifiers, nil
 	}
 
-	ident, err := parseFunctionParameter(buf)
+	ident, err := p.parseFunctionParameter(buf)
 	if err != nil {
 		return nil, err
 	}
@@ -642,10 +1520,16 @@ func parseFunctionParameterList(buf TokenBuffer) ([]*ast.ParameterLiteral, error
 	for curTokenIs(buf, Comma) {
 		buf.Read()
 
-		ident, err := parseFunctionParameter(buf)
+		token := buf.Peek(CURRENT)
+		ident, err := p.parseFunctionParameter(buf)
 		if err != nil {
 			return nil, err
 		}
+
+		// only trailing parameters can have default value
+		if ident.Default == nil && identifiers[len(identifiers)-1].Default != nil {
+			return nil, Error{token, fmt.Sprintf("parameter [%s] without default value follows parameter with default value", token.Val)}
+		}
 		identifiers = append(identifiers, ident)
 	}
 
@@ -656,7 +1540,7 @@ func parseFunctionParameterList(buf TokenBuffer) ([]*ast.ParameterLiteral, error
 	return identifiers, nil
 }
 
-func parseFunctionParameter(buf TokenBuffer) (*ast.ParameterLiteral, error) {
+func (p *parser) parseFunctionParameter(buf TokenBuffer) (*ast.ParameterLiteral, error) {
 	token := buf.Read()
 	if token.Type != Ident {
 		return nil, ExpectError{
@@ -666,7 +1550,7 @@ func parseFunctionParameter(buf TokenBuffer) (*ast.ParameterLiteral, error) {
 	}
 
 	ident := &ast.ParameterLiteral{
-		Identifier: &ast.Identifier{Name: token.Val},
+		Identifier: p.identOf(token),
 	}
 
 	dsToken := buf.Read()
@@ -679,15 +1563,27 @@ func parseFunctionParameter(buf TokenBuffer) (*ast.ParameterLiteral, error) {
 	}
 	ident.Type = ds
 
-	if err := updateScopeSymbol(token, dsToken); err != nil {
+	// parameter with default value, e.g. b int = 10
+	if curTokenIs(buf, Assign) {
+		buf.Read()
+
+		exp, err := p.parseExpression(buf, LOWEST)
+		if err != nil {
+			return nil, err
+		}
+		ident.Default = exp
+	}
+
+	if err := p.updateScopeSymbol(token, dsToken); err != nil {
 		return nil, err
 	}
+	p.setRange(buf, ident, token)
 
 	return ident, nil
 }
 
 // parseReturnStatement parse "return" keyword with its expression
-func parseReturnStatement(buf TokenBuffer) (ast.Statement, error) {
+func (p *parser) parseReturnStatement(buf TokenBuffer) (ast.Statement, error) {
 	if err := expectNext(buf, Return); err != nil {
 		return nil, err
 	}
@@ -699,7 +1595,7 @@ func parseReturnStatement(buf TokenBuffer) (ast.Statement, error) {
 		return stmt, nil
 	}
 
-	exp, err := parseExpression(buf, LOWEST)
+	exp, err := p.parseExpressionList(buf)
 	if err != nil {
 		return nil, err
 	}
@@ -710,11 +1606,42 @@ func parseReturnStatement(buf TokenBuffer) (ast.Statement, error) {
 	return stmt, nil
 }
 
+// parseExpressionList parse expressions separated by comma. When
+// there are more than one expression, returns them as tuple expression.
+// e.g. 1, true
+func (p *parser) parseExpressionList(buf TokenBuffer) (ast.Expression, error) {
+	start := buf.Peek(CURRENT)
+	exp, err := p.parseExpression(buf, LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	if !curTokenIs(buf, Comma) {
+		return exp, nil
+	}
+
+	tuple := &ast.TupleExpression{
+		Elements: []ast.Expression{exp},
+	}
+	for curTokenIs(buf, Comma) {
+		buf.Read()
+
+		exp, err := p.parseExpression(buf, LOWEST)
+		if err != nil {
+			return nil, err
+		}
+		tuple.Elements = append(tuple.Elements, exp)
+	}
+	p.setRange(buf, tuple, start)
+
+	return tuple, nil
+}
+
 // parseGroupedExpression parse grouped expression which
 // grouped using parenthesis
-func parseGroupedExpression(buf TokenBuffer) (ast.Expression, error) {
+func (p *parser) parseGroupedExpression(buf TokenBuffer) (ast.Expression, error) {
 	buf.Read()
-	exp, err := parseExpression(buf, LOWEST)
+	exp, err := p.parseExpression(buf, LOWEST)
 	if err != nil {
 		return nil, err
 	}
@@ -725,35 +1652,156 @@ func parseGroupedExpression(buf TokenBuffer) (ast.Expression, error) {
 	return exp, nil
 }
 
-// parseAssignStatement parse assign statements which assign values
-// to its identifier. e.g. int a = 1
-func parseAssignStatement(buf TokenBuffer) (*ast.AssignStatement, error) {
-	stmt := &ast.AssignStatement{}
+// parseVariableStatement parse statements which starts with data structure,
+// it is either assign statement or tuple assign statement.
+// e.g. int a = 1, int a, bool b = f()
+func (p *parser) parseVariableStatement(buf TokenBuffer) (ast.Statement, error) {
+	ds, ident, err := p.parseAssignTarget(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if curTokenIs(buf, Comma) {
+		return p.parseTupleAssignStatement(buf, ds, ident)
+	}
 
+	return p.parseAssignValue(buf, ds, ident)
+}
+
+// parseAssignTarget parse data structure with identifier which
+// value is assigned to, then add the identifier to scope
+func (p *parser) parseAssignTarget(buf TokenBuffer) (ast.DataStructure, ast.Identifier, error) {
 	dsToken := buf.Read()
-	stmt.Type = datastructureMap[dsToken.Type]
+	ds := datastructureMap[dsToken.Type]
 
 	token := buf.Read()
 	if token.Type != Ident {
-		return nil, ExpectError{
+		return ds, ast.Identifier{}, ExpectError{
 			token,
 			Ident,
 		}
 	}
 
-	if err := updateScopeSymbol(token, dsToken); err != nil {
+	if err := p.updateScopeSymbol(token, dsToken); err != nil {
+		return ds, ast.Identifier{}, err
+	}
+
+	return ds, *p.identOf(token), nil
+}
+
+// parseConstStatement parse constant declaration at contract scope.
+// The constant is added to scope as immutable symbol.
+// e.g. const int FEE = 100
+func (p *parser) parseConstStatement(buf TokenBuffer) (*ast.ConstStatement, error) {
+	if err := p.countNode(buf); err != nil {
+		return nil, err
+	}
+
+	if err := expectNext(buf, Const); err != nil {
+		return nil, err
+	}
+
+	dsToken := buf.Read()
+	ds, ok := datastructureMap[dsToken.Type]
+	if !ok || ds == ast.VoidType {
+		return nil, Error{dsToken, "constant must be int, uint, bool or string"}
+	}
+
+	token := buf.Read()
+	if token.Type != Ident {
+		return nil, ExpectError{token, Ident}
+	}
+
+	if s := p.scope.Get(token.Val); s != nil {
+		return nil, DupSymError{token}
+	}
+	p.scope.Set(token.Val, &symbol.Constant{
+		Name:     &ast.Identifier{Name: token.Val},
+		DataType: ds,
+	})
+
+	if err := expectNext(buf, Assign); err != nil {
+		return nil, err
+	}
+
+	exp, err := p.parseExpression(buf, LOWEST)
+	if err != nil {
+		return nil, err
+	}
+	consumeSemi(buf)
+
+	return &ast.ConstStatement{
+		Type:  ds,
+		Name:  *p.identOf(token),
+		Value: exp,
+	}, nil
+}
+
+// parseTupleAssignStatement parse assign statement which assign
+// multiple values to identifiers. e.g. int a, bool b = f()
+func (p *parser) parseTupleAssignStatement(buf TokenBuffer, ds ast.DataStructure, ident ast.Identifier) (*ast.TupleAssignStatement, error) {
+	stmt := &ast.TupleAssignStatement{
+		Types:     []ast.DataStructure{ds},
+		Variables: []ast.Identifier{ident},
+	}
+
+	for curTokenIs(buf, Comma) {
+		buf.Read()
+
+		if _, ok := datastructureMap[buf.Peek(CURRENT).Type]; !ok {
+			return nil, Error{
+				buf.Peek(CURRENT),
+				"data structure of variable missed",
+			}
+		}
+
+		ds, ident, err := p.parseAssignTarget(buf)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Types = append(stmt.Types, ds)
+		stmt.Variables = append(stmt.Variables, ident)
+	}
+
+	if err := expectNext(buf, Assign); err != nil {
+		return nil, err
+	}
+
+	exp, err := p.parseExpressionList(buf)
+	if err != nil {
+		return nil, err
+	}
+	stmt.Value = exp
+
+	consumeSemi(buf)
+
+	return stmt, nil
+}
+
+// parseAssignStatement parse assign statements which assign values
+// to its identifier. e.g. int a = 1
+func (p *parser) parseAssignStatement(buf TokenBuffer) (*ast.AssignStatement, error) {
+	ds, ident, err := p.parseAssignTarget(buf)
+	if err != nil {
 		return nil, err
 	}
 
-	stmt.Variable = ast.Identifier{
-		Name: token.Val,
+	return p.parseAssignValue(buf, ds, ident)
+}
+
+// parseAssignValue parse value of assign statement which is
+// assigned to ident
+func (p *parser) parseAssignValue(buf TokenBuffer, ds ast.DataStructure, ident ast.Identifier) (*ast.AssignStatement, error) {
+	stmt := &ast.AssignStatement{
+		Type:     ds,
+		Variable: ident,
 	}
 
 	if err := expectNext(buf, Assign); err != nil {
 		return nil, err
 	}
 
-	exp, err := parseExpression(buf, LOWEST)
+	exp, err := p.parseExpression(buf, LOWEST)
 	if err != nil {
 		return nil, err
 	}
@@ -768,24 +1816,24 @@ func parseAssignStatement(buf TokenBuffer) (*ast.AssignStatement, error) {
 // parseReassignStatement parse reassign statement
 // i.e) int a = 1
 // a = 2
-func parseReassignStatement(buf TokenBuffer) (ast.Statement, error) {
+func (p *parser) parseReassignStatement(buf TokenBuffer) (ast.Statement, error) {
 	stmt := &ast.ReassignStatement{}
 	token := buf.Read()
 	if token.Type != Ident {
 		return nil, ExpectError{Source: token, Expected: Ident}
 	}
 
-	if exist := scope.Get(token.Val); exist == nil {
-		return nil, NotExistSymError{token}
+	if err := p.checkReassignable(token); err != nil {
+		return nil, err
 	}
 
-	stmt.Variable = &ast.Identifier{Name: token.Val}
+	stmt.Variable = p.identOf(token)
 
 	if err := expectNext(buf, Assign); err != nil {
 		return nil, err
 	}
 
-	exp, err := parseExpression(buf, LOWEST)
+	exp, err := p.parseExpression(buf, LOWEST)
 	if err != nil {
 		return nil, err
 	}
@@ -796,12 +1844,40 @@ func parseReassignStatement(buf TokenBuffer) (ast.Statement, error) {
 	return stmt, nil
 }
 
+// parseIncDecStatement parse increment or decrement of variable, which
+// is parsed as reassignment of it. e.g. i++ is parsed as i = i + 1
+func (p *parser) parseIncDecStatement(buf TokenBuffer) (ast.Statement, error) {
+	token := buf.Read()
+	if token.Type != Ident {
+		return nil, ExpectError{Source: token, Expected: Ident}
+	}
+
+	if err := p.checkReassignable(token); err != nil {
+		return nil, err
+	}
+
+	operator := ast.Plus
+	if op := buf.Read(); op.Type == Dec {
+		operator = ast.Minus
+	}
+	consumeSemi(buf)
+
+	return &ast.ReassignStatement{
+		Variable: p.identOf(token),
+		Value: &ast.InfixExpression{
+			Left:     p.identOf(token),
+			Operator: operator,
+			Right:    &ast.IntegerLiteral{Value: 1},
+		},
+	}, nil
+}
+
 // parseCallExpression parse function call
-func parseCallExpression(buf TokenBuffer, fn ast.Expression) (ast.Expression, error) {
+func (p *parser) parseCallExpression(buf TokenBuffer, fn ast.Expression) (ast.Expression, error) {
 	exp := &ast.CallExpression{Function: fn}
 
 	var err error
-	exp.Arguments, err = parseCallArguments(buf)
+	exp.Arguments, err = p.parseCallArguments(buf)
 	if err != nil {
 		return nil, err
 	}
@@ -811,8 +1887,46 @@ func parseCallExpression(buf TokenBuffer, fn ast.Expression) (ast.Expression, er
 	return exp, nil
 }
 
+// parseIndexExpression parse index of expression. e.g. b[0]
+func (p *parser) parseIndexExpression(buf TokenBuffer, left ast.Expression) (ast.Expression, error) {
+	if err := expectNext(buf, Lbracket); err != nil {
+		return nil, err
+	}
+
+	index, err := p.parseExpression(buf, LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expectNext(buf, Rbracket); err != nil {
+		return nil, err
+	}
+
+	return &ast.IndexExpression{Left: left, Index: index}, nil
+}
+
+// parseSelectorExpression parse field selected from identifier. e.g. msg.sender
+func (p *parser) parseSelectorExpression(buf TokenBuffer, left ast.Expression) (ast.Expression, error) {
+	dot := buf.Read()
+	if dot.Type != Dot {
+		return nil, ExpectError{dot, Dot}
+	}
+
+	ident, ok := left.(*ast.Identifier)
+	if !ok {
+		return nil, Error{dot, fmt.Sprintf("cannot select field of %s", left)}
+	}
+
+	field, err := p.parseIdentifier(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.SelectorExpression{Left: ident, Field: field.(*ast.Identifier)}, nil
+}
+
 // parseCallArguments parse arguments of function call
-func parseCallArguments(buf TokenBuffer) ([]ast.Expression, error) {
+func (p *parser) parseCallArguments(buf TokenBuffer) ([]ast.Expression, error) {
 	args := []ast.Expression{}
 	if err := expectNext(buf, Lparen); err != nil {
 		return nil, err
@@ -823,7 +1937,7 @@ func parseCallArguments(buf TokenBuffer) ([]ast.Expression, error) {
 		return args, nil
 	}
 
-	exp, err := parseExpression(buf, LOWEST)
+	exp, err := p.parseExpression(buf, LOWEST)
 	if err != nil {
 		return nil, err
 	}
@@ -833,7 +1947,7 @@ func parseCallArguments(buf TokenBuffer) ([]ast.Expression, error) {
 	for curTokenIs(buf, Comma) {
 		buf.Read()
 
-		exp, err := parseExpression(buf, LOWEST)
+		exp, err := p.parseExpression(buf, LOWEST)
 		if err != nil {
 			return nil, err
 		}
@@ -850,7 +1964,7 @@ func parseCallArguments(buf TokenBuffer) ([]ast.Expression, error) {
 }
 
 // parseIfStatement parse if-else statement. Else statement is optional
-func parseIfStatement(buf TokenBuffer) (*ast.IfStatement, error) {
+func (p *parser) parseIfStatement(buf TokenBuffer) (*ast.IfStatement, error) {
 	if err := expectNext(buf, If); err != nil {
 		return nil, err
 	}
@@ -861,7 +1975,7 @@ func parseIfStatement(buf TokenBuffer) (*ast.IfStatement, error) {
 
 	expression := &ast.IfStatement{}
 	var err error
-	expression.Condition, err = parseExpression(buf, LOWEST)
+	expression.Condition, err = p.parseExpression(buf, LOWEST)
 	if err != nil {
 		return nil, err
 	}
@@ -870,7 +1984,7 @@ func parseIfStatement(buf TokenBuffer) (*ast.IfStatement, error) {
 		return nil, err
 	}
 
-	expression.Consequence, err = parseBlockStatement(buf)
+	expression.Consequence, err = p.parseBlockStatement(buf)
 	if err != nil {
 		return nil, err
 	}
@@ -878,7 +1992,7 @@ func parseIfStatement(buf TokenBuffer) (*ast.IfStatement, error) {
 	if curTokenIs(buf, Else) {
 		buf.Read()
 
-		expression.Alternative, err = parseBlockStatement(buf)
+		expression.Alternative, err = p.parseBlockStatement(buf)
 		if err != nil {
 			return nil, err
 		}
@@ -889,6 +2003,206 @@ func parseIfStatement(buf TokenBuffer) (*ast.IfStatement, error) {
 	return expression, nil
 }
 
+// parseForStatement parse for statement in two forms, with three
+// clauses or with condition only. Variables declared in init clause
+// are only visible in the loop.
+// e.g. for (int i = 0; i < 10; i = i + 1) { ... }, for (x < 10) { ... }
+func (p *parser) parseForStatement(buf TokenBuffer) (*ast.ForStatement, error) {
+	if err := expectNext(buf, For); err != nil {
+		return nil, err
+	}
+
+	if err := expectNext(buf, Lparen); err != nil {
+		return nil, err
+	}
+
+	p.enterScope()
+	defer p.leaveScope()
+
+	stmt := &ast.ForStatement{}
+	var err error
+
+	_, isAssign := datastructureMap[buf.Peek(CURRENT).Type]
+	isReassign := curTokenIs(buf, Ident) && nextTokenIs(buf, Assign)
+
+	if isAssign || isReassign || curTokenIs(buf, Semicolon) {
+		start := buf.Peek(CURRENT)
+		if stmt.Init, err = p.parseForInit(buf); err != nil {
+			return nil, err
+		}
+		p.setRange(buf, stmt.Init, start)
+		if err := expectNext(buf, Semicolon); err != nil {
+			return nil, err
+		}
+		return p.parseForClauses(buf, stmt)
+	}
+
+	if stmt.Condition, err = p.parseExpression(buf, LOWEST); err != nil {
+		return nil, err
+	}
+
+	if err := expectNext(buf, Rparen); err != nil {
+		return nil, err
+	}
+
+	if stmt.Body, err = p.parseBlockStatement(buf); err != nil {
+		return nil, err
+	}
+
+	consumeSemi(buf)
+
+	return stmt, nil
+}
+
+// parseDoWhileStatement parse loop which runs body at least once,
+// variables declared in body are not visible in condition.
+// e.g. do { i = i + 1 } while (i < 10)
+func (p *parser) parseDoWhileStatement(buf TokenBuffer) (*ast.DoWhileStatement, error) {
+	if err := expectNext(buf, Do); err != nil {
+		return nil, err
+	}
+
+	stmt := &ast.DoWhileStatement{}
+	var err error
+
+	if stmt.Body, err = p.parseBlockStatement(buf); err != nil {
+		return nil, err
+	}
+
+	if err := expectNext(buf, While); err != nil {
+		return nil, err
+	}
+
+	if err := expectNext(buf, Lparen); err != nil {
+		return nil, err
+	}
+
+	if stmt.Condition, err = p.parseExpression(buf, LOWEST); err != nil {
+		return nil, err
+	}
+
+	if err := expectNext(buf, Rparen); err != nil {
+		return nil, err
+	}
+
+	consumeSemi(buf)
+
+	return stmt, nil
+}
+
+// parseRevertStatement parse revert with custom error.
+// e.g. revert InsufficientBalance(amount, balance)
+func (p *parser) parseRevertStatement(buf TokenBuffer) (*ast.RevertStatement, error) {
+	if err := expectNext(buf, Revert); err != nil {
+		return nil, err
+	}
+
+	token := buf.Read()
+	if token.Type != Ident {
+		return nil, ExpectError{token, Ident}
+	}
+
+	args, err := p.parseCallArguments(buf)
+	if err != nil {
+		return nil, err
+	}
+	consumeSemi(buf)
+
+	return &ast.RevertStatement{Error: p.identOf(token), Arguments: args}, nil
+}
+
+// parseForInit parse init clause of for statement, which is assign
+// statement, reassign statement or empty. Unlike other statements,
+// semicolon after the clause is not consumed.
+func (p *parser) parseForInit(buf TokenBuffer) (ast.Statement, error) {
+	if curTokenIs(buf, Semicolon) {
+		return nil, nil
+	}
+
+	if curTokenIs(buf, Ident) {
+		token := buf.Read()
+		if err := p.checkReassignable(token); err != nil {
+			return nil, err
+		}
+
+		if err := expectNext(buf, Assign); err != nil {
+			return nil, err
+		}
+
+		exp, err := p.parseExpression(buf, LOWEST)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ast.ReassignStatement{
+			Variable: p.identOf(token),
+			Value:    exp,
+		}, nil
+	}
+
+	ds, ident, err := p.parseAssignTarget(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expectNext(buf, Assign); err != nil {
+		return nil, err
+	}
+
+	exp, err := p.parseExpression(buf, LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.AssignStatement{
+		Type:     ds,
+		Variable: ident,
+		Value:    exp,
+	}, nil
+}
+
+// parseForClauses parse condition and post clause of for statement,
+// and its body. Init clause with its semicolon is already consumed.
+func (p *parser) parseForClauses(buf TokenBuffer, stmt *ast.ForStatement) (*ast.ForStatement, error) {
+	var err error
+
+	if !curTokenIs(buf, Semicolon) {
+		if stmt.Condition, err = p.parseExpression(buf, LOWEST); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expectNext(buf, Semicolon); err != nil {
+		return nil, err
+	}
+
+	start := buf.Peek(CURRENT)
+	switch {
+	case curTokenIs(buf, Rparen):
+	case nextTokenIs(buf, Inc) || nextTokenIs(buf, Dec):
+		if stmt.Post, err = p.parseIncDecStatement(buf); err != nil {
+			return nil, err
+		}
+	default:
+		if stmt.Post, err = p.parseReassignStatement(buf); err != nil {
+			return nil, err
+		}
+	}
+	p.setRange(buf, stmt.Post, start)
+
+	if err := expectNext(buf, Rparen); err != nil {
+		return nil, err
+	}
+
+	if stmt.Body, err = p.parseBlockStatement(buf); err != nil {
+		return nil, err
+	}
+
+	consumeSemi(buf)
+
+	return stmt, nil
+}
+
 // parseBlockStatement parse block statement.
 // PROTOCOL:
 //   reading token from TokenBuffer **only and must** be done in
@@ -898,18 +2212,19 @@ func parseIfStatement(buf TokenBuffer) (*ast.IfStatement, error) {
 //
 //  parseBlockStatement parse: { ... } <-- left-brace + statements + Right-brace
 //
-func parseBlockStatement(buf TokenBuffer) (*ast.BlockStatement, error) {
+func (p *parser) parseBlockStatement(buf TokenBuffer) (*ast.BlockStatement, error) {
+	start := buf.Peek(CURRENT)
 	if err := expectNext(buf, Lbrace); err != nil {
 		return nil, err
 	}
 
-	enterScope()
+	p.enterScope()
 
 	block := &ast.BlockStatement{}
 	curToken := buf.Peek(CURRENT)
 
 	for curToken.Type != Rbrace && curToken.Type != Eof {
-		stmt, err := parseStatement(buf)
+		stmt, err := p.parseStatement(buf)
 		if err != nil {
 			return nil, err
 		}
@@ -922,27 +2237,31 @@ func parseBlockStatement(buf TokenBuffer) (*ast.BlockStatement, error) {
 	if curTokenIs(buf, Rbrace) {
 		buf.Read()
 	}
+	p.setRange(buf, block, start)
 
-	leaveScope()
+	p.leaveScope()
 
 	return block, nil
 }
 
-func parseExpressionStatement(buf TokenBuffer) (*ast.ExpressionStatement, error) {
+// errNotStatement is the reason of error for expression
+// which can't be used as statement
+const errNotStatement = "expression is not a statement, expected call, assignment, increment or decrement"
+
+// parseExpressionStatement parse call expression used as statement,
+// the other expressions don't have effect and are not statement
+func (p *parser) parseExpressionStatement(buf TokenBuffer) (*ast.ExpressionStatement, error) {
 	stmt := &ast.ExpressionStatement{}
 	token := buf.Read()
-	if token.Type != Ident {
-		return nil, ExpectError{
-			token,
-			Ident,
-		}
+	if token.Type != Ident || !curTokenIs(buf, Lparen) {
+		return nil, Error{token, errNotStatement}
 	}
 
-	ident := &ast.Identifier{Name: token.Val}
-	exp, err := parseCallExpression(buf, ident)
+	exp, err := p.parseCallExpression(buf, p.identOf(token))
 	if err != nil {
 		return nil, err
 	}
+	p.setRange(buf, exp, token)
 
 	stmt.Expr = exp
 	return stmt, nil
@@ -17,7 +17,6 @@
 package parse
 
 import (
-	"errors"
 	"fmt"
 	"testing"
 
@@ -39,10 +38,29 @@ func (m *mockTokenBuffer) Read() Token {
 	return ret
 }
 
-func (m *mockTokenBuffer) Peek(n peekNumber) Token {
-	return m.buf[m.sp+int(n)]
+func (m *mockTokenBuffer) Peek(n int) Token {
+	return m.buf[m.sp+n]
 }
 
+func (m *mockTokenBuffer) Last() Token {
+	for i := m.sp - 1; i >= 0; i-- {
+		if m.buf[i].Type != Semicolon {
+			return m.buf[i]
+		}
+	}
+	return Token{}
+}
+
+func (m *mockTokenBuffer) Mark() int {
+	return m.sp
+}
+
+func (m *mockTokenBuffer) Reset(mark int) {
+	m.sp = mark
+}
+
+func (m *mockTokenBuffer) Release(mark int) {}
+
 // setupScopeFn helps to build Scope for each test case
 type setupScopeFn func() *symbol.Scope
 
@@ -95,7 +113,7 @@ contract {
 			},
 			expected: "",
 			expectedErr: ExpectError{
-				Token{Eof, "eof", 0, 0},
+				Token{Eof, "eof", 0, 0, 0},
 				Rbrace,
 			},
 		},
@@ -215,7 +233,7 @@ func bar(Parameter : (Identifier: a, Type: int)) void {
 			},
 			expected: ``,
 			expectedErr: ExpectError{
-				Token{IntType, "int", 0, 0},
+				Token{IntType, "int", 0, 0, 0},
 				Rbrace,
 			},
 		},
@@ -394,37 +412,6 @@ func TestNextPrecedence(t *testing.T) {
 	}
 }
 
-func TestPeekNumber_IsValid(t *testing.T) {
-	tests := []struct {
-		n        peekNumber
-		expected bool
-	}{
-		{
-			n:        peekNumber(0),
-			expected: true,
-		},
-		{
-			n:        peekNumber(1),
-			expected: true,
-		},
-		{
-			n:        peekNumber(2),
-			expected: false,
-		},
-		{
-			n:        peekNumber(-1),
-			expected: false,
-		},
-	}
-
-	for i, test := range tests {
-		n := test.n
-		if n.isValid() != test.expected {
-			t.Fatalf("test[%d] - isValid() result wrong. Expected=%t, got=%t", i, test.expected, n.isValid())
-		}
-	}
-}
-
 func TestExpectNext(t *testing.T) {
 	tokens := []Token{
 		{Type: Int, Val: "1"},
@@ -448,7 +435,7 @@ func TestExpectNext(t *testing.T) {
 			token:        Minus,
 			expectedBool: false,
 			expectedError: ExpectError{
-				Token{Ident, "a", 0, 0},
+				Token{Ident, "a", 0, 0, 0},
 				Minus,
 			},
 		},
@@ -461,7 +448,7 @@ func TestExpectNext(t *testing.T) {
 			token:        Rbrace,
 			expectedBool: false,
 			expectedError: ExpectError{
-				Token{Asterisk, "*", 0, 0},
+				Token{Asterisk, "*", 0, 0, 0},
 				Rbrace,
 			},
 		},
@@ -482,6 +469,7 @@ func TestExpectNext(t *testing.T) {
 }
 
 func TestParseIdentifier(t *testing.T) {
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		buf          TokenBuffer
 		setupScope   setupScopeFn
@@ -496,6 +484,7 @@ func TestParseIdentifier(t *testing.T) {
 						"1",
 						24,
 						12,
+						0,
 					},
 				},
 				0,
@@ -503,7 +492,7 @@ func TestParseIdentifier(t *testing.T) {
 			setupScope: defaultSetupScopeFn,
 			expected:   nil,
 			expectedErrs: ExpectError{
-				Token{Int, "1", 24, 12},
+				Token{Int, "1", 24, 12, 0},
 				Ident,
 			},
 		},
@@ -515,6 +504,7 @@ func TestParseIdentifier(t *testing.T) {
 						"ADD",
 						125,
 						225,
+						0,
 					},
 				},
 				0,
@@ -537,6 +527,7 @@ func TestParseIdentifier(t *testing.T) {
 						"a",
 						125,
 						225,
+						0,
 					},
 				},
 				0,
@@ -549,6 +540,7 @@ func TestParseIdentifier(t *testing.T) {
 					"a",
 					125,
 					225,
+					0,
 				},
 			},
 		},
@@ -560,6 +552,7 @@ func TestParseIdentifier(t *testing.T) {
 						"+",
 						422,
 						12,
+						0,
 					},
 				},
 				0,
@@ -567,7 +560,7 @@ func TestParseIdentifier(t *testing.T) {
 			setupScope: defaultSetupScopeFn,
 			expected:   nil,
 			expectedErrs: ExpectError{
-				Token{Plus, "+", 422, 12},
+				Token{Plus, "+", 422, 12, 0},
 				Ident,
 			},
 		},
@@ -579,6 +572,7 @@ func TestParseIdentifier(t *testing.T) {
 						"*",
 						12,
 						123,
+						0,
 					},
 				},
 				0,
@@ -586,7 +580,7 @@ func TestParseIdentifier(t *testing.T) {
 			setupScope: defaultSetupScopeFn,
 			expected:   nil,
 			expectedErrs: ExpectError{
-				Token{Asterisk, "*", 12, 123},
+				Token{Asterisk, "*", 12, 123, 0},
 				Ident,
 			},
 		},
@@ -598,6 +592,7 @@ func TestParseIdentifier(t *testing.T) {
 						"(",
 						5,
 						876,
+						0,
 					},
 				},
 				0,
@@ -605,15 +600,15 @@ func TestParseIdentifier(t *testing.T) {
 			setupScope: defaultSetupScopeFn,
 			expected:   nil,
 			expectedErrs: ExpectError{
-				Token{Lparen, "(", 5, 876},
+				Token{Lparen, "(", 5, 876, 0},
 				Ident,
 			},
 		},
 	}
 
 	for i, test := range tests {
-		scope = test.setupScope()
-		exp, err := parseIdentifier(test.buf)
+		p.scope = test.setupScope()
+		exp, err := p.parseIdentifier(test.buf)
 
 		if err != nil && err.Error() != test.expectedErrs.Error() {
 			t.Fatalf("test[%d] - wrong error. Expected=%s, got=%s", i, test.expectedErrs, err)
@@ -633,12 +628,16 @@ func TestParseIdentifier(t *testing.T) {
 }
 
 func TestParseIntegerLiteral(t *testing.T) {
+	p := newParser(Limits{}, nil)
 	tokens := []Token{
 		{Type: Int, Val: "12"},
 		{Type: Int, Val: "55"},
 		{Type: Int, Val: "a"},
 		{Type: String, Val: "abcdefg"},
 		{Type: Int, Val: "-13"},
+		{Type: Int, Val: "0x1F"},
+		{Type: Int, Val: "0b1010"},
+		{Type: Int, Val: "1_000_000"},
 	}
 	tokenBuf := mockTokenBuffer{tokens, 0}
 	tests := []struct {
@@ -654,8 +653,11 @@ func TestParseIntegerLiteral(t *testing.T) {
 			expectedErr: nil,
 		},
 		{
-			expected:    nil,
-			expectedErr: errors.New(`strconv.ParseInt: parsing "a": invalid syntax`),
+			expected: nil,
+			expectedErr: Error{
+				Token{Type: Int, Val: "a"},
+				`strconv.ParseInt: parsing "a": invalid syntax`,
+			},
 		},
 		{
 			expected: nil,
@@ -668,12 +670,24 @@ func TestParseIntegerLiteral(t *testing.T) {
 			expected:    &ast.IntegerLiteral{Value: -13},
 			expectedErr: nil,
 		},
+		{
+			expected:    &ast.IntegerLiteral{Value: 31},
+			expectedErr: nil,
+		},
+		{
+			expected:    &ast.IntegerLiteral{Value: 10},
+			expectedErr: nil,
+		},
+		{
+			expected:    &ast.IntegerLiteral{Value: 1000000},
+			expectedErr: nil,
+		},
 	}
 
 	for i, test := range tests {
 		// For debugging
 		tokenBuf.sp = i
-		exp, err := parseIntegerLiteral(&tokenBuf)
+		exp, err := p.parseIntegerLiteral(&tokenBuf)
 		if err != nil && err.Error() != test.expectedErr.Error() {
 			t.Fatalf("test[%d] - TestParseIntegerLiteral() wrong error. Expected=%s, got=%s",
 				i, test.expectedErr, err.Error())
@@ -687,6 +701,7 @@ func TestParseIntegerLiteral(t *testing.T) {
 }
 
 func TestParseBooleanLiteral(t *testing.T) {
+	p := newParser(Limits{}, nil)
 	tokens := []Token{
 		{Type: True, Val: "true"},
 		{Type: False, Val: "false"},
@@ -699,16 +714,19 @@ func TestParseBooleanLiteral(t *testing.T) {
 		expectedErr error
 	}{
 		{
-			expected:    &ast.BooleanLiteral{true},
+			expected:    &ast.BooleanLiteral{Value: true},
 			expectedErr: nil,
 		},
 		{
-			expected:    &ast.BooleanLiteral{false},
+			expected:    &ast.BooleanLiteral{Value: false},
 			expectedErr: nil,
 		},
 		{
-			expected:    nil,
-			expectedErr: errors.New(`strconv.ParseBool: parsing "azzx": invalid syntax`),
+			expected: nil,
+			expectedErr: Error{
+				Token{Type: True, Val: "azzx"},
+				`strconv.ParseBool: parsing "azzx": invalid syntax`,
+			},
 		},
 		{
 			expected: nil,
@@ -720,7 +738,7 @@ func TestParseBooleanLiteral(t *testing.T) {
 	}
 
 	for i, test := range tests {
-		exp, err := parseBooleanLiteral(&tokenBuf)
+		exp, err := p.parseBooleanLiteral(&tokenBuf)
 
 		if err != nil && err.Error() != test.expectedErr.Error() {
 			t.Fatalf(`test[%d] - TestParseBooleanLiteral() wrong error. Expected="%s", got="%s"`,
@@ -740,6 +758,7 @@ func TestParseBooleanLiteral(t *testing.T) {
 }
 
 func TestParseStringLiteral(t *testing.T) {
+	p := newParser(Limits{}, nil)
 	tokens := []Token{
 		{Type: String, Val: "hello"},
 		{Type: String, Val: "hihi"},
@@ -775,7 +794,7 @@ func TestParseStringLiteral(t *testing.T) {
 	for i, test := range tests {
 		// For debbuging
 		tokenBuf.sp = i
-		exp, err := parseStringLiteral(&tokenBuf)
+		exp, err := p.parseStringLiteral(&tokenBuf)
 
 		switch err != nil {
 		case true:
@@ -796,7 +815,7 @@ func TestParseStringLiteral(t *testing.T) {
 }
 
 func TestParseFunctionLiteral(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 
 	tests := []struct {
 		buf          TokenBuffer
@@ -871,7 +890,7 @@ func TestParseFunctionLiteral(t *testing.T) {
 			defaultSetupScopeFn,
 			"",
 			ExpectError{
-				Token{Lbrace, "{", 0, 0},
+				Token{Lbrace, "{", 0, 0, 0},
 				Function,
 			},
 		},
@@ -1048,9 +1067,9 @@ if ( true ) {  } else {  }
 	}
 
 	for i, test := range tests {
-		scope = test.setupScope()
+		p.scope = test.setupScope()
 
-		exp, err := parseFunctionLiteral(test.buf)
+		exp, err := p.parseFunctionLiteral(test.buf)
 
 		if err != nil && err.Error() != test.expectedErr.Error() {
 			t.Fatalf("test[%d] - TestParseFunctionLiteral() wrong error\n"+
@@ -1067,7 +1086,7 @@ if ( true ) {  } else {  }
 }
 
 func TestParseFunctionParameter(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		buf         TokenBuffer
 		setupScope  setupScopeFn
@@ -1120,7 +1139,7 @@ func TestParseFunctionParameter(t *testing.T) {
 			setupScope: defaultSetupScopeFn,
 			expected:   nil,
 			expectedErr: ExpectError{
-				Token{Rbrace, "}", 0, 0},
+				Token{Rbrace, "}", 0, 0, 0},
 				Rparen,
 			},
 		},
@@ -1143,8 +1162,8 @@ func TestParseFunctionParameter(t *testing.T) {
 	}
 
 	for i, test := range tests {
-		scope = test.setupScope()
-		identifiers, err := parseFunctionParameterList(test.buf)
+		p.scope = test.setupScope()
+		identifiers, err := p.parseFunctionParameterList(test.buf)
 		if err != nil && err.Error() != test.expectedErr.Error() {
 			t.Fatalf("test[%d] - TestParseFunctionParameter() wrong error.\n"+
 				"Expected: %s\n"+
@@ -1162,7 +1181,7 @@ func TestParseFunctionParameter(t *testing.T) {
 }
 
 func TestMakePrefixExpression(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		buf         TokenBuffer
 		setupScope  setupScopeFn
@@ -1295,8 +1314,8 @@ func TestMakePrefixExpression(t *testing.T) {
 	}
 
 	for i, tt := range tests {
-		scope = tt.setupScope()
-		exp, err := makePrefixExpression(tt.buf)
+		p.scope = tt.setupScope()
+		exp, err := p.makePrefixExpression(tt.buf)
 
 		if err != nil && err.Error() != tt.expectedErr.Error() {
 			t.Errorf(`test[%d] - Wrong error returned Expected="%v", got="%v"`,
@@ -1312,7 +1331,7 @@ func TestMakePrefixExpression(t *testing.T) {
 }
 
 func TestMakeInfixExpression(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		prefix      ast.IntegerLiteral
 		buf         TokenBuffer
@@ -1418,7 +1437,7 @@ func TestMakeInfixExpression(t *testing.T) {
 	// result String() : 1+(2*3)
 
 	for i, test := range tests {
-		exp, err := makeInfixExpression(test.buf, &test.prefix, LOWEST)
+		exp, err := p.makeInfixExpression(test.buf, &test.prefix, LOWEST, Token{})
 
 		if err != nil && test.expectedErr.Error() != err.Error() {
 			t.Fatalf("test[%d] - TestMakeInfixExpression() wrong error. Expected=%s, got=%s",
@@ -1432,7 +1451,7 @@ func TestMakeInfixExpression(t *testing.T) {
 }
 
 func TestParseInfixExpression(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		buf         TokenBuffer
 		left        ast.IntegerLiteral
@@ -1515,7 +1534,7 @@ func TestParseInfixExpression(t *testing.T) {
 	}
 
 	for i, test := range tests {
-		exp, err := parseInfixExpression(test.buf, &test.left)
+		exp, err := p.parseInfixExpression(test.buf, &test.left)
 
 		if err != nil && test.expectedErr.Error() != err.Error() {
 			t.Fatalf("test[%d] - TestMakeInfixExpression() wrong error. Expected=%s, got=%s",
@@ -1530,7 +1549,7 @@ func TestParseInfixExpression(t *testing.T) {
 }
 
 func TestParseGroupedExpression(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		buf         TokenBuffer
 		setupScope  setupScopeFn
@@ -1618,15 +1637,15 @@ func TestParseGroupedExpression(t *testing.T) {
 			setupScope: defaultSetupScopeFn,
 			expected:   "",
 			expectedErr: ExpectError{
-				Token{Rbrace, "{", 0, 0},
+				Token{Rbrace, "{", 0, 0, 0},
 				Rparen,
 			},
 		},
 	}
 
 	for i, test := range tests {
-		scope = test.setupScope()
-		exp, err := parseGroupedExpression(test.buf)
+		p.scope = test.setupScope()
+		exp, err := p.parseGroupedExpression(test.buf)
 
 		if err != nil && err.Error() != test.expectedErr.Error() {
 			t.Fatalf("test[%d] - TestParseGroupedExpression() wrong error.\n"+
@@ -1645,7 +1664,7 @@ func TestParseGroupedExpression(t *testing.T) {
 }
 
 func TestParseReturnStatement(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		buf         TokenBuffer
 		expected    string
@@ -1723,14 +1742,14 @@ func TestParseReturnStatement(t *testing.T) {
 			},
 			expected: "",
 			expectedErr: ExpectError{
-				Token{IntType, "int", 0, 0},
+				Token{IntType, "int", 0, 0, 0},
 				Return,
 			},
 		},
 	}
 
 	for i, test := range tests {
-		exp, err := parseReturnStatement(test.buf)
+		exp, err := p.parseReturnStatement(test.buf)
 
 		if err != nil && err.Error() != test.expectedErr.Error() {
 			t.Fatalf("test[%d] - TestParseReturnStatement() wrong error.\n"+
@@ -1749,6 +1768,7 @@ func TestParseReturnStatement(t *testing.T) {
 }
 
 func TestParsePrefixExpression(t *testing.T) {
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		tokenBuffer      TokenBuffer
 		expectedOperator string
@@ -1796,12 +1816,8 @@ func TestParsePrefixExpression(t *testing.T) {
 		},
 	}
 
-	prefixParseFnMap[Int] = parseIntegerLiteral
-	prefixParseFnMap[True] = parseBooleanLiteral
-	prefixParseFnMap[False] = parseBooleanLiteral
-
 	for i, tt := range tests {
-		exp, err := parsePrefixExpression(tt.tokenBuffer)
+		exp, err := p.parsePrefixExpression(tt.tokenBuffer)
 		if err != nil {
 			t.Errorf(`tests[%d] - Returned error is "%s"`,
 				i, err)
@@ -1825,7 +1841,7 @@ func TestParsePrefixExpression(t *testing.T) {
 }
 
 func TestParseCallExpression(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		setupScope  setupScopeFn
 		buf         TokenBuffer
@@ -1981,9 +1997,9 @@ func TestParseCallExpression(t *testing.T) {
 	}
 
 	for i, test := range tests {
-		scope = test.setupScope()
+		p.scope = test.setupScope()
 
-		exp, err := parseCallExpression(test.buf, test.function)
+		exp, err := p.parseCallExpression(test.buf, test.function)
 
 		if err != nil && err.Error() != test.expectedErr.Error() {
 			t.Fatalf("test[%d] - parseCallExpression() wrong error. Expected=%s, got=%s",
@@ -1997,7 +2013,7 @@ func TestParseCallExpression(t *testing.T) {
 }
 
 func TestParseCallArguments(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		buf         TokenBuffer
 		setupScope  setupScopeFn
@@ -2141,8 +2157,8 @@ func TestParseCallArguments(t *testing.T) {
 	}
 
 	for i, test := range tests {
-		scope = test.setupScope()
-		exp, err := parseCallArguments(test.buf)
+		p.scope = test.setupScope()
+		exp, err := p.parseCallArguments(test.buf)
 
 		if err != nil && err.Error() != test.expectedErr.Error() {
 			t.Fatalf("test[%d] - TestParseCallArguments() wrong error. Expected=%s, got=%s",
@@ -2161,7 +2177,7 @@ func TestParseCallArguments(t *testing.T) {
 }
 
 func TestParseAssignStatement(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		setupScopeFn
 		tokenBuffer           TokenBuffer
@@ -2443,10 +2459,10 @@ func TestParseAssignStatement(t *testing.T) {
 
 	for i, tt := range tests {
 		// setup
-		scope = tt.setupScopeFn()
+		p.scope = tt.setupScopeFn()
 
 		// exercise
-		exp, err := parseAssignStatement(tt.tokenBuffer)
+		exp, err := p.parseAssignStatement(tt.tokenBuffer)
 
 		// verify
 		if err != nil && err.Error() != tt.expectedErr.Error() {
@@ -2469,14 +2485,14 @@ func TestParseAssignStatement(t *testing.T) {
 				i, tt.expectedVal, exp.Value.String())
 		}
 
-		if !tt.chkScopeFn(scope) {
+		if !tt.chkScopeFn(p.scope) {
 			t.Errorf("test[%d] - updateScopeSymbol updates scope incorrectly", i)
 		}
 	}
 }
 
 func TestParseReassignStatement(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		buf         TokenBuffer
 		setupScope  setupScopeFn
@@ -2541,8 +2557,8 @@ func TestParseReassignStatement(t *testing.T) {
 	}
 
 	for i, test := range tests {
-		scope = test.setupScope()
-		stmt, err := parseReassignStatement(test.buf)
+		p.scope = test.setupScope()
+		stmt, err := p.parseReassignStatement(test.buf)
 		if err != nil && err.Error() != test.expectedErr.Error() {
 			t.Fatalf("test[%d] - parseReassignStatement() returns wrong error.\n"+
 				"Expected=%s\n"+
@@ -2560,7 +2576,7 @@ func TestParseReassignStatement(t *testing.T) {
 // TestParseExpression tests strings which combine prefix and
 // infix expression
 func TestParseExpression(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		buf         TokenBuffer
 		setupScope  setupScopeFn
@@ -2760,8 +2776,8 @@ func TestParseExpression(t *testing.T) {
 	}
 
 	for i, test := range tests {
-		scope = test.setupScope()
-		exp, err := parseExpression(test.buf, LOWEST)
+		p.scope = test.setupScope()
+		exp, err := p.parseExpression(test.buf, LOWEST)
 
 		if err != nil && err.Error() != test.expectedErr.Error() {
 			t.Fatalf("test[%d] - parseExpression() with wrong error. Expected=%s, got=%s",
@@ -2776,7 +2792,7 @@ func TestParseExpression(t *testing.T) {
 }
 
 func TestParseIfStatement(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		setupScopeFn
 		buf         TokenBuffer
@@ -2965,10 +2981,10 @@ func TestParseIfStatement(t *testing.T) {
 
 	for i, test := range tests {
 		// setup
-		scope = test.setupScopeFn()
+		p.scope = test.setupScopeFn()
 
 		// exercise
-		stmt, err := parseIfStatement(test.buf)
+		stmt, err := p.parseIfStatement(test.buf)
 
 		// verify
 		if err != nil && err.Error() != test.expectedErr.Error() {
@@ -2981,14 +2997,14 @@ func TestParseIfStatement(t *testing.T) {
 				i, test.expected, stmt.String())
 		}
 
-		if !test.chkScopeFn(scope) {
+		if !test.chkScopeFn(p.scope) {
 			t.Fatalf("test[%d] - updateScopeSymbol updates scope incorrectly", i)
 		}
 	}
 }
 
 func TestParseBlockStatement(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		setupScopeFn
 		buf         TokenBuffer
@@ -3178,10 +3194,10 @@ bool c = true`,
 
 	for i, test := range tests {
 		// setup
-		scope = test.setupScopeFn()
+		p.scope = test.setupScopeFn()
 
 		// exercise
-		exp, err := parseBlockStatement(test.buf)
+		exp, err := p.parseBlockStatement(test.buf)
 
 		// verify
 		if err != nil && err.Error() != test.expectedErr.Error() {
@@ -3194,14 +3210,14 @@ bool c = true`,
 				i, test.expected, exp.String())
 		}
 
-		if !test.chkScopeFn(scope) {
+		if !test.chkScopeFn(p.scope) {
 			t.Fatalf("test[%d] - updateScopeSymbol updates scope incorrectly", i)
 		}
 	}
 }
 
 func TestParseStatement(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		setupScopeFn
 		buf          TokenBuffer
@@ -3604,9 +3620,9 @@ func TestParseStatement(t *testing.T) {
 				},
 				0,
 			},
-			expectedErr: ExpectError{
+			expectedErr: Error{
 				Token{Type: Int},
-				Ident,
+				errNotStatement,
 			},
 			expectedStmt: ``,
 			chkScopeFn:   defaultChkScopeFn,
@@ -3881,9 +3897,9 @@ func TestParseStatement(t *testing.T) {
 				},
 				0,
 			},
-			expectedErr: ExpectError{
+			expectedErr: Error{
 				Token{Type: Int},
-				Ident,
+				errNotStatement,
 			},
 			expectedStmt: ``,
 			chkScopeFn:   defaultChkScopeFn,
@@ -3892,10 +3908,10 @@ func TestParseStatement(t *testing.T) {
 
 	for i, test := range tests {
 		// setup
-		scope = test.setupScopeFn()
+		p.scope = test.setupScopeFn()
 
 		// exercise
-		stmt, err := parseStatement(test.buf)
+		stmt, err := p.parseStatement(test.buf)
 
 		// verify
 		if err != nil && err.Error() != test.expectedErr.Error() {
@@ -3909,14 +3925,14 @@ func TestParseStatement(t *testing.T) {
 				i, test.expectedStmt, stmt.String())
 		}
 
-		if !test.chkScopeFn(scope) {
+		if !test.chkScopeFn(p.scope) {
 			t.Errorf("test[%d] - updateScopeSymbol updates scope incorrectly", i)
 		}
 	}
 }
 
 func TestParseExpressionStatement(t *testing.T) {
-	initParseFnMap()
+	p := newParser(Limits{}, nil)
 
 	tests := []struct {
 		buf          TokenBuffer
@@ -4008,7 +4024,7 @@ func TestParseExpressionStatement(t *testing.T) {
 			},
 			"",
 			ExpectError{
-				Token{IntType, "int", 0, 0},
+				Token{IntType, "int", 0, 0, 0},
 				Rparen,
 			},
 		},
@@ -4024,9 +4040,9 @@ func TestParseExpressionStatement(t *testing.T) {
 			},
 			defaultSetupScopeFn,
 			"",
-			ExpectError{
+			Error{
 				Token{Type: Int},
-				Ident,
+				errNotStatement,
 			},
 		},
 		{
@@ -4044,16 +4060,16 @@ func TestParseExpressionStatement(t *testing.T) {
 				return scope
 			},
 			"",
-			ExpectError{
-				Token{Rparen, "}", 0, 0},
-				Lparen,
+			Error{
+				Token{Type: Ident, Val: "add"},
+				errNotStatement,
 			},
 		},
 	}
 
 	for i, test := range tests {
-		scope = test.setupScope()
-		stmt, err := parseExpressionStatement(test.buf)
+		p.scope = test.setupScope()
+		stmt, err := p.parseExpressionStatement(test.buf)
 		if stmt != nil && stmt.String() != test.expectedStmt {
 			t.Fatalf("test[%d] - TestParseFunctionStatement wrong answer.\n"+
 				"Expected= %s\n"+
@@ -4069,22 +4085,23 @@ func TestParseExpressionStatement(t *testing.T) {
 }
 
 func TestEnterLeaveScope(t *testing.T) {
+	p := newParser(Limits{}, nil)
 	// scope is global variable which defined in parser.go
-	scope = symbol.NewScope()
-	scope.Set("foo", &symbol.String{Name: &ast.Identifier{Name: "foo"}})
+	p.scope = symbol.NewScope()
+	p.scope.Set("foo", &symbol.String{Name: &ast.Identifier{Name: "foo"}})
 
-	enterScope()
+	p.enterScope()
 
-	scope.Set("bar", &symbol.String{Name: &ast.Identifier{Name: "bar"}})
+	p.scope.Set("bar", &symbol.String{Name: &ast.Identifier{Name: "bar"}})
 
-	if scope.Get("foo") == nil {
+	if p.scope.Get("foo") == nil {
 		t.Errorf("scope should have foo symbol, because we're in the inner scope")
 	}
 
-	leaveScope()
+	p.leaveScope()
 
 	// test whether inner exist
-	inner := scope.GetInner()
+	inner := p.scope.GetInner()
 	if len(inner) != 1 {
 		t.Errorf("scope should have 1 inner scope, but have %d", len(inner))
 	}
@@ -4093,20 +4110,21 @@ func TestEnterLeaveScope(t *testing.T) {
 		t.Errorf("scope should have bar symbol, because we're in the inner scope")
 	}
 
-	scope.Set("baz", &symbol.String{Name: &ast.Identifier{Name: "baz"}})
+	p.scope.Set("baz", &symbol.String{Name: &ast.Identifier{Name: "baz"}})
 
-	if scope.Get("bar") != nil {
+	if p.scope.Get("bar") != nil {
 		t.Errorf("scope should NOT have \"bar\" symbol, because we're in the outer scope")
 	}
-	if scope.Get("foo") == nil {
+	if p.scope.Get("foo") == nil {
 		t.Errorf("scope should have \"foo\" symbol, because we're in the outer scope")
 	}
-	if scope.Get("baz") == nil {
+	if p.scope.Get("baz") == nil {
 		t.Errorf("scope should have \"baz\" symbol, because we're in the outer scope")
 	}
 }
 
 func TestUpdateScopeSymbol(t *testing.T) {
+	p := newParser(Limits{}, nil)
 	tests := []struct {
 		setupScopeFn
 		ident       Token
@@ -4233,10 +4251,10 @@ func TestUpdateScopeSymbol(t *testing.T) {
 
 	for i, tt := range tests {
 		// setup
-		scope = tt.setupScopeFn()
+		p.scope = tt.setupScopeFn()
 
 		// exercise
-		err := updateScopeSymbol(tt.ident, tt.keyword)
+		err := p.updateScopeSymbol(tt.ident, tt.keyword)
 
 		// verify
 		if err != nil && err.Error() != tt.expectedErr.Error() {
@@ -4244,8 +4262,33 @@ func TestUpdateScopeSymbol(t *testing.T) {
 				i, err.Error(), tt.expectedErr.Error())
 		}
 		// verify
-		if ok := tt.chkScope(scope); !ok {
+		if ok := tt.chkScope(p.scope); !ok {
 			t.Errorf("test[%d] - updateScopeSymbol updates scope incorrectly", i)
 		}
 	}
 }
+
+func TestRecordFunctionSignature(t *testing.T) {
+	p := newParser(Limits{}, nil)
+	p.scope = symbol.NewScope()
+	p.scope.Set("add", &symbol.Function{Name: "add"})
+
+	p.recordFunctionSignature(Token{Type: Ident, Val: "add"}, &ast.FunctionLiteral{
+		Name: &ast.Identifier{Name: "add"},
+		Parameters: []*ast.ParameterLiteral{
+			{Identifier: &ast.Identifier{Name: "a"}, Type: ast.IntType},
+			{Identifier: &ast.Identifier{Name: "b"}, Type: ast.StringType},
+		},
+		ReturnType: ast.BoolType,
+	})
+
+	fn, ok := p.scope.Get("add").(*symbol.Function)
+	if !ok {
+		t.Fatalf("scope should have add function symbol")
+	}
+
+	if sig := fn.Signature(); sig != "add(int, string) bool" {
+		t.Errorf("recordFunctionSignature records wrong signature. expected=%s, got=%s",
+			"add(int, string) bool", sig)
+	}
+}
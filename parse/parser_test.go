@@ -18,6 +18,9 @@ package parse_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"strings"
 	"testing"
 	"text/template"
 
@@ -1312,7 +1315,7 @@ func TestIfElseStatement(t *testing.T) {
 				},
 			},
 			expectedErr: parse.DupSymError{
-				Source: parse.Token{Type: parse.Ident, Val: "a", Line: 6, Column: 15}},
+				Source: parse.Token{Type: parse.Ident, Val: "a", Line: 6, Column: 15, Offset: 118}},
 		},
 	}
 
@@ -1730,3 +1733,1110 @@ func testReassignStatement(t *testing.T, stmt *ast.ReassignStatement, ident ast.
 			value.String(), stmt.Value.String())
 	}
 }
+
+func TestParseContext(t *testing.T) {
+	input := `
+contract {
+	func foo() int {
+		return 1
+	}
+}`
+
+	contract, err := parse.ParseContext(context.Background(),
+		parse.NewTokenBuffer(parse.NewLexer(input)))
+	if err != nil {
+		t.Fatalf("ParseContext() returns unexpected error: %s", err)
+	}
+	if len(contract.Functions) != 1 {
+		t.Fatalf("wrong number of functions. expected=1, got=%d", len(contract.Functions))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = parse.ParseContext(ctx, parse.NewTokenBuffer(parse.NewLexer(input)))
+	if err != context.Canceled {
+		t.Errorf("ParseContext() with cancelled context wrong error. expected=%v, got=%v", context.Canceled, err)
+	}
+}
+
+func TestParseLimited(t *testing.T) {
+	input := `
+contract {
+	func foo() int {
+		int a = 1 + 2
+		return a
+	}
+	func bar() int {
+		return 1
+	}
+}`
+
+	tests := []struct {
+		limits      parse.Limits
+		expectedErr string
+	}{
+		{
+			limits:      parse.Limits{},
+			expectedErr: "",
+		},
+		{
+			limits:      parse.Limits{MaxFunctions: 2, MaxNodes: 8},
+			expectedErr: "",
+		},
+		{
+			limits:      parse.Limits{MaxFunctions: 1},
+			expectedErr: "[line 6, column 5] number of functions exceeds limit 1",
+		},
+		{
+			limits:      parse.Limits{MaxNodes: 3},
+			expectedErr: "[line 3, column 16] number of nodes exceeds limit 3",
+		},
+	}
+
+	for i, test := range tests {
+		_, err := parse.ParseLimited(context.Background(),
+			parse.NewTokenBuffer(parse.NewLexer(input)), test.limits)
+
+		if test.expectedErr == "" {
+			if err != nil {
+				t.Errorf("test[%d] - ParseLimited() returns unexpected error: %s", i, err)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("test[%d] - ParseLimited() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+		}
+		if !errors.Is(err, parse.ErrLimitExceeded) {
+			t.Errorf("test[%d] - ParseLimited() error should match ErrLimitExceeded", i)
+		}
+	}
+}
+
+func TestMultipleReturnValues(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	func pair() (int, bool) {
+		return 1, true
+	}
+	func foo() int {
+		int a, bool b = pair()
+		return a
+	}
+}`,
+			expected: `func pair() (int, bool) {
+return 1, true
+}
+func foo() int {
+int a, bool b = function pair(  )
+return a
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo() (int) {
+		return 1
+	}
+}`,
+			expectedErr: "[line 2, column 19] [LBRACE] parenthesized return type needs at least two types",
+		},
+		{
+			input: `
+contract {
+	func foo() (int, void) {
+		return 1
+	}
+}`,
+			expectedErr: "[line 2, column 22] [IDENT] invalid function return type",
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		int a, b = 1, 2
+		return a
+	}
+}`,
+			expectedErr: "[line 3, column 10] [IDENT] data structure of variable missed",
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		int a, bool a = 1, true
+		return a
+	}
+}`,
+			expectedErr: "[line 3, column 15] symbol [a] already exist",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+
+		fns := []string{}
+		for _, fn := range contract.Functions {
+			fns = append(fns, fn.String())
+		}
+		if result := strings.Join(fns, "\n"); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	func foo() {
+		int x = 0
+		for (x < 10) {
+			x = x + 1
+		}
+		for (int i = 0; i < 3; i = i + 1) {
+			x = x + i
+		}
+		for (x = 0; ; ) {
+			return
+		}
+	}
+}`,
+			expected: `func foo() void {
+int x = 0
+for ( (x < 10) ) { x = (x + 1) }
+for ( int i = 0; (i < 3); i = (i + 1) ) { x = (x + i) }
+for ( x = 0; ;  ) { return }
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		for (int i = 0; i < 3; i = i + 1) {
+		}
+		i = 1
+	}
+}`,
+			expectedErr: "[line 5, column 3] symbol [i] is not exist",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		int x = 0
+		for (x < 10; x = x + 1) {
+		}
+	}
+}`,
+			expectedErr: "[line 4, column 14] Expected [RPAREN], but got [SEMICOLON]",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.Functions[0].String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestDoWhileStatement(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	func foo() {
+		int x = 0
+		do {
+			x = x + 1
+		} while (x < 10)
+	}
+}`,
+			expected: `func foo() void {
+int x = 0
+do { x = (x + 1) } while ( (x < 10) )
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		do {
+			int y = 1
+		} while (true)
+		y = 2
+	}
+}`,
+			expectedErr: "[line 6, column 3] symbol [y] is not exist",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		do {
+		} (true)
+	}
+}`,
+			expectedErr: "[line 4, column 5] Expected [WHILE], but got [LPAREN]",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.Functions[0].String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestConstStatement(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	const int FEE = 100
+	const int DOUBLE_FEE = FEE * 2
+	func foo() int {
+		return DOUBLE_FEE
+	}
+}`,
+			expected: `
+contract {
+const int FEE = 100
+const int DOUBLE_FEE = (FEE * 2)
+func foo() int {
+return DOUBLE_FEE
+}
+}`,
+		},
+		{
+			input: `
+contract {
+	const int FEE = 100
+	func foo() {
+		FEE = 1
+	}
+}`,
+			expectedErr: "[line 4, column 5] cannot assign to constant [FEE]",
+		},
+		{
+			input: `
+contract {
+	const int FEE = 100
+	func foo() {
+		for (FEE = 0; FEE < 3; ) {
+		}
+	}
+}`,
+			expectedErr: "[line 4, column 10] cannot assign to constant [FEE]",
+		},
+		{
+			input: `
+contract {
+	const int FEE = 100
+	func foo() {
+		int FEE = 1
+	}
+}`,
+			expectedErr: "[line 4, column 9] symbol [FEE] already exist",
+		},
+		{
+			input: `
+contract {
+	const FEE = 100
+}`,
+			expectedErr: "[line 2, column 10] [IDENT] constant must be int, uint, bool or string",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestConstStatement_errorKind(t *testing.T) {
+	input := `
+contract {
+	const int FEE = 100
+	func foo() {
+		FEE = 1
+	}
+}`
+
+	_, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(input)))
+	if !errors.Is(err, parse.ErrSyntax) {
+		t.Errorf("Parse() error should match ErrSyntax, got=%v", err)
+	}
+	if _, ok := err.(parse.ConstAssignError); !ok {
+		t.Errorf("Parse() error should be ConstAssignError, got=%T", err)
+	}
+}
+
+func TestBytesStatement(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	func foo(data bytes) int {
+		bytes hash = 0x"deadBEEF"
+		bytes empty = 0x""
+		return hash[0] + data[len(data) - 1] * 2
+	}
+}`,
+			expected: `func foo(Parameter : (Identifier: data, Type: bytes)) int {
+bytes hash = 0x"deadbeef"
+bytes empty = 0x""
+return ((hash[0]) + ((data[(function len( data ) - 1)]) * 2))
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		bytes b = 0x"abc"
+	}
+}`,
+			expectedErr: `[line 3, column 19] [BYTES] encoding/hex: odd length hex string`,
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		bytes b = 0x"00"
+		int x = b[0
+	}
+}`,
+			expectedErr: "[line 5, column 0] Expected [RBRACKET], but got [SEMICOLON]",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.Functions[0].String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestAddressLiteral(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	func foo(owner address) bool {
+		address admin = 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed
+		return owner == admin
+	}
+}`,
+			expected: `func foo(Parameter : (Identifier: owner, Type: address)) bool {
+address admin = 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed
+return (owner == admin)
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		address admin = 0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed
+	}
+}`,
+			expectedErr: "[line 3, column 61] [INT] invalid address [0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed] - wrong checksum",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		address admin = 0X5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed
+	}
+}`,
+			expectedErr: "[line 3, column 61] [INT] invalid address [0X5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed] - missing 0x prefix",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.Functions[0].String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestSizedIntegerStatement(t *testing.T) {
+	input := `
+contract {
+	func foo(a int8, b int16) int32 {
+		int32 c = a + b
+		int64 d = c
+		return c
+	}
+}`
+	expected := `func foo(Parameter : (Identifier: a, Type: int8), Parameter : (Identifier: b, Type: int16)) int32 {
+int32 c = (a + b)
+int d = c
+return c
+}`
+
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(input)))
+	if err != nil {
+		t.Fatalf("Parse() returns unexpected error: %s", err)
+	}
+	if result := contract.Functions[0].String(); result != expected {
+		t.Errorf("Parse() wrong result.\nexpected=%s\ngot=%s", expected, result)
+	}
+}
+
+func TestSelectorExpression(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	func foo(owner address) bool {
+		address sender = msg.sender
+		return msg.sender == owner
+	}
+}`,
+			expected: `func foo(Parameter : (Identifier: owner, Type: address)) bool {
+address sender = msg.sender
+return (msg.sender == owner)
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo() bool {
+		return (1).sender
+	}
+}`,
+			expectedErr: "[line 3, column 13] [DOT] cannot select field of 1",
+		},
+		{
+			input: `
+contract {
+	func foo() bool {
+		return msg.1
+	}
+}`,
+			expectedErr: "[line 3, column 15] Expected [IDENT], but got [INT]",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.Functions[0].String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestModifierLiteral(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	modifier positive {
+		require(FEE > 0, "fee")
+		_
+	}
+	modifier counted {
+		int calls = 1
+		if (calls > 0) {
+			_
+		}
+	}
+	func foo(a int) positive counted int {
+		return a + FEE
+	}
+}`,
+			expected: `func foo(Parameter : (Identifier: a, Type: int)) positive counted int {
+function require( (FEE > 0), "fee" )
+int calls = 1
+if ( (calls > 0) ) { return (a + FEE) }
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo() bar int {
+		return 1
+	}
+}`,
+			expectedErr: "[line 2, column 15] [IDENT] undefined modifier [bar]",
+		},
+		{
+			input: `
+contract {
+	modifier bar {
+		int a = 1
+	}
+}`,
+			expectedErr: "[line 2, column 13] [IDENT] modifier [bar] has no placeholder _",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		_
+	}
+}`,
+			expectedErr: "[line 3, column 4] [IDENT] placeholder _ is only allowed in modifier",
+		},
+		{
+			input: `
+contract {
+	modifier withx {
+		int x = 10
+		_
+		require(x == 10, "clob")
+	}
+	func g(a int) withx {
+		int x = a
+	}
+}`,
+			expectedErr: "[line 7, column 20] [IDENT] local [x] of modifier [withx] is also declared in function [g]",
+		},
+		{
+			input: `
+contract {
+	modifier withx {
+		int x = 10
+		_
+	}
+	modifier withy {
+		if (true) {
+			int x = 1
+		}
+		_
+	}
+	func g(a int) withx withy {
+		int y = a
+	}
+}`,
+			expectedErr: "[line 12, column 26] [IDENT] local [x] of modifier [withy] is also declared in modifier [withx]",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.Functions[0].String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestBlankIdentifier(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	func foo(_ int, _ int, a int) int {
+		int _ = a
+		bool _ = true
+		_ = a * 2
+		return a
+	}
+}`,
+			expected: `func foo(Parameter : (Identifier: _, Type: int), Parameter : (Identifier: _, Type: int), Parameter : (Identifier: a, Type: int)) int {
+int _ = a
+bool _ = true
+_ = (a * 2)
+return a
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		_ = 1
+		b = 2
+	}
+}`,
+			expectedErr: "[line 4, column 3] symbol [b] is not exist",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.Functions[0].String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestDefaultParameter(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	func foo(a int, b int = 10, c bool = FEE > 1) int {
+		return a + b
+	}
+}`,
+			expected: `func foo(Parameter : (Identifier: a, Type: int), Parameter : (Identifier: b, Type: int, Default: 10), Parameter : (Identifier: c, Type: bool, Default: (FEE > 1))) int {
+return (a + b)
+}`,
+		},
+		{
+			input: `
+contract {
+	func foo(a int = 1, b int) int {
+		return a + b
+	}
+}`,
+			expectedErr: "[line 2, column 22] [IDENT] parameter [b] without default value follows parameter with default value",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.Functions[0].String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestInterface(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+interface Token {
+	func balance(owner int) int
+	func transfer(to int, amount int) (bool, int)
+	func burn()
+}
+
+interface Ownable {
+}
+
+contract implements Token, Ownable {
+	func burn() {
+	}
+}`,
+			expected: `
+interface Token {
+func balance(Parameter : (Identifier: owner, Type: int)) int
+func transfer(Parameter : (Identifier: to, Type: int), Parameter : (Identifier: amount, Type: int)) (bool, int)
+func burn() void
+}
+interface Ownable {
+}
+contract implements Token, Ownable {
+func burn() void {
+
+}
+}`,
+		},
+		{
+			input: `
+interface Token {
+	func balance(owner int) int {
+		return 1
+	}
+}
+contract {
+}`,
+			expectedErr: "[line 2, column 30] Expected [RBRACE], but got [LBRACE]",
+		},
+		{
+			input: `
+interface Token {
+}
+interface Token {
+}
+contract {
+}`,
+			expectedErr: "[line 3, column 15] [IDENT] interface [Token] already exist",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestCustomError(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	error Empty()
+	error InsufficientBalance(needed int, got int)
+	func withdraw(amount int) int {
+		if (amount > 10) {
+			revert InsufficientBalance(amount, 10)
+		}
+		revert Empty()
+	}
+}`,
+			expected: `
+contract {
+error Empty()
+error InsufficientBalance(Parameter : (Identifier: needed, Type: int), Parameter : (Identifier: got, Type: int))
+func withdraw(Parameter : (Identifier: amount, Type: int)) int {
+if ( (amount > 10) ) { revert InsufficientBalance( amount, 10 ) }
+revert Empty(  )
+}
+}`,
+		},
+		{
+			input: `
+contract {
+	error Invalid(code int = 1)
+}`,
+			expectedErr: "[line 2, column 14] [IDENT] parameter [code] of error can't have default value",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		revert 1
+	}
+}`,
+			expectedErr: "[line 3, column 11] Expected [IDENT], but got [INT]",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestEnum(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	enum Status { Pending, Active, Closed }
+	enum Side {
+		Buy,
+		Sell,
+	}
+	func isActive(s int) bool {
+		return s == Status.Active
+	}
+}`,
+			expected: `
+contract {
+enum Status { Pending, Active, Closed }
+enum Side { Buy, Sell }
+func isActive(Parameter : (Identifier: s, Type: int)) bool {
+return (s == Status.Active)
+}
+}`,
+		},
+		{
+			input: `
+contract {
+	enum Status { Pending, Active, Pending }
+}`,
+			expectedErr: "[line 2, column 39] [IDENT] member [Pending] of enum already exist",
+		},
+		{
+			input: `
+contract {
+	enum Status { }
+}`,
+			expectedErr: "[line 2, column 12] [IDENT] enum [Status] has no member",
+		},
+		{
+			input: `
+contract {
+	const int Status = 1
+	enum Status { Pending }
+}`,
+			expectedErr: "[line 3, column 12] symbol [Status] already exist",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
+
+func TestIncDecStatement(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	func count(n int) int {
+		int c = 0
+		for (int i = 0; i < n; i++) {
+			c++
+		}
+		c--
+		return c
+	}
+}`,
+			expected: `
+contract {
+func count(Parameter : (Identifier: n, Type: int)) int {
+int c = 0
+for ( int i = 0; (i < n); i = (i + 1) ) { c = (c + 1) }
+c = (c - 1)
+return c
+}
+}`,
+		},
+		{
+			input: `
+contract {
+	const int MAX = 1
+	func foo() {
+		MAX++
+	}
+}`,
+			expectedErr: "[line 4, column 5] cannot assign to constant [MAX]",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		1()
+	}
+}`,
+			expectedErr: "[line 3, column 3] [INT] expression is not a statement, expected call, assignment, increment or decrement",
+		},
+		{
+			input: `
+contract {
+	func foo(a int) {
+		a + 1
+	}
+}`,
+			expectedErr: "[line 3, column 3] [IDENT] expression is not a statement, expected call, assignment, increment or decrement",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+			continue
+		}
+		if result := contract.String(); result != test.expected {
+			t.Errorf("test[%d] - Parse() wrong result.\nexpected=%s\ngot=%s", i, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DE-labtory/koa/ast"
+)
+
+// Version is the version of koa language which parser accepts,
+// file declares versions it can be compiled with by pragma
+//
+//	pragma koa >=0.2 <0.4
+const Version = "0.0.1"
+
+// versionOperators are the comparisons which version constraint
+// of pragma can use
+var versionOperators = map[TokenType]func(cmp int) bool{
+	LT:     func(cmp int) bool { return cmp < 0 },
+	LTE:    func(cmp int) bool { return cmp <= 0 },
+	GT:     func(cmp int) bool { return cmp > 0 },
+	GTE:    func(cmp int) bool { return cmp >= 0 },
+	EQ:     func(cmp int) bool { return cmp == 0 },
+	NOT_EQ: func(cmp int) bool { return cmp != 0 },
+}
+
+// experimentalFeatures are language features which are still under
+// development, mapped to the keywords starting their syntax. Feature
+// is registered while it ships incrementally, and removed once it is
+// stable, so that default builds don't depend on it.
+var (
+	experimentalMu       sync.RWMutex
+	experimentalFeatures = map[string][]TokenType{}
+)
+
+// RegisterExperiment registers experimental feature, so that syntax
+// starting with one of keywords is parsed only in the file which
+// enables the feature, e.g. after
+//
+//	parse.RegisterExperiment("loops", parse.For, parse.Do)
+//
+// for and do-while statements need
+//
+//	pragma experimental loops
+//
+// It is meant to be called from init, before parsing. It panics if
+// feature is already registered or keyword is gated by other feature.
+func RegisterExperiment(feature string, keywords ...TokenType) {
+	experimentalMu.Lock()
+	defer experimentalMu.Unlock()
+
+	if _, ok := experimentalFeatures[feature]; ok {
+		panic(fmt.Sprintf("parse: experimental feature %s registered twice", feature))
+	}
+	for f, gated := range experimentalFeatures {
+		for _, g := range gated {
+			for _, k := range keywords {
+				if g == k {
+					panic(fmt.Sprintf("parse: keyword %s is already gated by %s", TokenTypeMap[k], f))
+				}
+			}
+		}
+	}
+	experimentalFeatures[feature] = keywords
+}
+
+// UnregisterExperiment removes experimental feature, after that its
+// syntax is parsed without pragma
+func UnregisterExperiment(feature string) {
+	experimentalMu.Lock()
+	defer experimentalMu.Unlock()
+
+	delete(experimentalFeatures, feature)
+}
+
+// isExperiment returns whether feature is registered
+func isExperiment(feature string) bool {
+	experimentalMu.RLock()
+	defer experimentalMu.RUnlock()
+
+	_, ok := experimentalFeatures[feature]
+	return ok
+}
+
+// gatedKeywords returns the feature which gates each keyword
+func gatedKeywords() map[TokenType]string {
+	experimentalMu.RLock()
+	defer experimentalMu.RUnlock()
+
+	gated := make(map[TokenType]string)
+	for f, keywords := range experimentalFeatures {
+		for _, k := range keywords {
+			gated[k] = f
+		}
+	}
+	return gated
+}
+
+// parsePragmas parse pragma directives at the start of file, which are
+// version constraint, experimental feature or erc165, e.g.
+//
+//	pragma koa >=0.2 <0.4
+//	pragma experimental generics
+//	pragma erc165
+//
+// erc165 makes compiler generate supportsInterface for contract.
+func (p *parser) parsePragmas(buf TokenBuffer, contract *ast.Contract) error {
+	for curTokenIs(buf, Pragma) {
+		pragma := buf.Read()
+
+		token := buf.Read()
+		if token.Type != Ident {
+			return Error{token, "pragma should be followed by koa, experimental or erc165"}
+		}
+
+		var err error
+		switch token.Val {
+		case "koa":
+			err = parseVersionPragma(buf, pragma)
+		case "experimental":
+			err = p.parseExperimentalPragma(buf)
+		case "erc165":
+			consumeSemi(buf)
+			contract.SupportsInterface = true
+		default:
+			err = Error{token, "pragma should be followed by koa, experimental or erc165"}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseExperimentalPragma enables experimental feature
+func (p *parser) parseExperimentalPragma(buf TokenBuffer) error {
+	token := buf.Read()
+	if token.Type != Ident {
+		return ExpectError{token, Ident}
+	}
+	if !isExperiment(token.Val) {
+		return Error{token, fmt.Sprintf("unknown experimental feature [%s]", token.Val)}
+	}
+	consumeSemi(buf)
+
+	p.experiments[token.Val] = true
+	return nil
+}
+
+// checkGated fails if token starts the syntax of experimental
+// feature which file doesn't enable
+func (p *parser) checkGated(token Token) error {
+	feature, ok := p.gated[token.Type]
+	if !ok {
+		return nil
+	}
+	return p.requireExperiment(token, feature)
+}
+
+// requireExperiment fails unless experimental feature is enabled,
+// parser calls it when it meets the syntax of the feature
+func (p *parser) requireExperiment(token Token, feature string) error {
+	if p.experiments[feature] {
+		return nil
+	}
+	return Error{token, fmt.Sprintf("%s is experimental, enable it with pragma experimental %s", feature, feature)}
+}
+
+// parseVersionPragma fails unless Version satisfies every constraint
+// of pragma. File without version pragma is compiled with any version.
+func parseVersionPragma(buf TokenBuffer, pragma Token) error {
+	constraints := make([]string, 0)
+	satisfied := true
+	for !curTokenIs(buf, Semicolon) && !curTokenIs(buf, Eof) {
+		operator := buf.Read()
+		compare, ok := versionOperators[operator.Type]
+		if !ok {
+			return Error{operator, "version constraint should start with comparison"}
+		}
+
+		version, err := parseVersion(buf)
+		if err != nil {
+			return err
+		}
+
+		constraints = append(constraints, operator.Val+version)
+		if !compare(compareVersion(Version, version)) {
+			satisfied = false
+		}
+	}
+	consumeSemi(buf)
+
+	if len(constraints) == 0 {
+		return Error{pragma, "pragma has no version constraint"}
+	}
+	if !satisfied {
+		return Error{pragma, fmt.Sprintf("file requires koa %s, but compiler is %s",
+			strings.Join(constraints, " "), Version)}
+	}
+	return nil
+}
+
+// parseVersion parse version of dotted numbers, e.g. 0.2.1
+func parseVersion(buf TokenBuffer) (string, error) {
+	token := buf.Read()
+	if token.Type != Int {
+		return "", ExpectError{token, Int}
+	}
+
+	version := token.Val
+	for curTokenIs(buf, Dot) {
+		buf.Read()
+
+		token := buf.Read()
+		if token.Type != Int {
+			return "", ExpectError{token, Int}
+		}
+		version += "." + token.Val
+	}
+
+	return version, nil
+}
+
+// compareVersion returns -1, 0 or 1 as version a is older than,
+// same with or newer than b. Missing numbers are zero, so 0.2 is
+// the same with 0.2.0.
+func compareVersion(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for len(as) < len(bs) {
+		as = append(as, "0")
+	}
+	for len(bs) < len(as) {
+		bs = append(bs, "0")
+	}
+
+	for i := range as {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
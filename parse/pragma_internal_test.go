@@ -0,0 +1,101 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse
+
+import (
+	"testing"
+)
+
+func TestExperimentalPragma(t *testing.T) {
+	RegisterExperiment("generics")
+	defer UnregisterExperiment("generics")
+
+	tests := []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input: `pragma koa >=0.0.1
+pragma experimental generics
+contract {
+}`,
+		},
+		{
+			input: `pragma experimental asm
+contract {
+}`,
+			expectedErr: "[line 0, column 24] [IDENT] unknown experimental feature [asm]",
+		},
+	}
+
+	for i, test := range tests {
+		_, err := Parse(NewTokenBuffer(NewLexer(test.input)))
+		if test.expectedErr == "" && err != nil {
+			t.Errorf("test[%d] - Parse() returns unexpected error: %s", i, err)
+		}
+		if test.expectedErr != "" && (err == nil || err.Error() != test.expectedErr) {
+			t.Errorf("test[%d] - Parse() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+		}
+	}
+}
+
+func TestRequireExperiment(t *testing.T) {
+	p := newParser(Limits{}, nil)
+	token := Token{Type: Ident, Val: "T", Line: 1, Column: 2}
+
+	expected := "[line 1, column 2] [IDENT] generics is experimental, enable it with pragma experimental generics"
+	if err := p.requireExperiment(token, "generics"); err == nil || err.Error() != expected {
+		t.Errorf("requireExperiment() wrong error. expected=%s, got=%v", expected, err)
+	}
+
+	p.experiments["generics"] = true
+	if err := p.requireExperiment(token, "generics"); err != nil {
+		t.Errorf("requireExperiment() returns unexpected error: %s", err)
+	}
+
+	if err := newParser(Limits{}, nil).requireExperiment(token, "generics"); err == nil {
+		t.Errorf("requireExperiment() of new parser should fail, experiments aren't shared")
+	}
+}
+
+func TestRegisterExperiment_panics(t *testing.T) {
+	RegisterExperiment("loops", Do)
+	defer UnregisterExperiment("loops")
+
+	tests := []struct {
+		feature  string
+		keywords []TokenType
+	}{
+		{feature: "loops"},
+		{feature: "loops2", keywords: []TokenType{For, Do}},
+	}
+
+	for i, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("test[%d] - RegisterExperiment() should panic", i)
+				}
+			}()
+			RegisterExperiment(test.feature, test.keywords...)
+		}()
+	}
+
+	if gated := gatedKeywords(); len(gated) != 1 || gated[Do] != "loops" {
+		t.Errorf("gatedKeywords() wrong result. expected=map[DO:loops], got=%v", gated)
+	}
+}
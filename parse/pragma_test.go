@@ -0,0 +1,211 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DE-labtory/koa/parse"
+)
+
+func TestPragma(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input: `pragma koa >=0.0.1 <0.1
+contract {
+}`,
+		},
+		{
+			input: `pragma koa == 0.0.1.0
+contract {
+}`,
+		},
+		{
+			input: `pragma koa >=0.2 <0.4
+contract {
+}`,
+			expectedErr: "[line 0, column 6] [PRAGMA] file requires koa >=0.2 <0.4, but compiler is 0.0.1",
+		},
+		{
+			input: `pragma koa != 0.0.1
+contract {
+}`,
+			expectedErr: "[line 0, column 6] [PRAGMA] file requires koa !=0.0.1, but compiler is 0.0.1",
+		},
+		{
+			input: `pragma erc165
+contract {
+}`,
+		},
+		{
+			input: `pragma solidity ^0.5.0
+contract {
+}`,
+			expectedErr: "[line 0, column 15] [IDENT] pragma should be followed by koa, experimental or erc165",
+		},
+		{
+			input: `pragma koa 0.2
+contract {
+}`,
+			expectedErr: "[line 0, column 12] [INT] version constraint should start with comparison",
+		},
+		{
+			input: `pragma koa
+contract {
+}`,
+			expectedErr: "[line 0, column 6] [PRAGMA] pragma has no version constraint",
+		},
+		{
+			input: `import "lib.koa"
+contract {
+}`,
+			expectedErr: "[lib.koa] [line 0, column 6] [PRAGMA] file requires koa >=1.0, but compiler is 0.0.1",
+		},
+	}
+
+	resolver := mapResolver{
+		"lib.koa": `pragma koa >=1.0`,
+	}
+
+	for i, test := range tests {
+		_, err := parse.ParseImports(context.Background(),
+			parse.NewTokenBuffer(parse.NewLexer(test.input)), parse.Limits{}, resolver)
+		if test.expectedErr == "" {
+			if err != nil {
+				t.Errorf("test[%d] - ParseImports() returns unexpected error: %s", i, err)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("test[%d] - ParseImports() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+		}
+		if err != nil && !errors.Is(err, parse.ErrSyntax) {
+			t.Errorf("test[%d] - ParseImports() error %v is not syntax error", i, err)
+		}
+	}
+}
+
+func TestPragma_erc165(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{
+			input: `contract {
+}`,
+			expected: false,
+		},
+		{
+			input: `pragma koa >=0.0.1
+pragma erc165
+contract {
+}`,
+			expected: true,
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if err != nil {
+			t.Fatalf("test[%d] - Parse() returns unexpected error: %s", i, err)
+		}
+		if contract.SupportsInterface != test.expected {
+			t.Errorf("test[%d] - Parse() wrong SupportsInterface. expected=%t, got=%t", i, test.expected, contract.SupportsInterface)
+		}
+	}
+}
+
+func TestRegisterExperiment(t *testing.T) {
+	parse.RegisterExperiment("loops", parse.Do)
+	defer parse.UnregisterExperiment("loops")
+
+	tests := []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input: `pragma experimental loops
+contract {
+	func foo() {
+		int x = 0
+		do {
+			x = x + 1
+		} while (x < 10)
+	}
+}`,
+		},
+		{
+			input: `contract {
+	func foo() {
+		int x = 0
+		do {
+			x = x + 1
+		} while (x < 10)
+	}
+}`,
+			expectedErr: "[line 3, column 4] [DO] loops is experimental, enable it with pragma experimental loops",
+		},
+		{
+			input: `import "loops.koa"
+contract {
+	func foo() {
+		do {
+		} while (true)
+	}
+}`,
+		},
+		{
+			input: `contract {
+	func foo() {
+		for (int i = 0; i < 10; i++) {
+		}
+	}
+}`,
+		},
+	}
+
+	resolver := mapResolver{
+		"loops.koa": `pragma experimental loops`,
+	}
+
+	for i, test := range tests {
+		_, err := parse.ParseImports(context.Background(),
+			parse.NewTokenBuffer(parse.NewLexer(test.input)), parse.Limits{}, resolver)
+		if test.expectedErr == "" {
+			if err != nil {
+				t.Errorf("test[%d] - ParseImports() returns unexpected error: %s", i, err)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != test.expectedErr {
+			t.Errorf("test[%d] - ParseImports() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+		}
+	}
+
+	parse.UnregisterExperiment("loops")
+	_, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(tests[1].input)))
+	if err != nil {
+		t.Errorf("Parse() of unregistered experiment returns unexpected error: %s", err)
+	}
+}
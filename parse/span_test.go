@@ -0,0 +1,130 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parse_test
+
+import (
+	"testing"
+
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/parse"
+)
+
+func TestRange_Expr(t *testing.T) {
+	src := "(a + b) * foo(1, x[2]) == msg.sender"
+
+	expr, err := parse.ParseExpr(src)
+	if err != nil {
+		t.Fatalf("ParseExpr() returns unexpected error: %s", err)
+	}
+
+	eq := expr.(*ast.InfixExpression)
+	mul := eq.Left.(*ast.InfixExpression)
+	call := mul.Right.(*ast.CallExpression)
+
+	tests := []struct {
+		node     ast.Ranged
+		expected string
+	}{
+		{eq, src},
+		{mul, "(a + b) * foo(1, x[2])"},
+		{mul.Left, "a + b"},
+		{call, "foo(1, x[2])"},
+		{call.Arguments[1], "x[2]"},
+		{eq.Right, "msg.sender"},
+		{eq.Right.(*ast.SelectorExpression).Field, "sender"},
+	}
+
+	for i, test := range tests {
+		r := test.node.Range()
+		if text := src[r.Start:r.End]; text != test.expected {
+			t.Errorf("test[%d] - wrong range. expected=%q, got=%q", i, test.expected, text)
+		}
+	}
+}
+
+func TestRange_Contract(t *testing.T) {
+	src := `contract {
+	const int FEE = 10
+
+	func add(a int, b int = 1) int {
+		if (a > FEE) {
+			return a + b
+		}
+		for (int i = 0; i < b; i++) {
+			a = a * 2
+		}
+		return a
+	}
+}
+`
+
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(src)))
+	if err != nil {
+		t.Fatalf("Parse() returns unexpected error: %s", err)
+	}
+
+	fn := contract.Functions[0]
+	ifStmt := fn.Body.Statements[0].(*ast.IfStatement)
+	forStmt := fn.Body.Statements[1].(*ast.ForStatement)
+
+	tests := []struct {
+		node     ast.Ranged
+		expected string
+	}{
+		{contract, src[:len(src)-1]},
+		{contract.Constants[0], "const int FEE = 10"},
+		{fn.Name, "add"},
+		{fn.Parameters[1], "b int = 1"},
+		{ifStmt, "if (a > FEE) {\n\t\t\treturn a + b\n\t\t}"},
+		{ifStmt.Consequence.Statements[0], "return a + b"},
+		{forStmt.Init, "int i = 0"},
+		{forStmt.Post, "i++"},
+		{forStmt.Body.Statements[0], "a = a * 2"},
+		{fn.Body.Statements[2], "return a"},
+	}
+
+	for i, test := range tests {
+		r := test.node.Range()
+		if text := src[r.Start:r.End]; text != test.expected {
+			t.Errorf("test[%d] - wrong range. expected=%q, got=%q", i, test.expected, text)
+		}
+	}
+
+	r := fn.Range()
+	if text := src[r.Start:r.End]; text[:8] != "func add" || text[len(text)-1] != '}' {
+		t.Errorf("wrong range of function. got=%q", text)
+	}
+}
+
+func TestRange_Stmt(t *testing.T) {
+	src := "int a = f(1)\n"
+
+	stmt, err := parse.ParseStmt(src)
+	if err != nil {
+		t.Fatalf("ParseStmt() returns unexpected error: %s", err)
+	}
+
+	r := stmt.(ast.Ranged).Range()
+	if text := src[r.Start:r.End]; text != "int a = f(1)" {
+		t.Errorf("wrong range. expected=%q, got=%q", "int a = f(1)", text)
+	}
+
+	assign := stmt.(*ast.AssignStatement)
+	if r := assign.Variable.Range(); src[r.Start:r.End] != "a" {
+		t.Errorf("wrong range of variable. got=%v", r)
+	}
+}
@@ -25,6 +25,9 @@ type Token struct {
 	Val    string
 	Column Pos
 	Line   int
+
+	// Offset is the byte offset of the first byte of token in source
+	Offset Pos
 }
 
 func (t Token) String() string {
@@ -49,12 +52,19 @@ const (
 	Ident    // add, foobar, x, y, ...
 	Int      // 1343456
 	String   // "hello world"
+	Bytes    // 0x"deadbeef"
 	Function // func
 	Contract // contract
 
 	IntType
 	StringType
 	BoolType
+	BytesType
+	UintType
+	Int8Type
+	Int16Type
+	Int32Type
+	AddressType
 	VoidType
 
 	Assign   // =
@@ -84,20 +94,37 @@ const (
 	NOT_EQ // !=
 
 	Comma // ,
+	Dot   // .
 
 	Lparen // (
 	Rparen // )
 	Lbrace // {
 	Rbrace // }
 
-	True   // true
-	False  // false
-	If     // if
-	Else   // else
-	Return // return
-	Eof    // end of file
-	Eol    // end of line
+	Lbracket // [
+	Rbracket // ]
+
+	True       // true
+	False      // false
+	If         // if
+	Else       // else
+	Return     // return
+	For        // for
+	Do         // do
+	While      // while
+	Const      // const
+	Modifier   // modifier
+	Interface  // interface
+	Implements // implements
+	Import     // import
+	Pragma     // pragma
+	ErrorDecl  // error
+	Revert     // revert
+	Enum       // enum
+	Eof        // end of file
+	Eol        // end of line
 	Semicolon
+	Comment // comment, emitted only by NewCommentLexer
 )
 
 // TokenTypeMap mapping TokenType with its
@@ -108,12 +135,20 @@ var TokenTypeMap = map[TokenType]string{
 	Ident:    "IDENT",
 	Int:      "INT",
 	String:   "STRING",
+	Bytes:    "BYTES",
 	Function: "FUNCTION",
 	Contract: "CONTRACT",
 
 	IntType:    "INT_TYPE",
 	StringType: "STRING_TYPE",
 	BoolType:   "BOOL_TYPE",
+	BytesType:  "BYTES_TYPE",
+	UintType:   "UINT_TYPE",
+	Int8Type:   "INT8_TYPE",
+	Int16Type:  "INT16_TYPE",
+	Int32Type:  "INT32_TYPE",
+
+	AddressType: "ADDRESS_TYPE",
 
 	Assign:   "ASSIGN",
 	Plus:     "PLUS",
@@ -142,34 +177,72 @@ var TokenTypeMap = map[TokenType]string{
 	NOT_EQ: "NOT_EQ",
 
 	Comma: "COMMA",
+	Dot:   "DOT",
 
 	Lparen: "LPAREN",
 	Rparen: "RPAREN",
 	Lbrace: "LBRACE",
 	Rbrace: "RBRACE",
 
+	Lbracket: "LBRACKET",
+	Rbracket: "RBRACKET",
+
 	True:   "TRUE",
 	False:  "FALSE",
 	If:     "IF",
 	Else:   "ELSE",
 	Return: "RETURN",
+	For:    "FOR",
+	Do:     "DO",
+	While:  "WHILE",
+	Const:  "CONST",
+
+	Modifier: "MODIFIER",
+
+	Interface:  "INTERFACE",
+	Implements: "IMPLEMENTS",
+	Import:     "IMPORT",
+	Pragma:     "PRAGMA",
+	ErrorDecl:  "ERROR",
+	Revert:     "REVERT",
+	Enum:       "ENUM",
 
 	Eof:       "EOF",
 	Eol:       "EOL",
 	Semicolon: "SEMICOLON",
+	Comment:   "COMMENT",
 }
 
 var keywords = map[string]TokenType{
-	"contract": Contract,
-	"func":     Function,
-	"if":       If,
-	"else":     Else,
-	"int":      IntType,
-	"string":   StringType,
-	"bool":     BoolType,
-	"return":   Return,
-	"true":     True,
-	"false":    False,
+	"contract":   Contract,
+	"func":       Function,
+	"if":         If,
+	"else":       Else,
+	"int":        IntType,
+	"string":     StringType,
+	"bool":       BoolType,
+	"bytes":      BytesType,
+	"uint":       UintType,
+	"int8":       Int8Type,
+	"int16":      Int16Type,
+	"int32":      Int32Type,
+	"int64":      IntType,
+	"address":    AddressType,
+	"return":     Return,
+	"for":        For,
+	"do":         Do,
+	"while":      While,
+	"const":      Const,
+	"modifier":   Modifier,
+	"interface":  Interface,
+	"implements": Implements,
+	"import":     Import,
+	"pragma":     Pragma,
+	"error":      ErrorDecl,
+	"revert":     Revert,
+	"enum":       Enum,
+	"true":       True,
+	"false":      False,
 }
 
 func LookupIdent(ident string) TokenType {
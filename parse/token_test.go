@@ -31,6 +31,7 @@ func TestLookupIdent(t *testing.T) {
 		{"int", IntType},
 		{"string", StringType},
 		{"return", Return},
+		{"for", For},
 		{"true", True},
 		{"false", False},
 	}
@@ -41,7 +42,7 @@ func TestLookupIdent(t *testing.T) {
 
 		if tokType != test.expectedTokenType {
 			t.Fatalf("tests[%d] - wrong token Type. Expected=%q, got=%q",
-				i, test.expectedTokenType, input)
+				i, TokenTypeMap[test.expectedTokenType], TokenTypeMap[tokType])
 		}
 	}
 
@@ -18,6 +18,7 @@ package symbol
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/DE-labtory/koa/ast"
 )
@@ -28,7 +29,13 @@ const (
 	IntegerSymbol  = "INTEGER"
 	BooleanSymbol  = "BOOLEAN"
 	StringSymbol   = "STRING"
+	BytesSymbol    = "BYTES"
+	UintSymbol     = "UINT"
+	SizedSymbol    = "SIZED_INTEGER"
+	AddressSymbol  = "ADDRESS"
 	FunctionSymbol = "FUNCTION"
+	ConstantSymbol = "CONSTANT"
+	EnumSymbol     = "ENUM"
 )
 
 type Symbol interface {
@@ -50,6 +57,47 @@ func (i *Integer) String() string {
 	return fmt.Sprintf("%s", i.Name.String())
 }
 
+// Represent signed integer symbol narrower than int,
+// Width is the number of bits, e.g. 8 for int8
+type SizedInteger struct {
+	Name  *ast.Identifier
+	Width int
+}
+
+func (i *SizedInteger) Type() SymbolType {
+	return SizedSymbol
+}
+
+func (i *SizedInteger) String() string {
+	return fmt.Sprintf("%s", i.Name.String())
+}
+
+// Represent address symbol
+type Address struct {
+	Name *ast.Identifier
+}
+
+func (a *Address) Type() SymbolType {
+	return AddressSymbol
+}
+
+func (a *Address) String() string {
+	return fmt.Sprintf("%s", a.Name.String())
+}
+
+// Represent unsigned integer symbol
+type Uint struct {
+	Name *ast.Identifier
+}
+
+func (u *Uint) Type() SymbolType {
+	return UintSymbol
+}
+
+func (u *Uint) String() string {
+	return fmt.Sprintf("%s", u.Name.String())
+}
+
 // Represent Boolean Object
 type Boolean struct {
 	Name *ast.Identifier
@@ -76,12 +124,75 @@ func (s *String) String() string {
 	return fmt.Sprintf("%s", s.Name.String())
 }
 
+// Represent Bytes Object
+type Bytes struct {
+	Name *ast.Identifier
+}
+
+func (b *Bytes) Type() SymbolType {
+	return BytesSymbol
+}
+
+func (b *Bytes) String() string {
+	return fmt.Sprintf("%s", b.Name.String())
+}
+
+// Represent Constant symbol which is declared with const keyword.
+// Constant is immutable, DataType is the type of its value.
+type Constant struct {
+	Name     *ast.Identifier
+	DataType ast.DataStructure
+}
+
+func (c *Constant) Type() SymbolType {
+	return ConstantSymbol
+}
+
+func (c *Constant) String() string {
+	return fmt.Sprintf("%s", c.Name.String())
+}
+
+// Enum is enum type whose members are integer constants,
+// the value of member is its index in Members
+type Enum struct {
+	Name    *ast.Identifier
+	Members []string
+}
+
+func (e *Enum) Type() SymbolType {
+	return EnumSymbol
+}
+
+func (e *Enum) String() string {
+	return fmt.Sprintf("%s", e.Name.String())
+}
+
+// Value returns the value of member, false if enum doesn't have it
+func (e *Enum) Value(member string) (int64, bool) {
+	for i, m := range e.Members {
+		if m == member {
+			return int64(i), true
+		}
+	}
+	return 0, false
+}
+
 // Represent Function symbol
 // Name represents function's name.
 // Scope represents function value's scope.
+// Parameters, ReturnType and ReturnTypes represent function's signature,
+// ReturnTypes is set when ReturnType is ast.TupleType.
 type Function struct {
-	Name  string
-	Scope *Scope
+	Name        string
+	Scope       *Scope
+	Parameters  []ast.DataStructure
+	ReturnType  ast.DataStructure
+	ReturnTypes []ast.DataStructure
+
+	// Defaults are default values of parameters, nil for
+	// parameter without default value. Only trailing parameters
+	// have default value.
+	Defaults []ast.Expression
 }
 
 func (f *Function) Type() SymbolType {
@@ -91,3 +202,23 @@ func (f *Function) Type() SymbolType {
 func (f *Function) String() string {
 	return fmt.Sprintf("%s", f.Name)
 }
+
+// Signature returns function's name with its parameter
+// types and return type. e.g. add(int, int) int
+func (f *Function) Signature() string {
+	params := make([]string, 0, len(f.Parameters))
+	for _, p := range f.Parameters {
+		params = append(params, p.String())
+	}
+
+	ret := f.ReturnType.String()
+	if f.ReturnType == ast.TupleType {
+		types := make([]string, 0, len(f.ReturnTypes))
+		for _, t := range f.ReturnTypes {
+			types = append(types, t.String())
+		}
+		ret = "(" + strings.Join(types, ", ") + ")"
+	}
+
+	return fmt.Sprintf("%s(%s) %s", f.Name, strings.Join(params, ", "), ret)
+}
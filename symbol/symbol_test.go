@@ -116,8 +116,8 @@ func TestFunction(t *testing.T) {
 	}{
 		{
 			&Function{
-				"add",
-				&Scope{},
+				Name:  "add",
+				Scope: &Scope{},
 			},
 			"add",
 			FunctionSymbol,
@@ -141,3 +141,44 @@ func TestFunction(t *testing.T) {
 		}
 	}
 }
+
+func TestFunction_Signature(t *testing.T) {
+	tests := []struct {
+		input    *Function
+		expected string
+	}{
+		{
+			&Function{
+				Name:       "add",
+				Parameters: []ast.DataStructure{ast.IntType, ast.IntType},
+				ReturnType: ast.IntType,
+			},
+			"add(int, int) int",
+		},
+		{
+			&Function{
+				Name:       "foo",
+				Parameters: []ast.DataStructure{},
+				ReturnType: ast.VoidType,
+			},
+			"foo() void",
+		},
+		{
+			&Function{
+				Name:        "pair",
+				Parameters:  []ast.DataStructure{ast.IntType},
+				ReturnType:  ast.TupleType,
+				ReturnTypes: []ast.DataStructure{ast.IntType, ast.BoolType},
+			},
+			"pair(int) (int, bool)",
+		},
+	}
+
+	for i, test := range tests {
+		if sig := test.input.Signature(); sig != test.expected {
+			t.Fatalf("test[%d] Signature() in Function wrong result.\n"+
+				"expected: %s\n"+
+				"got: %s", i, test.expected, sig)
+		}
+	}
+}
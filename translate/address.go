@@ -0,0 +1,118 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/encoding"
+	"github.com/DE-labtory/koa/opcode"
+)
+
+// addressWords is the number of vm words which an address takes.
+// Address of 20 bytes is kept as first 8 bytes, next 8 bytes and
+// last 4 bytes, the last one at the top of the stack.
+const addressWords = 3
+
+// addressEntries returns the names of memory entries which keep
+// the words of address variable name
+func addressEntries(name string) []string {
+	return []string{name, name + "#1", name + "#2"}
+}
+
+// isAddress reports whether e evaluates to address
+func (cc *compileContext) isAddress(e ast.Expression) bool {
+	switch expr := e.(type) {
+	case *ast.Identifier:
+		return cc.addresses[expr.Name]
+	case *ast.SelectorExpression:
+		return expr.String() == "msg.sender"
+	case *ast.AddressLiteral:
+		return true
+	}
+	return false
+}
+
+// compileAddressParameter loads address argument of argNum and
+// stores it to the memory of parameter name
+func compileAddressParameter(name string, argNum int, asm *Asm, tracer MemTracer) error {
+	operand, err := encoding.EncodeOperand(argNum)
+	if err != nil {
+		return err
+	}
+	asm.Emerge(opcode.Push, operand)
+	asm.Emerge(opcode.LoadAddress)
+
+	for _, id := range addressEntries(name) {
+		tracer.Define(id)
+	}
+	return compileStoreAddress(name, asm, tracer)
+}
+
+// compileStoreAddress stores address on top of the stack to the
+// memory of variable name, which should be defined already
+func compileStoreAddress(name string, asm *Asm, tracer MemTracer) error {
+	entries := addressEntries(name)
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry, err := tracer.Entry(entries[i])
+		if err != nil {
+			return err
+		}
+		if err := compileMemAccess(entry, opcode.Mstore, asm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileLoadAddress pushes address in the memory of variable name
+func compileLoadAddress(name string, asm *Asm, tracer MemTracer) error {
+	for _, id := range addressEntries(name) {
+		entry, err := tracer.Entry(id)
+		if err != nil {
+			return err
+		}
+		if err := compileMemAccess(entry, opcode.Mload, asm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileMemAccess emerges op, Mload or Mstore, on the memory entry
+func compileMemAccess(entry MemEntry, op opcode.Type, asm *Asm) error {
+	size, err := encoding.EncodeOperand(entry.Size)
+	if err != nil {
+		return err
+	}
+
+	offset, err := encoding.EncodeOperand(entry.Offset)
+	if err != nil {
+		return err
+	}
+
+	asm.Emerge(opcode.Push, size)
+	asm.Emerge(opcode.Push, offset)
+	asm.Emerge(op)
+	return nil
+}
+
+// compilePops pops n words from the stack
+func compilePops(n int, asm *Asm) {
+	for i := 0; i < n; i++ {
+		asm.Emerge(opcode.Pop)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DE-labtory/koa/encoding"
+	"github.com/DE-labtory/koa/opcode"
+)
+
+// Assemble reads program written in text format, with following
+// additions for writing program by hand:
+//
+//	.const SIZE 8          ; constant, can be used as Push operand
+//	loop:                  ; label, the index of next instruction
+//	Push 10                ; decimal operand
+//	Push SIZE
+//	Push loop
+//	Jump
+//
+// Every operand is encoded as 8 bytes, and the assembled bytecode is
+// verified by disassembling it.
+func Assemble(text string) (Asm, error) {
+	lines := strings.Split(text, "\n")
+
+	symbols, err := collectSymbols(lines)
+	if err != nil {
+		return Asm{}, err
+	}
+
+	asm := Asm{
+		AsmCodes: make([]AsmCode, 0),
+	}
+
+	for i, line := range lines {
+		fields := asmFields(line)
+		if len(fields) == 0 || isDirective(fields) || isLabel(fields) {
+			continue
+		}
+
+		operator, err := opcode.Lookup(fields[0])
+		if err != nil {
+			return Asm{}, TextError{i + 1, fmt.Sprintf("unknown operator %s", fields[0])}
+		}
+
+		if operator != opcode.Push {
+			if len(fields) != 1 {
+				return Asm{}, TextError{i + 1, fmt.Sprintf("%s doesn't take operand", fields[0])}
+			}
+			asm.Emerge(operator)
+			continue
+		}
+
+		if len(fields) != 2 {
+			return Asm{}, TextError{i + 1, "Push takes exactly one operand"}
+		}
+
+		operand, err := assembleOperand(fields[1], symbols)
+		if err != nil {
+			return Asm{}, TextError{i + 1, err.Error()}
+		}
+		asm.Emerge(operator, operand)
+	}
+
+	disassembled, err := Disassemble(asm.ToRawByteCode())
+	if err != nil {
+		return Asm{}, err
+	}
+	if !bytes.Equal(disassembled.ToRawByteCode(), asm.ToRawByteCode()) {
+		return Asm{}, fmt.Errorf("Assemble() error - assembled bytecode doesn't disassemble to itself")
+	}
+
+	return asm, nil
+}
+
+// collectSymbols reads constants and labels of program. Label has
+// the index of instruction following it, where Push takes two
+// indexes, one for operator and one for operand.
+func collectSymbols(lines []string) (map[string]int64, error) {
+	symbols := map[string]int64{}
+	pc := 0
+
+	for i, line := range lines {
+		fields := asmFields(line)
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case isDirective(fields):
+			if fields[0] != ".const" || len(fields) != 3 {
+				return nil, TextError{i + 1, "constant should be .const NAME VALUE"}
+			}
+			value, err := strconv.ParseInt(fields[2], 0, 64)
+			if err != nil {
+				return nil, TextError{i + 1, fmt.Sprintf("invalid constant value %s", fields[2])}
+			}
+			if err := defineSymbol(symbols, fields[1], value); err != nil {
+				return nil, TextError{i + 1, err.Error()}
+			}
+		case isLabel(fields):
+			name := strings.TrimSuffix(fields[0], ":")
+			if err := defineSymbol(symbols, name, int64(pc)); err != nil {
+				return nil, TextError{i + 1, err.Error()}
+			}
+		case fields[0] == "Push":
+			pc += 2
+		default:
+			pc++
+		}
+	}
+
+	return symbols, nil
+}
+
+func defineSymbol(symbols map[string]int64, name string, value int64) error {
+	if name == "" {
+		return fmt.Errorf("empty symbol name")
+	}
+	if _, ok := symbols[name]; ok {
+		return fmt.Errorf("symbol %s already defined", name)
+	}
+	symbols[name] = value
+	return nil
+}
+
+// assembleOperand encodes hexadecimal, decimal or symbol operand of Push
+func assembleOperand(s string, symbols map[string]int64) ([]byte, error) {
+	if strings.HasPrefix(s, "0x") {
+		return parseOperand(s)
+	}
+
+	if value, ok := symbols[s]; ok {
+		return encoding.EncodeOperand(value)
+	}
+
+	value, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("undefined symbol %s", s)
+	}
+	return encoding.EncodeOperand(value)
+}
+
+// asmFields splits line to fields after removing comment
+func asmFields(line string) []string {
+	if idx := strings.Index(line, commentPrefix); idx >= 0 {
+		line = line[:idx]
+	}
+	return strings.Fields(line)
+}
+
+func isDirective(fields []string) bool {
+	return strings.HasPrefix(fields[0], ".")
+}
+
+func isLabel(fields []string) bool {
+	return len(fields) == 1 && strings.HasSuffix(fields[0], ":")
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DE-labtory/koa/opcode"
+	"github.com/DE-labtory/koa/translate"
+	"github.com/DE-labtory/koa/vm"
+)
+
+func TestAssemble(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    []byte
+		expectedErr string
+	}{
+		{
+			input: `
+.const ONE 1
+Push ONE
+Push end      ; skip next Push
+Jump
+Push 0x0000000000000002
+end:
+Push 3
+Add
+`,
+			expected: []byte{
+				byte(opcode.Push), 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+				byte(opcode.Push), 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x07,
+				byte(opcode.Jump),
+				byte(opcode.Push), 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+				byte(opcode.Push), 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03,
+				byte(opcode.Add),
+			},
+		},
+		{
+			input: `
+Push -1
+.const BIG 0x10
+Push BIG
+`,
+			expected: []byte{
+				byte(opcode.Push), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+				byte(opcode.Push), 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+			},
+		},
+		{
+			input:       "Push nowhere",
+			expectedErr: "[line 1] undefined symbol nowhere",
+		},
+		{
+			input:       "a:\nAdd\na:",
+			expectedErr: "[line 3] symbol a already defined",
+		},
+		{
+			input:       ".const A",
+			expectedErr: "[line 1] constant should be .const NAME VALUE",
+		},
+		{
+			input:       ".const A B",
+			expectedErr: "[line 1] invalid constant value B",
+		},
+		{
+			input:       "Jump end",
+			expectedErr: "[line 1] Jump doesn't take operand",
+		},
+	}
+
+	for i, test := range tests {
+		asm, err := translate.Assemble(test.input)
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - Assemble() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - Assemble() returns unexpected error: %s", i, err)
+			continue
+		}
+		if raw := asm.ToRawByteCode(); !bytes.Equal(raw, test.expected) {
+			t.Errorf("test[%d] - Assemble() wrong bytecode.\nexpected=%x\ngot=%x", i, test.expected, raw)
+		}
+	}
+}
+
+func TestAssemble_execute(t *testing.T) {
+	asm, err := translate.Assemble(`
+Push 1
+Push end
+Jump
+Push 2   ; skipped
+end:
+Push 3
+Add
+`)
+	if err != nil {
+		t.Fatalf("Assemble() returns unexpected error: %s", err)
+	}
+
+	stack, err := vm.Execute(asm.ToRawByteCode(), vm.NewMemory(), nil)
+	if err != nil {
+		t.Fatalf("Execute() returns unexpected error: %s", err)
+	}
+	if result := int64(stack.Pop()); result != 4 {
+		t.Errorf("wrong result of assembled program. expected=4, got=%d", result)
+	}
+}
@@ -17,6 +17,7 @@
 package translate
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -26,6 +27,72 @@ import (
 	"github.com/DE-labtory/koa/opcode"
 )
 
+// ErrCompile is matched by every error which CompileContract returns,
+// use errors.Is(err, ErrCompile) to distinguish them from the errors
+// of other phases
+var ErrCompile = errors.New("compile error")
+
+// Error wraps the error which happened during compiling the contract.
+// The message is the same as the one of the wrapped error.
+type Error struct {
+	Err error
+}
+
+func (e Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+func (e Error) Is(target error) bool {
+	return target == ErrCompile
+}
+
+// errMultipleValues is returned when contract assigns multiple values
+// returned by function, calls to functions of contract aren't compiled yet
+var errMultipleValues = errors.New("assigning multiple values returned by function is not supported by compiler yet")
+
+// errFunctionCall is returned when contract calls its own function,
+// which would leave nothing on the stack until internal calls are
+// compiled
+var errFunctionCall = errors.New("calling function of contract is not supported by compiler yet")
+
+// errBytes is returned when contract uses byte string literal longer
+// than encoding.MaxBytesLength, vm item can't hold it
+var errBytes = fmt.Errorf("bytes longer than %d bytes are not supported by compiler yet", encoding.MaxBytesLength)
+
+// SelectorError occurs when two functions of contract have the same
+// selector, function jumper would always dispatch to the first one
+type SelectorError struct {
+	Selector []byte
+	First    string
+	Second   string
+}
+
+func (e SelectorError) Error() string {
+	return fmt.Sprintf("function selector %x of %s collides with %s", e.Selector, e.Second, e.First)
+}
+
+func (e SelectorError) Is(target error) bool {
+	return target == ErrCompile
+}
+
+// checkSelectors returns SelectorError if selectors of functions collide
+func checkSelectors(functions []*ast.FunctionLiteral) error {
+	signatures := make(map[string]string)
+	for _, f := range functions {
+		sig := f.Signature()
+		selector := abi.Selector(sig)
+		if first, ok := signatures[string(selector)]; ok {
+			return SelectorError{selector, first, sig}
+		}
+		signatures[string(selector)] = sig
+	}
+	return nil
+}
+
 type FuncMap map[string]int
 
 // Declare() saves the start point of function.
@@ -38,45 +105,127 @@ func (m FuncMap) Declare(signature string, asm Asm) {
 // CompileContract() compiles a smart contract.
 // returns bytecode and error.
 func CompileContract(c ast.Contract) (Asm, error) {
+	return CompileContractContext(context.Background(), c)
+}
+
+// CompileContractContext is like CompileContract but stops before
+// compiling the next function once ctx is done, returning ctx.Err()
+func CompileContractContext(ctx context.Context, c ast.Contract) (Asm, error) {
+	asm, _, err := compileContract(ctx, c)
+	if err != nil && err != ctx.Err() {
+		return asm, Error{err}
+	}
+	return asm, err
+}
+
+// compileContext has what compiler knows about the contract being
+// compiled. Each compilation has its own, so that contracts can be
+// compiled concurrently.
+type compileContext struct {
+	// constants keeps the values of contract constants folded before
+	// compiling functions. Identifier which refers to constant is compiled
+	// to Push of its value instead of loading it from memory. Members of
+	// enum are kept by their selectors, e.g. Status.Active.
+	constants map[string]interface{}
+
+	// unsigned tells whether variable or constant is uint, so that
+	// operators on it are compiled to unsigned opcodes
+	unsigned map[string]bool
+
+	// widths has the bit width of sized integer variables. Vm works on
+	// 64 bits words, so results of arithmetic on them are truncated to
+	// the width.
+	widths map[string]int
+
+	// errorSelectors has the selectors of custom errors declared in
+	// contract, which revert statements abort with
+	errorSelectors map[string][]byte
+
+	// addresses tells whether variable is address, which takes
+	// addressWords words in the stack and the memory
+	addresses map[string]bool
+}
+
+func newCompileContext() *compileContext {
+	return &compileContext{
+		constants:      map[string]interface{}{},
+		unsigned:       map[string]bool{},
+		widths:         map[string]int{},
+		errorSelectors: map[string][]byte{},
+		addresses:      map[string]bool{},
+	}
+}
+
+// compileContract returns bytecode of contract with FuncMap, which has
+// the start of each function in it
+func compileContract(ctx context.Context, c ast.Contract) (Asm, FuncMap, error) {
+	c, err := withSupportsInterface(c)
+	if err != nil {
+		return Asm{}, nil, err
+	}
+	if err := checkSelectors(c.Functions); err != nil {
+		return Asm{}, nil, err
+	}
+
 	asm := &Asm{
 		AsmCodes: make([]AsmCode, 0),
 	}
 
+	cc := newCompileContext()
+	values, err := foldConstants(c.Constants, c.Enums)
+	if err != nil {
+		return *asm, nil, err
+	}
+	cc.constants = values
+	for _, cs := range c.Constants {
+		cc.unsigned[cs.Name.Name] = cs.Type == ast.UintType
+		cc.declareWidth(cs.Name.Name, cs.Type)
+		cc.addresses[cs.Name.Name] = cs.Type == ast.AddressType
+	}
+
+	if err := cc.declareErrors(c.Errors); err != nil {
+		return *asm, nil, err
+	}
+
 	// Keep the size of the memory with createMemSizePlaceholder.
 	if err := createMemSizePlaceholder(asm); err != nil {
-		return *asm, err
+		return *asm, nil, err
 	}
 
 	// Keep the size of the jumper with createFuncJmprPlaceholder.
 	funcMap := FuncMap{}
 	if err := createFuncJmprPlaceholder(c, asm, funcMap); err != nil {
-		return *asm, err
+		return *asm, nil, err
 	}
 
 	// Compile the functions in contract.
 	memTracer := NewMemEntryTable()
 
 	for _, f := range c.Functions {
+		if err := ctx.Err(); err != nil {
+			return *asm, nil, err
+		}
+
 		funcMap.Declare(f.Signature(), *asm)
 
-		if err := compileFunction(*f, asm, memTracer); err != nil {
-			return *asm, err
+		if err := compileFunction(*f, asm, memTracer, cc); err != nil {
+			return *asm, nil, err
 		}
 	}
 
 	// Compile Memory size with updated memory table.
 	// And replace expected memory size with new memory size of the memory table.
 	if err := compileMemSize(asm, memTracer); err != nil {
-		return *asm, err
+		return *asm, nil, err
 	}
 
 	// Compile Function jumper with updated FuncMap.
 	// And replace expected function jumper with new function jumper
 	if err := compileFuncJmpr(c, asm, funcMap); err != nil {
-		return *asm, err
+		return *asm, nil, err
 	}
 
-	return *asm, nil
+	return *asm, funcMap, nil
 }
 
 // TODO: implement test cases :-)
@@ -224,11 +373,39 @@ func compileFuncSel(asm *Asm, funcSel []byte, funcDst int) error {
 }
 
 func ExtractAbi(c ast.Contract) (*abi.ABI, error) {
+	c, err := withSupportsInterface(c)
+	if err != nil {
+		return nil, err
+	}
+
 	abiMethods, err := toAbiMethods(c.Functions)
 	if err != nil {
 		return nil, err
 	}
 
+	abiErrors := make([]abi.Method, 0, len(c.Errors))
+	for _, e := range c.Errors {
+		m, err := abi.ExtractAbiFromError(*e)
+		if err != nil {
+			return nil, err
+		}
+		abiErrors = append(abiErrors, m)
+	}
+
+	return &abi.ABI{
+		Methods: abiMethods,
+		Errors:  abiErrors,
+	}, nil
+}
+
+// ExtractInterfaceAbi returns the ABI of functions in interface,
+// which contracts implementing it share
+func ExtractInterfaceAbi(i ast.Interface) (*abi.ABI, error) {
+	abiMethods, err := toAbiMethods(i.Functions)
+	if err != nil {
+		return nil, err
+	}
+
 	return &abi.ABI{
 		Methods: abiMethods,
 	}, nil
@@ -252,17 +429,17 @@ func toAbiMethods(functions []*ast.FunctionLiteral) ([]abi.Method, error) {
 
 // compileFunction() compiles a function in contract.
 // Generates and adds output to bytecode.
-func compileFunction(f ast.FunctionLiteral, bytecode *Asm, tracer *MemEntryTable) error {
+func compileFunction(f ast.FunctionLiteral, bytecode *Asm, tracer *MemEntryTable, cc *compileContext) error {
 	closedTracer := NewEnclosedMemEntryTable(tracer)
 	for i, param := range f.Parameters {
-		if err := compileParameter(*param, i, bytecode, closedTracer); err != nil {
+		if err := compileParameter(*param, i, bytecode, closedTracer, cc); err != nil {
 			return err
 		}
 	}
 
 	statements := f.Body.Statements
 	for _, s := range statements {
-		if err := compileStatement(s, bytecode, closedTracer); err != nil {
+		if err := compileStatement(s, bytecode, closedTracer, cc); err != nil {
 			return err
 		}
 	}
@@ -272,7 +449,18 @@ func compileFunction(f ast.FunctionLiteral, bytecode *Asm, tracer *MemEntryTable
 }
 
 // compileParameter() compiles parameters in a function.
-func compileParameter(p ast.ParameterLiteral, argNum int, bytecode *Asm, tracer MemTracer) error {
+func compileParameter(p ast.ParameterLiteral, argNum int, bytecode *Asm, tracer MemTracer, cc *compileContext) error {
+	if p.Identifier.Name == blank {
+		return nil
+	}
+
+	cc.unsigned[p.Identifier.String()] = p.Type == ast.UintType
+	cc.declareWidth(p.Identifier.String(), p.Type)
+	cc.addresses[p.Identifier.String()] = p.Type == ast.AddressType
+	if p.Type == ast.AddressType {
+		return compileAddressParameter(p.Identifier.String(), argNum, bytecode, tracer)
+	}
+
 	entry := tracer.Define(p.Identifier.String())
 	// Load an argument
 	operand, err := encoding.EncodeOperand(argNum)
@@ -280,7 +468,17 @@ func compileParameter(p ast.ParameterLiteral, argNum int, bytecode *Asm, tracer
 		return err
 	}
 	bytecode.Emerge(opcode.Push, operand)
-	bytecode.Emerge(opcode.LoadArgs)
+	if p.Type == ast.BytesType {
+		bytecode.Emerge(opcode.LoadBytes)
+	} else {
+		bytecode.Emerge(opcode.LoadArgs)
+	}
+	// Argument of sized integer is wrapped around to its width
+	if sized(p.Type) {
+		if err := compileTruncate(p.Type.Width(), bytecode); err != nil {
+			return err
+		}
+	}
 	// Push size of the argument
 	size, err := encoding.EncodeOperand(entry.Size)
 	if err != nil {
@@ -301,22 +499,37 @@ func compileParameter(p ast.ParameterLiteral, argNum int, bytecode *Asm, tracer
 
 // compileStatement() compiles a statement in function.
 // Generates and adds output to bytecode.
-func compileStatement(s ast.Statement, bytecode *Asm, tracer MemTracer) error {
+func compileStatement(s ast.Statement, bytecode *Asm, tracer MemTracer, cc *compileContext) error {
 	switch statement := s.(type) {
 	case *ast.AssignStatement:
-		return compileAssignStatement(statement, bytecode, tracer)
+		return compileAssignStatement(statement, bytecode, tracer, cc)
+
+	case *ast.TupleAssignStatement:
+		return compileTupleAssignStatement(statement, bytecode, tracer, cc)
 
 	case *ast.ReturnStatement:
-		return compileReturnStatement(statement, bytecode, tracer)
+		return compileReturnStatement(statement, bytecode, tracer, cc)
+
+	case *ast.ReassignStatement:
+		return compileReassignStatement(statement, bytecode, tracer, cc)
 
 	case *ast.IfStatement:
-		return compileIfStatement(statement, bytecode, tracer)
+		return compileIfStatement(statement, bytecode, tracer, cc)
+
+	case *ast.ForStatement:
+		return compileForStatement(statement, bytecode, tracer, cc)
+
+	case *ast.DoWhileStatement:
+		return compileDoWhileStatement(statement, bytecode, tracer, cc)
+
+	case *ast.RevertStatement:
+		return compileRevertStatement(statement, bytecode, tracer, cc)
 
 	case *ast.BlockStatement:
-		return compileBlockStatement(statement, bytecode, tracer)
+		return compileBlockStatement(statement, bytecode, tracer, cc)
 
 	case *ast.ExpressionStatement:
-		return compileExpressionStatement(statement, bytecode, tracer)
+		return compileExpressionStatement(statement, bytecode, tracer, cc)
 
 	default:
 		return nil
@@ -338,12 +551,33 @@ func compileStatement(s ast.Statement, bytecode *Asm, tracer MemTracer) error {
 // 	[size]
 // 	[value]
 //
-func compileAssignStatement(s *ast.AssignStatement, asm *Asm, tracer MemTracer) error {
-	if err := compileExpression(s.Value, asm, tracer); err != nil {
+func compileAssignStatement(s *ast.AssignStatement, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if err := compileExpression(s.Value, asm, tracer, cc); err != nil {
 		return err
 	}
 
-	memEntry := tracer.Define(s.Variable.Name)
+	return compileDefine(s.Variable.Name, s.Type, s.Value, asm, tracer, cc)
+}
+
+// compileDefine() defines variable name of type ds and stores value,
+// which is on top of the stack, to its memory.
+func compileDefine(name string, ds ast.DataStructure, value ast.Expression, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if name == blank {
+		return compileDiscard(value, asm, cc)
+	}
+
+	cc.unsigned[name] = ds == ast.UintType
+	cc.declareWidth(name, ds)
+	cc.addresses[name] = ds == ast.AddressType
+	if ds == ast.AddressType {
+		for _, id := range addressEntries(name) {
+			tracer.Define(id)
+		}
+		return compileStoreAddress(name, asm, tracer)
+	}
+
+	memEntry := tracer.Define(name)
+
 	size, err := encoding.EncodeOperand(memEntry.Size)
 	if err != nil {
 		return err
@@ -360,12 +594,101 @@ func compileAssignStatement(s *ast.AssignStatement, asm *Asm, tracer MemTracer)
 	return nil
 }
 
+// compileTupleAssignStatement() compiles assigning values of tuple
+// expression to variables. Every value is evaluated before variables
+// are defined, then values are stored from the last one.
+//
+// Ex)
+//
+// translate
+// 	'int a, bool b = 1, true'
+// to
+// 	'Push 1 Push true Push <size of b> Push <offset of b> Mstore Push <size of a> Push <offset of a> Mstore'
+//
+func compileTupleAssignStatement(s *ast.TupleAssignStatement, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	tuple, ok := s.Value.(*ast.TupleExpression)
+	if !ok {
+		return errMultipleValues
+	}
+
+	for _, e := range tuple.Elements {
+		if err := compileExpression(e, asm, tracer, cc); err != nil {
+			return err
+		}
+	}
+
+	for i := len(s.Variables) - 1; i >= 0; i-- {
+		if err := compileDefine(s.Variables[i].Name, s.Types[i], tuple.Elements[i], asm, tracer, cc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileReassignStatement() compiles a reassign statement,
+// new value is stored to the memory of the variable.
+//
+// Ex)
+//
+// translate
+// 	'a = 5'
+// to
+// 	'Push 5 Push <size of a> Push <offset of a> Mstore'
+//
+func compileReassignStatement(s *ast.ReassignStatement, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if err := compileExpression(s.Value, asm, tracer, cc); err != nil {
+		return err
+	}
+
+	if s.Variable.Name == blank {
+		return compileDiscard(s.Value, asm, cc)
+	}
+
+	if cc.addresses[s.Variable.Name] {
+		return compileStoreAddress(s.Variable.Name, asm, tracer)
+	}
+
+	memEntry, err := tracer.Entry(s.Variable.Name)
+	if err != nil {
+		return err
+	}
+
+	size, err := encoding.EncodeOperand(memEntry.Size)
+	if err != nil {
+		return err
+	}
+
+	offset, err := encoding.EncodeOperand(memEntry.Offset)
+	if err != nil {
+		return err
+	}
+
+	asm.Emerge(opcode.Push, size)
+	asm.Emerge(opcode.Push, offset)
+	asm.Emerge(opcode.Mstore)
+	return nil
+}
+
+// blank is the identifier which discards value assigned to it
+const blank = "_"
+
+// compileDiscard() compiles assigning to blank identifier,
+// the value is popped instead of stored in the memory.
+func compileDiscard(value ast.Expression, asm *Asm, cc *compileContext) error {
+	if cc.isAddress(value) {
+		compilePops(addressWords, asm)
+		return nil
+	}
+	asm.Emerge(opcode.Pop)
+	return nil
+}
+
 // compileReturnStatement compiles 'return' keyword
 //
 // PROTOCOL:
 //   if return value of return statement is nil, then
 //   return value zero
-func compileReturnStatement(s *ast.ReturnStatement, asm *Asm, tracer MemTracer) error {
+func compileReturnStatement(s *ast.ReturnStatement, asm *Asm, tracer MemTracer, cc *compileContext) error {
 
 	var retVal ast.Expression
 
@@ -375,7 +698,16 @@ func compileReturnStatement(s *ast.ReturnStatement, asm *Asm, tracer MemTracer)
 		retVal = s.ReturnValue
 	}
 
-	if err := compileExpression(retVal, asm, tracer); err != nil {
+	if tuple, ok := retVal.(*ast.TupleExpression); ok {
+		return compileTupleReturn(tuple, asm, tracer, cc)
+	}
+
+	// address is returned as its words
+	if cc.isAddress(retVal) {
+		return compileTupleReturn(&ast.TupleExpression{Elements: []ast.Expression{retVal}}, asm, tracer, cc)
+	}
+
+	if err := compileExpression(retVal, asm, tracer, cc); err != nil {
 		return err
 	}
 
@@ -384,6 +716,38 @@ func compileReturnStatement(s *ast.ReturnStatement, asm *Asm, tracer MemTracer)
 	return nil
 }
 
+// compileTupleReturn() compiles returning multiple values, which are
+// returned with the number of their words.
+//
+// Ex)
+//
+// translate
+// 	'return 1, true'
+// to
+// 	'Push 1 Push true Push 2 Returns'
+//
+func compileTupleReturn(t *ast.TupleExpression, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	words := 0
+	for _, e := range t.Elements {
+		if err := compileExpression(e, asm, tracer, cc); err != nil {
+			return err
+		}
+
+		if cc.isAddress(e) {
+			words += addressWords
+		} else {
+			words++
+		}
+	}
+
+	if err := compilePrimitive(words, asm); err != nil {
+		return err
+	}
+	asm.Emerge(opcode.Returns)
+
+	return nil
+}
+
 // compileIfStatement() compiles a 'if statement'.
 //
 // Ex)
@@ -397,20 +761,20 @@ func compileReturnStatement(s *ast.ReturnStatement, asm *Asm, tracer MemTracer)
 // to
 //  'push <expression> push <pc-to-jumpdst-1> jumpi <Consequence...> push <pc-to-end-of-jumpdst-2> jump jumpdst-1 <Alternative...> jumpdst-2'
 //
-func compileIfStatement(s *ast.IfStatement, asm *Asm, tracer MemTracer) error {
+func compileIfStatement(s *ast.IfStatement, asm *Asm, tracer MemTracer, cc *compileContext) error {
 
-	if err := compileExpression(s.Condition, asm, tracer); err != nil {
+	if err := compileExpression(s.Condition, asm, tracer, cc); err != nil {
 		return err
 	}
 
 	if s.Alternative != nil {
-		return compileIfElse(s, asm, tracer)
+		return compileIfElse(s, asm, tracer, cc)
 	}
 
-	return compileIf(s, asm, tracer)
+	return compileIf(s, asm, tracer, cc)
 }
 
-func compileIfElse(s *ast.IfStatement, asm *Asm, tracer MemTracer) error {
+func compileIfElse(s *ast.IfStatement, asm *Asm, tracer MemTracer, cc *compileContext) error {
 	// 'push <expression>
 
 	asm.Emerge(opcode.Push, []byte(fmt.Sprintf("%d", -1)))
@@ -419,7 +783,7 @@ func compileIfElse(s *ast.IfStatement, asm *Asm, tracer MemTracer) error {
 	l1 := len(asm.AsmCodes)
 	asm.Emerge(opcode.Jumpi)
 	// 'push <expression> push <-1(will be replaced)> jumpi'
-	if err := compileBlockStatement(s.Consequence, asm, tracer); err != nil {
+	if err := compileBlockStatement(s.Consequence, asm, tracer, cc); err != nil {
 		return err
 	}
 	// 'push <expression> push <-1(will be replaced)> jumpi <Consequence...>'
@@ -429,7 +793,7 @@ func compileIfElse(s *ast.IfStatement, asm *Asm, tracer MemTracer) error {
 	asm.Emerge(opcode.Jump)
 	// 'push <expression> push <-1(will be replaced)> jumpi <Consequence...> push <pc-to-end-of-Alternative> jump'
 
-	if err := compileBlockStatement(s.Alternative, asm, tracer); err != nil {
+	if err := compileBlockStatement(s.Alternative, asm, tracer, cc); err != nil {
 		return err
 	}
 
@@ -451,7 +815,7 @@ func compileIfElse(s *ast.IfStatement, asm *Asm, tracer MemTracer) error {
 	return nil
 }
 
-func compileIf(s *ast.IfStatement, asm *Asm, tracer MemTracer) error {
+func compileIf(s *ast.IfStatement, asm *Asm, tracer MemTracer, cc *compileContext) error {
 	// 'push <expression>
 
 	asm.Emerge(opcode.Push, []byte(fmt.Sprintf("%d", -1)))
@@ -460,7 +824,7 @@ func compileIf(s *ast.IfStatement, asm *Asm, tracer MemTracer) error {
 	l1 := len(asm.AsmCodes)
 	asm.Emerge(opcode.Jumpi)
 	// 'push <expression> push <-1(will be replaced)> jumpi'
-	if err := compileBlockStatement(s.Consequence, asm, tracer); err != nil {
+	if err := compileBlockStatement(s.Consequence, asm, tracer, cc); err != nil {
 		return err
 	}
 	// 'push <expression> push <-1(will be replaced)> jumpi <Consequence...>'
@@ -477,40 +841,141 @@ func compileIf(s *ast.IfStatement, asm *Asm, tracer MemTracer) error {
 	return nil
 }
 
-func compileBlockStatement(s *ast.BlockStatement, bytecode *Asm, tracer MemTracer) error {
-	for _, statement := range s.Statements {
-		if err := compileStatement(statement, bytecode, tracer); err != nil {
+// compileForStatement() compiles a 'for statement'.
+// Loop without condition runs until it returns.
+//
+// Ex)
+//
+// translate
+// 	'for (Init; Condition; Post) {
+// 		// Body...
+//  }'
+// to
+//  '<Init> <Condition> push <pc-to-end-of-loop> jumpi <Body...> <Post> push <pc-to-Condition> jump'
+//
+func compileForStatement(s *ast.ForStatement, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if s.Init != nil {
+		if err := compileStatement(s.Init, asm, tracer, cc); err != nil {
 			return err
 		}
 	}
 
+	// pc of the first instruction of condition
+	l1 := len(asm.AsmCodes)
+
+	l2 := -1
+	if s.Condition != nil {
+		if err := compileExpression(s.Condition, asm, tracer, cc); err != nil {
+			return err
+		}
+		asm.Emerge(opcode.Push, []byte(fmt.Sprintf("%d", -1)))
+		// '<Init> <Condition> push <-1(will be replaced)>'
+		l2 = len(asm.AsmCodes)
+		asm.Emerge(opcode.Jumpi)
+	}
+
+	if err := compileBlockStatement(s.Body, asm, tracer, cc); err != nil {
+		return err
+	}
+
+	if s.Post != nil {
+		if err := compileStatement(s.Post, asm, tracer, cc); err != nil {
+			return err
+		}
+	}
+
+	pc2cond, err := encoding.EncodeOperand(l1)
+	if err != nil {
+		return err
+	}
+	asm.Emerge(opcode.Push, pc2cond)
+	asm.Emerge(opcode.Jump)
+	// '<Init> <Condition> push <-1(will be replaced)> jumpi <Body...> <Post> push <pc-to-Condition> jump'
+
+	if l2 != -1 {
+		pc2end, err := encoding.EncodeOperand(len(asm.AsmCodes))
+		if err != nil {
+			return err
+		}
+		asm.ReplaceOperandAt(l2-1, pc2end)
+	}
+
 	return nil
 }
 
-func compileExpressionStatement(s *ast.ExpressionStatement, bytecode *Asm, tracer MemTracer) error {
-	if err := compileExpression(s.Expr, bytecode, tracer); err != nil {
+// compileDoWhileStatement() compiles a 'do-while statement'.
+//
+// Ex)
+//
+// translate
+// 	'do {
+// 		// Body...
+//  } while (expression)'
+// to
+//  '<Body...> <expression> NOT push <pc-to-Body> jumpi'
+//
+func compileDoWhileStatement(s *ast.DoWhileStatement, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	// pc of the first instruction of body
+	l1 := len(asm.AsmCodes)
+
+	if err := compileBlockStatement(s.Body, asm, tracer, cc); err != nil {
 		return err
 	}
 
-	// Clear the stack.
-	bytecode.Emerge(opcode.Pop)
+	if err := compileExpression(s.Condition, asm, tracer, cc); err != nil {
+		return err
+	}
+	// Jumpi jumps when the condition is false, so the condition is
+	// negated to jump back to the body while it is true
+	asm.Emerge(opcode.NOT)
+
+	pc2body, err := encoding.EncodeOperand(l1)
+	if err != nil {
+		return err
+	}
+	asm.Emerge(opcode.Push, pc2body)
+	asm.Emerge(opcode.Jumpi)
+
+	return nil
+}
+
+func compileBlockStatement(s *ast.BlockStatement, bytecode *Asm, tracer MemTracer, cc *compileContext) error {
+	for _, statement := range s.Statements {
+		if err := compileStatement(statement, bytecode, tracer, cc); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+func compileExpressionStatement(s *ast.ExpressionStatement, bytecode *Asm, tracer MemTracer, cc *compileContext) error {
+	// require and assert leave nothing on the stack
+	if call, ok := s.Expr.(*ast.CallExpression); ok && isRevertUnless(call) {
+		return compileRevertUnless(call.Arguments, bytecode, tracer, cc)
+	}
+
+	if err := compileExpression(s.Expr, bytecode, tracer, cc); err != nil {
+		return err
+	}
+
+	// Clear the stack.
+	return compileDiscard(s.Expr, bytecode, cc)
+}
+
 // TODO: implement me w/ test cases :-)
 // compileExpression() compiles a expression in statement.
 // Generates and adds ouput to bytecode.
-func compileExpression(e ast.Expression, asm *Asm, tracer MemTracer) error {
+func compileExpression(e ast.Expression, asm *Asm, tracer MemTracer, cc *compileContext) error {
 	switch expr := e.(type) {
 	case *ast.CallExpression:
-		return compileCallExpression(expr, asm)
+		return compileCallExpression(expr, asm, tracer, cc)
 
 	case *ast.InfixExpression:
-		return compileInfixExpression(expr, asm, tracer)
+		return compileInfixExpression(expr, asm, tracer, cc)
 
 	case *ast.PrefixExpression:
-		return compilePrefixExpression(expr, asm, tracer)
+		return compilePrefixExpression(expr, asm, tracer, cc)
 
 	case *ast.IntegerLiteral:
 		return compilePrimitive(expr.Value, asm)
@@ -521,37 +986,167 @@ func compileExpression(e ast.Expression, asm *Asm, tracer MemTracer) error {
 	case *ast.BooleanLiteral:
 		return compilePrimitive(expr.Value, asm)
 
+	case *ast.BytesLiteral:
+		return compileBytesLiteral(expr, asm)
+
+	case *ast.AddressLiteral:
+		return compilePrimitive(expr.Value, asm)
+
+	case *ast.IndexExpression:
+		return compileIndexExpression(expr, asm, tracer, cc)
+
+	case *ast.SelectorExpression:
+		return compileSelectorExpression(expr, asm, cc)
+
 	case *ast.Identifier:
-		return compileIdentifier(expr, asm, tracer)
+		return compileIdentifier(expr, asm, tracer, cc)
 
 	default:
 		return errors.New("compileExpression() error")
 	}
 }
 
+// builtins maps builtin function to the opcode which produces
+// its value from its arguments
+var builtins = map[string]opcode.Type{
+	"chainid": opcode.ChainID,
+	"itoa":    opcode.Itoa,
+	"atoi":    opcode.Atoi,
+	"hex":     opcode.Hex,
+	"len":     opcode.Len,
+}
+
 // TODO: implement me w/ test cases :-)
-func compileCallExpression(e *ast.CallExpression, asm *Asm) error {
+func compileCallExpression(e *ast.CallExpression, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if ident, ok := e.Function.(*ast.Identifier); ok {
+		if op, ok := builtins[ident.Name]; ok {
+			for _, arg := range e.Arguments {
+				if err := compileExpression(arg, asm, tracer, cc); err != nil {
+					return err
+				}
+			}
+			asm.Emerge(op)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", errFunctionCall, e.Function)
+}
+
+// isRevertUnless reports whether e calls require or assert builtin
+func isRevertUnless(e *ast.CallExpression) bool {
+	ident, ok := e.Function.(*ast.Identifier)
+	return ok && (ident.Name == "require" || ident.Name == "assert")
+}
+
+// compileRevertUnless() compiles 'require(cond, "reason")' and
+// 'assert(cond)', which revert unless the condition holds.
+// assert reverts without reason. Reason is reverted with as a single
+// word, so it is at most 6 characters, which type checker verifies.
+//
+// Ex)
+//
+// translate
+// 	'require(a > 0, "amount")'
+// to
+// 	'<a > 0> NOT Push <pc-after-revert> Jumpi Push "amount" Revert'
+//
+func compileRevertUnless(args []ast.Expression, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if len(args) == 0 {
+		return errors.New("compileRevertUnless() error - missing condition")
+	}
+
+	if err := compileExpression(args[0], asm, tracer, cc); err != nil {
+		return err
+	}
+	asm.Emerge(opcode.NOT)
+
+	asm.Emerge(opcode.Push, []byte(fmt.Sprintf("%d", -1)))
+	l1 := len(asm.AsmCodes)
+	asm.Emerge(opcode.Jumpi)
+
+	var reason ast.Expression = &ast.StringLiteral{}
+	if len(args) > 1 {
+		reason = args[1]
+	}
+	if err := compileExpression(reason, asm, tracer, cc); err != nil {
+		return err
+	}
+	asm.Emerge(opcode.Revert)
+
+	end, err := encoding.EncodeOperand(len(asm.AsmCodes))
+	if err != nil {
+		return err
+	}
+	asm.ReplaceOperandAt(l1-1, end)
+
+	return nil
+}
+
+// selectors maps field of reserved identifier to the opcode
+// which produces its value
+var selectors = map[string]opcode.Type{
+	"msg.sender":      opcode.Caller,
+	"block.timestamp": opcode.Timestamp,
+	"block.number":    opcode.Number,
+}
+
+func compileSelectorExpression(e *ast.SelectorExpression, asm *Asm, cc *compileContext) error {
+	if value, ok := cc.constants[e.String()]; ok {
+		return compilePrimitive(value, asm)
+	}
+
+	op, ok := selectors[e.String()]
+	if !ok {
+		return fmt.Errorf("undefined selector %s", e)
+	}
+	asm.Emerge(op)
 	return nil
 }
 
-func compileInfixExpression(e *ast.InfixExpression, asm *Asm, tracer MemTracer) error {
-	if err := compileExpression(e.Left, asm, tracer); err != nil {
+func compileInfixExpression(e *ast.InfixExpression, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if e.Operator == ast.LAND || e.Operator == ast.LOR {
+		return compileLogicalExpression(e, asm, tracer, cc)
+	}
+
+	if err := compileExpression(e.Left, asm, tracer, cc); err != nil {
 		return err
 	}
 
-	if err := compileExpression(e.Right, asm, tracer); err != nil {
+	if err := compileExpression(e.Right, asm, tracer, cc); err != nil {
 		return err
 	}
 
+	// addresses are compared word by word
+	if cc.isAddress(e.Left) || cc.isAddress(e.Right) {
+		asm.Emerge(opcode.AddressEQ)
+		if e.Operator == ast.NOT_EQ {
+			asm.Emerge(opcode.NOT)
+		}
+		return nil
+	}
+
+	// addition, subtraction and multiplication wrap around in the same
+	// way for int and uint, other operators differ for uint
+	if cc.isUnsigned(e) {
+		if op, ok := unsignedOperators[e.Operator]; ok {
+			asm.Emerge(op)
+			return nil
+		}
+	}
+
 	switch e.Operator {
 	case ast.Plus:
 		asm.Emerge(opcode.Add)
+		return compileTruncate(cc.widthOf(e), asm)
 	case ast.Minus:
 		asm.Emerge(opcode.Sub)
+		return compileTruncate(cc.widthOf(e), asm)
 	case ast.Asterisk:
 		asm.Emerge(opcode.Mul)
+		return compileTruncate(cc.widthOf(e), asm)
 	case ast.Slash:
 		asm.Emerge(opcode.Div)
+		return compileTruncate(cc.widthOf(e), asm)
 	case ast.Mod:
 		asm.Emerge(opcode.Mod)
 
@@ -569,11 +1164,6 @@ func compileInfixExpression(e *ast.InfixExpression, asm *Asm, tracer MemTracer)
 	case ast.NOT_EQ:
 		asm.Emerge(opcode.EQ)
 		asm.Emerge(opcode.NOT)
-	case ast.LAND:
-		asm.Emerge(opcode.And)
-	case ast.LOR:
-		asm.Emerge(opcode.Or)
-
 	default:
 		return fmt.Errorf("Undefined operator %s", e.Operator.String())
 	}
@@ -581,8 +1171,8 @@ func compileInfixExpression(e *ast.InfixExpression, asm *Asm, tracer MemTracer)
 	return nil
 }
 
-func compilePrefixExpression(e *ast.PrefixExpression, asm *Asm, tracer MemTracer) error {
-	if err := compileExpression(e.Right, asm, tracer); err != nil {
+func compilePrefixExpression(e *ast.PrefixExpression, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if err := compileExpression(e.Right, asm, tracer, cc); err != nil {
 		return err
 	}
 
@@ -591,6 +1181,7 @@ func compilePrefixExpression(e *ast.PrefixExpression, asm *Asm, tracer MemTracer
 		asm.Emerge(opcode.NOT)
 	case ast.Minus:
 		asm.Emerge(opcode.Minus)
+		return compileTruncate(cc.widthOf(e), asm)
 	default:
 		return fmt.Errorf("unknown operator %s", e.Operator.String())
 	}
@@ -598,7 +1189,84 @@ func compilePrefixExpression(e *ast.PrefixExpression, asm *Asm, tracer MemTracer
 	return nil
 }
 
+// compileLogicalExpression() compiles '&&' and '||' with short-circuit
+// evaluation, right operand is evaluated only when left operand doesn't
+// decide the result.
+//
+// Ex)
+//
+// translate
+//
+//	'a && b'
+//
+// to
+//
+//	'<a> Push <pc-to-false> Jumpi <b> Push <pc-to-end> Jump Push false'
+//
+// and
+//
+//	'a || b'
+//
+// to
+//
+//	'<a> Push <pc-to-right> Jumpi Push true Push <pc-to-end> Jump <b>'
+func compileLogicalExpression(e *ast.InfixExpression, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if err := compileExpression(e.Left, asm, tracer, cc); err != nil {
+		return err
+	}
+
+	asm.Emerge(opcode.Push, []byte(fmt.Sprintf("%d", -1)))
+	l1 := len(asm.AsmCodes)
+	asm.Emerge(opcode.Jumpi)
+
+	// left operand is true here
+	if e.Operator == ast.LAND {
+		if err := compileExpression(e.Right, asm, tracer, cc); err != nil {
+			return err
+		}
+	} else if err := compilePrimitive(true, asm); err != nil {
+		return err
+	}
+
+	asm.Emerge(opcode.Push, []byte(fmt.Sprintf("%d", -1)))
+	l2 := len(asm.AsmCodes)
+	asm.Emerge(opcode.Jump)
+
+	// left operand is false here
+	if e.Operator == ast.LAND {
+		if err := compilePrimitive(false, asm); err != nil {
+			return err
+		}
+	} else if err := compileExpression(e.Right, asm, tracer, cc); err != nil {
+		return err
+	}
+
+	pc2Else, err := encoding.EncodeOperand(l2 + 1)
+	if err != nil {
+		return err
+	}
+	asm.ReplaceOperandAt(l1-1, pc2Else)
+
+	pc2End, err := encoding.EncodeOperand(len(asm.AsmCodes))
+	if err != nil {
+		return err
+	}
+	asm.ReplaceOperandAt(l2-1, pc2End)
+
+	return nil
+}
+
 func compilePrimitive(value interface{}, asm *Asm) error {
+	// address is pushed as its words
+	if address, ok := value.(encoding.Address); ok {
+		for _, word := range address.Words() {
+			if err := compilePrimitive(word, asm); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	operand, err := encoding.EncodeOperand(value)
 	if err != nil {
 		return err
@@ -608,7 +1276,55 @@ func compilePrimitive(value interface{}, asm *Asm) error {
 	return nil
 }
 
-func compileIdentifier(e *ast.Identifier, asm *Asm, tracer MemTracer) error {
+// compileBytesLiteral() compiles byte string literal to Push of the
+// bytes kept in a word.
+//
+// Ex)
+//
+// translate
+// 	'0x"dead"'
+// to
+// 	'Push 0xdead000000000002'
+//
+func compileBytesLiteral(e *ast.BytesLiteral, asm *Asm) error {
+	value, err := encoding.PackBytes(e.Value)
+	if err != nil {
+		return errBytes
+	}
+	return compilePrimitive(value, asm)
+}
+
+// compileIndexExpression() compiles indexing bytes.
+//
+// Ex)
+//
+// translate
+// 	'b[1]'
+// to
+// 	'<b> Push 1 ByteAt'
+//
+func compileIndexExpression(e *ast.IndexExpression, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if err := compileExpression(e.Left, asm, tracer, cc); err != nil {
+		return err
+	}
+
+	if err := compileExpression(e.Index, asm, tracer, cc); err != nil {
+		return err
+	}
+
+	asm.Emerge(opcode.ByteAt)
+	return nil
+}
+
+func compileIdentifier(e *ast.Identifier, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	if value, ok := cc.constants[e.Name]; ok {
+		return compilePrimitive(value, asm)
+	}
+
+	if cc.addresses[e.Name] {
+		return compileLoadAddress(e.Name, asm, tracer)
+	}
+
 	memEntry, err := tracer.Entry(e.Name)
 	if err != nil {
 		return err
@@ -1069,7 +1069,7 @@ func TestCompileFunction(t *testing.T) {
 
 		memTracer := NewMemEntryTable()
 		for _, f := range test.contract.Functions {
-			compileFunction(*f, a, memTracer)
+			compileFunction(*f, a, memTracer, newCompileContext())
 		}
 
 		if memTracer.MemoryCounter != test.expectedTracer.MemoryCounter {
@@ -1287,7 +1287,7 @@ func TestCompileAssignStatement(t *testing.T) {
 
 		memTracer := NewMemEntryTable()
 
-		err := compileAssignStatement(test.statement, a, memTracer)
+		err := compileAssignStatement(test.statement, a, memTracer, newCompileContext())
 		if err != nil {
 			t.Fatalf("test[%d] - compileAssignStatement had error. err=%v",
 				i, err)
@@ -1364,6 +1364,49 @@ func TestCompileReturnStatement(t *testing.T) {
 				},
 			},
 		},
+		// test return multiple values
+		{
+			statement: &ast.ReturnStatement{
+				ReturnValue: &ast.TupleExpression{
+					Elements: []ast.Expression{
+						&ast.IntegerLiteral{Value: 1},
+						&ast.BooleanLiteral{Value: true},
+					},
+				},
+			},
+			expected: Asm{
+				AsmCodes: []AsmCode{
+					{
+						RawByte: []byte{byte(opcode.Push)},
+						Value:   "Push",
+					},
+					{
+						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+						Value:   "0000000000000001",
+					},
+					{
+						RawByte: []byte{byte(opcode.Push)},
+						Value:   "Push",
+					},
+					{
+						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+						Value:   "0000000000000001",
+					},
+					{
+						RawByte: []byte{byte(opcode.Push)},
+						Value:   "Push",
+					},
+					{
+						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02},
+						Value:   "0000000000000002",
+					},
+					{
+						RawByte: []byte{byte(opcode.Returns)},
+						Value:   "Returns",
+					},
+				},
+			},
+		},
 	}
 
 	runStatementCompileTests(t, tests)
@@ -1606,7 +1649,7 @@ func TestCompileIfStatement(t *testing.T) {
 		}
 
 		memTracer := NewMemEntryTable()
-		err := compileIfStatement(test.statement, asm, memTracer)
+		err := compileIfStatement(test.statement, asm, memTracer, newCompileContext())
 		if err != nil && err != test.err {
 			t.Fatalf("test[%d] - TestCompileIfStatement() error wrong. expected=%v, got=%v", i, test.err, err)
 		}
@@ -1669,7 +1712,7 @@ func TestCompileBlockStatement(t *testing.T) {
 
 		memTracer := NewMemEntryTable()
 
-		err := compileBlockStatement(test.statements, a, memTracer)
+		err := compileBlockStatement(test.statements, a, memTracer, newCompileContext())
 
 		if err != nil && err != test.err {
 			t.Fatalf("test[%d] - TestCompileBlockStatement() error wrong. expected=%v, got=%v", i, test.err, err)
@@ -1746,7 +1789,7 @@ func TestCompileExpressionStatement(t *testing.T) {
 			AsmCodes: make([]AsmCode, 0),
 		}
 
-		err := compileExpressionStatement(test.statement, a, test.setupTracer())
+		err := compileExpressionStatement(test.statement, a, test.setupTracer(), newCompileContext())
 		if err != nil && err != test.err {
 			t.Fatalf("test[%d] - TestCompileExpressionStatement() error wrong. expected=%v, got=%v", i, test.err, err)
 		}
@@ -2160,13 +2203,41 @@ func TestCompileInfixExpression(t *testing.T) {
 						RawByte: []byte{byte(opcode.Push)},
 						Value:   "Push",
 					},
+					{
+						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a},
+						Value:   "000000000000000a",
+					},
+					{
+						RawByte: []byte{byte(opcode.Jumpi)},
+						Value:   "Jumpi",
+					},
+					{
+						RawByte: []byte{byte(opcode.Push)},
+						Value:   "Push",
+					},
 					{
 						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02},
 						Value:   "0000000000000002",
 					},
 					{
-						RawByte: []byte{byte(opcode.And)},
-						Value:   "And",
+						RawByte: []byte{byte(opcode.Push)},
+						Value:   "Push",
+					},
+					{
+						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0c},
+						Value:   "000000000000000c",
+					},
+					{
+						RawByte: []byte{byte(opcode.Jump)},
+						Value:   "Jump",
+					},
+					{
+						RawByte: []byte{byte(opcode.Push)},
+						Value:   "Push",
+					},
+					{
+						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+						Value:   "0000000000000000",
 					},
 				},
 			},
@@ -2193,12 +2264,40 @@ func TestCompileInfixExpression(t *testing.T) {
 						Value:   "Push",
 					},
 					{
-						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02},
-						Value:   "0000000000000002",
+						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a},
+						Value:   "000000000000000a",
+					},
+					{
+						RawByte: []byte{byte(opcode.Jumpi)},
+						Value:   "Jumpi",
 					},
 					{
-						RawByte: []byte{byte(opcode.Or)},
-						Value:   "Or",
+						RawByte: []byte{byte(opcode.Push)},
+						Value:   "Push",
+					},
+					{
+						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+						Value:   "0000000000000001",
+					},
+					{
+						RawByte: []byte{byte(opcode.Push)},
+						Value:   "Push",
+					},
+					{
+						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0c},
+						Value:   "000000000000000c",
+					},
+					{
+						RawByte: []byte{byte(opcode.Jump)},
+						Value:   "Jump",
+					},
+					{
+						RawByte: []byte{byte(opcode.Push)},
+						Value:   "Push",
+					},
+					{
+						RawByte: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02},
+						Value:   "0000000000000002",
 					},
 				},
 			},
@@ -2858,13 +2957,13 @@ func runExpressionCompileTests(t *testing.T, tests []expressionCompileTestCase)
 			err = compilePrimitive(expr.Value, asm)
 		case *ast.PrefixExpression:
 			testFuncName = "compilePrefixExpression()"
-			err = compilePrefixExpression(expr, asm, tracer)
+			err = compilePrefixExpression(expr, asm, tracer, newCompileContext())
 		case *ast.InfixExpression:
 			testFuncName = "compileInfixExpression()"
-			err = compileInfixExpression(expr, asm, tracer)
+			err = compileInfixExpression(expr, asm, tracer, newCompileContext())
 		case *ast.Identifier:
 			testFuncName = "compileIdentifier()"
-			err = compileIdentifier(expr, asm, tracer)
+			err = compileIdentifier(expr, asm, tracer, newCompileContext())
 		default:
 			t.Fatalf("%T type not support, abort.", expr)
 			t.FailNow()
@@ -2901,7 +3000,7 @@ func runStatementCompileTests(t *testing.T, tests []statementCompileTestCase) {
 		switch stmt := test.statement.(type) {
 		case *ast.ReturnStatement:
 			testFuncName = "compileReturnStatement()"
-			err = compileReturnStatement(stmt, asm, tracer)
+			err = compileReturnStatement(stmt, asm, tracer, newCompileContext())
 		default:
 			t.Fatalf("%T type not support, abort.", stmt)
 			t.FailNow()
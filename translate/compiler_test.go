@@ -17,9 +17,15 @@
 package translate_test
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/DE-labtory/koa/abi"
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/parse"
 	"github.com/DE-labtory/koa/translate"
 )
 
@@ -28,6 +34,89 @@ func TestCompileContract(t *testing.T) {
 
 }
 
+func TestCompileContract_selectorCollision(t *testing.T) {
+	// f8491() and f130736() share selector 62018627
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(`
+contract {
+	func f8491() int {
+		return 1
+	}
+	func f130736() int {
+		return 2
+	}
+}`)))
+	if err != nil {
+		t.Fatalf("Parse() returns unexpected error: %s", err)
+	}
+
+	_, err = translate.CompileContract(*contract)
+
+	expected := "function selector 62018627 of f130736() collides with f8491()"
+	if err == nil || err.Error() != expected {
+		t.Errorf("CompileContract() wrong error. expected=%s, got=%v", expected, err)
+	}
+	if !errors.Is(err, translate.ErrCompile) {
+		t.Errorf("CompileContract() error %v is not compile error", err)
+	}
+}
+
+func TestCompileContract_concurrent(t *testing.T) {
+	sources := []string{`
+contract {
+	const uint MAX = 10
+	error Small(got int)
+	func f(a uint) uint {
+		return a / MAX
+	}
+}`, `
+contract {
+	const int8 MAX = 100
+	func g(a int8) int8 {
+		if (a > MAX) {
+			return a
+		}
+		return a * 2
+	}
+}`}
+
+	contracts := make([]*ast.Contract, len(sources))
+	expected := make([]translate.Asm, len(sources))
+	for i, src := range sources {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(src)))
+		if err != nil {
+			t.Fatalf("Parse() returns unexpected error: %s", err)
+		}
+		contracts[i] = contract
+
+		if expected[i], err = translate.CompileContract(*contract); err != nil {
+			t.Fatalf("CompileContract() returns unexpected error: %s", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for n := 0; n < 100; n++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			asm, err := translate.CompileContract(*contracts[i])
+			if err != nil {
+				errs <- err
+				return
+			}
+			if !asm.Equal(expected[i]) {
+				errs <- fmt.Errorf("contract[%d] compiled differently\n%s", i, asm.String())
+			}
+		}(n % len(contracts))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
 func TestFuncMap_Declare(t *testing.T) {
 	tests := []struct {
 		signature string
@@ -98,3 +187,37 @@ func TestFuncMap_Declare(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractInterfaceAbi(t *testing.T) {
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(`
+interface Token {
+	func balance(owner int) int
+	func transfer(to int, amount int) bool
+}
+contract implements Token {
+	func balance(owner int) int {
+		return 0
+	}
+	func transfer(to int, amount int) bool {
+		return true
+	}
+}`)))
+	if err != nil {
+		t.Fatalf("Parse() returns unexpected error: %s", err)
+	}
+
+	shared, err := translate.ExtractInterfaceAbi(*contract.Interfaces[0])
+	if err != nil {
+		t.Fatalf("ExtractInterfaceAbi() returns unexpected error: %s", err)
+	}
+
+	implemented, err := translate.ExtractAbi(*contract)
+	if err != nil {
+		t.Fatalf("ExtractAbi() returns unexpected error: %s", err)
+	}
+
+	// ABI of contract starts with the functions of interface
+	if !reflect.DeepEqual(shared.Methods, implemented.Methods[:len(shared.Methods)]) {
+		t.Errorf("ExtractInterfaceAbi() differs from the ABI of contract.\nexpected=%v\ngot=%v", implemented, shared)
+	}
+}
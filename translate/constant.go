@@ -0,0 +1,212 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"fmt"
+
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/encoding"
+)
+
+// ConstError occurs when initializer of constant can't be
+// evaluated at compile time
+type ConstError struct {
+	Name   string
+	Reason string
+}
+
+func (e ConstError) Error() string {
+	return fmt.Sprintf("[const %s] %s", e.Name, e.Reason)
+}
+
+func (e ConstError) Is(target error) bool {
+	return target == ErrCompile
+}
+
+// foldConstants evaluates initializers of constants in the order of
+// declaration, so initializer can refer to the constants declared before it
+// and the members of enums
+func foldConstants(cs []*ast.ConstStatement, enums []*ast.EnumLiteral) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, e := range enums {
+		for i, m := range e.Members {
+			values[e.Name.Name+"."+m.Name] = int64(i)
+		}
+	}
+
+	for _, c := range cs {
+		v, err := evalConstant(c.Value, values)
+		if err != nil {
+			return nil, ConstError{c.Name.Name, err.Error()}
+		}
+		if n, ok := v.(int64); ok && !fitsWidth(n, c.Type.Width()) {
+			return nil, ConstError{c.Name.Name, fmt.Sprintf("constant %d overflows %s", n, c.Type)}
+		}
+		values[c.Name.Name] = v
+	}
+
+	return values, nil
+}
+
+// evalConstant evaluates constant expression to int64, bool or string
+func evalConstant(e ast.Expression, values map[string]interface{}) (interface{}, error) {
+	switch expr := e.(type) {
+	case *ast.IntegerLiteral:
+		return expr.Value, nil
+
+	case *ast.BooleanLiteral:
+		return expr.Value, nil
+
+	case *ast.StringLiteral:
+		return expr.Value, nil
+
+	case *ast.AddressLiteral:
+		return expr.Value, nil
+
+	case *ast.BytesLiteral:
+		value, err := encoding.PackBytes(expr.Value)
+		if err != nil {
+			return nil, errBytes
+		}
+		return value, nil
+
+	case *ast.Identifier:
+		v, ok := values[expr.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a constant", expr.Name)
+		}
+		return v, nil
+
+	case *ast.SelectorExpression:
+		v, ok := values[expr.String()]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a constant", expr)
+		}
+		return v, nil
+
+	case *ast.PrefixExpression:
+		return evalConstantPrefix(expr, values)
+
+	case *ast.InfixExpression:
+		return evalConstantInfix(expr, values)
+
+	default:
+		return nil, fmt.Errorf("%s is not a constant expression", e.String())
+	}
+}
+
+func evalConstantPrefix(e *ast.PrefixExpression, values map[string]interface{}) (interface{}, error) {
+	right, err := evalConstant(e.Right, values)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r := right.(type) {
+	case int64:
+		if e.Operator == ast.Minus {
+			return -r, nil
+		}
+	case bool:
+		if e.Operator == ast.Bang {
+			return !r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid operation %s", e.String())
+}
+
+func evalConstantInfix(e *ast.InfixExpression, values map[string]interface{}) (interface{}, error) {
+	left, err := evalConstant(e.Left, values)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := evalConstant(e.Right, values)
+	if err != nil {
+		return nil, err
+	}
+
+	switch l := left.(type) {
+	case int64:
+		if r, ok := right.(int64); ok {
+			return evalIntegerInfix(e, l, r)
+		}
+	case bool:
+		if r, ok := right.(bool); ok {
+			return evalBooleanInfix(e, l, r)
+		}
+	case encoding.Address:
+		if r, ok := right.(encoding.Address); ok && (e.Operator == ast.EQ || e.Operator == ast.NOT_EQ) {
+			return (l == r) == (e.Operator == ast.EQ), nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid operation %s", e.String())
+}
+
+func evalIntegerInfix(e *ast.InfixExpression, l, r int64) (interface{}, error) {
+	switch e.Operator {
+	case ast.Plus:
+		return l + r, nil
+	case ast.Minus:
+		return l - r, nil
+	case ast.Asterisk:
+		return l * r, nil
+	case ast.Slash, ast.Mod:
+		// vm rounds division of negative numbers differently from Go
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero in %s", e.String())
+		}
+		if l < 0 || r < 0 {
+			return nil, fmt.Errorf("division of negative constant in %s is not supported", e.String())
+		}
+		if e.Operator == ast.Slash {
+			return l / r, nil
+		}
+		return l % r, nil
+	case ast.LT:
+		return l < r, nil
+	case ast.GT:
+		return l > r, nil
+	case ast.LTE:
+		return l <= r, nil
+	case ast.GTE:
+		return l >= r, nil
+	case ast.EQ:
+		return l == r, nil
+	case ast.NOT_EQ:
+		return l != r, nil
+	}
+
+	return nil, fmt.Errorf("invalid operation %s", e.String())
+}
+
+func evalBooleanInfix(e *ast.InfixExpression, l, r bool) (interface{}, error) {
+	switch e.Operator {
+	case ast.LAND:
+		return l && r, nil
+	case ast.LOR:
+		return l || r, nil
+	case ast.EQ:
+		return l == r, nil
+	case ast.NOT_EQ:
+		return l != r, nil
+	}
+
+	return nil, fmt.Errorf("invalid operation %s", e.String())
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DE-labtory/koa/ast"
+)
+
+func TestFoldConstants(t *testing.T) {
+	constant := func(name string, value ast.Expression) *ast.ConstStatement {
+		return &ast.ConstStatement{Type: ast.IntType, Name: ast.Identifier{Name: name}, Value: value}
+	}
+	infix := func(left ast.Expression, op ast.Operator, right ast.Expression) ast.Expression {
+		return &ast.InfixExpression{Left: left, Operator: op, Right: right}
+	}
+
+	tests := []struct {
+		constants   []*ast.ConstStatement
+		expected    map[string]interface{}
+		expectedErr string
+	}{
+		{
+			constants: []*ast.ConstStatement{
+				constant("FEE", &ast.IntegerLiteral{Value: 100}),
+				constant("DOUBLE", infix(&ast.Identifier{Name: "FEE"}, ast.Asterisk, &ast.IntegerLiteral{Value: 2})),
+				constant("NEG", &ast.PrefixExpression{Operator: ast.Minus, Right: &ast.Identifier{Name: "DOUBLE"}}),
+				constant("BIG", infix(&ast.Identifier{Name: "FEE"}, ast.GT, &ast.IntegerLiteral{Value: 10})),
+				constant("NAME", &ast.StringLiteral{Value: "koa"}),
+			},
+			expected: map[string]interface{}{
+				"FEE":    int64(100),
+				"DOUBLE": int64(200),
+				"NEG":    int64(-200),
+				"BIG":    true,
+				"NAME":   "koa",
+			},
+		},
+		{
+			constants: []*ast.ConstStatement{
+				constant("A", &ast.Identifier{Name: "B"}),
+				constant("B", &ast.IntegerLiteral{Value: 1}),
+			},
+			expectedErr: "[const A] B is not a constant",
+		},
+		{
+			constants: []*ast.ConstStatement{
+				constant("A", infix(&ast.IntegerLiteral{Value: 1}, ast.Slash, &ast.IntegerLiteral{Value: 0})),
+			},
+			expectedErr: "[const A] division by zero in (1 / 0)",
+		},
+		{
+			constants: []*ast.ConstStatement{
+				constant("A", &ast.CallExpression{Function: &ast.Identifier{Name: "foo"}}),
+			},
+			expectedErr: "[const A] function foo(  ) is not a constant expression",
+		},
+	}
+
+	for i, test := range tests {
+		values, err := foldConstants(test.constants, nil)
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - foldConstants() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			if !errors.Is(err, ErrCompile) {
+				t.Errorf("test[%d] - foldConstants() error should match ErrCompile", i)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - foldConstants() returns unexpected error: %s", i, err)
+			continue
+		}
+		for name, expected := range test.expected {
+			if values[name] != expected {
+				t.Errorf("test[%d] - foldConstants() wrong value of %s. expected=%v, got=%v", i, name, expected, values[name])
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/DE-labtory/koa/abi"
+	"github.com/DE-labtory/koa/ast"
+)
+
+// supportsInterface is the name of function which is generated for
+// the contract with pragma erc165, like ERC-165
+const supportsInterface = "supportsInterface"
+
+// InterfaceID returns the id of interface, which is xor of the
+// selectors of its functions as ERC-165 does
+func InterfaceID(i ast.Interface) []byte {
+	id := make([]byte, 4)
+	for _, f := range i.Functions {
+		for j, b := range abi.Selector(f.Signature()) {
+			id[j] ^= b
+		}
+	}
+	return id
+}
+
+// withSupportsInterface returns contract with generated
+//
+//	func supportsInterface(id int) bool
+//
+// which returns whether contract implements the interface with id.
+// Interface of supportsInterface itself is also supported. Nothing
+// is generated unless contract has pragma erc165, and contract with
+// the pragma can't declare supportsInterface itself.
+func withSupportsInterface(c ast.Contract) (ast.Contract, error) {
+	if !c.SupportsInterface {
+		return c, nil
+	}
+	for _, f := range c.Functions {
+		if f.Name.Name == supportsInterface {
+			return c, fmt.Errorf("function %s collides with %s generated by pragma erc165", f.Signature(), supportsInterface)
+		}
+	}
+
+	interfaces := make(map[string]*ast.Interface)
+	for _, i := range c.Interfaces {
+		interfaces[i.Name.Name] = i
+	}
+
+	id := &ast.Identifier{Name: "id"}
+	fn := &ast.FunctionLiteral{
+		Name: &ast.Identifier{Name: supportsInterface},
+		Parameters: []*ast.ParameterLiteral{
+			{Identifier: id, Type: ast.IntType},
+		},
+		ReturnType: ast.BoolType,
+	}
+
+	ids := [][]byte{InterfaceID(ast.Interface{Functions: []*ast.FunctionLiteral{fn}})}
+	for _, name := range c.Implements {
+		if i, ok := interfaces[name.Name]; ok {
+			ids = append(ids, InterfaceID(*i))
+		}
+	}
+
+	var cond ast.Expression
+	for _, b := range ids {
+		eq := &ast.InfixExpression{
+			Left:     id,
+			Operator: ast.EQ,
+			Right:    &ast.IntegerLiteral{Value: int64(binary.BigEndian.Uint32(b))},
+		}
+		if cond == nil {
+			cond = eq
+			continue
+		}
+		cond = &ast.InfixExpression{Left: cond, Operator: ast.LOR, Right: eq}
+	}
+
+	fn.Body = &ast.BlockStatement{
+		Statements: []ast.Statement{&ast.ReturnStatement{NodePos: ast.NodePos{Pos: ast.Pos{Synthetic: true}}, ReturnValue: cond}},
+	}
+
+	functions := make([]*ast.FunctionLiteral, 0, len(c.Functions)+1)
+	c.Functions = append(append(functions, c.Functions...), fn)
+	return c, nil
+}
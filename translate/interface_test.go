@@ -0,0 +1,171 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/DE-labtory/koa/abi"
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/translate"
+)
+
+func TestInterfaceID(t *testing.T) {
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(`
+interface Empty {
+}
+interface Balance {
+	func balance(owner int) int
+}
+interface Token {
+	func balance(owner int) int
+	func transfer(to int, amount int) bool
+}
+contract {
+}`)))
+	if err != nil {
+		t.Fatalf("Parse() returns unexpected error: %s", err)
+	}
+
+	balance := abi.Selector("balance(int)")
+	transfer := abi.Selector("transfer(int,int)")
+	token := make([]byte, 4)
+	for i := range token {
+		token[i] = balance[i] ^ transfer[i]
+	}
+
+	tests := []struct {
+		i        *ast.Interface
+		expected []byte
+	}{
+		{contract.Interfaces[0], []byte{0, 0, 0, 0}},
+		{contract.Interfaces[1], balance},
+		{contract.Interfaces[2], token},
+	}
+
+	for i, test := range tests {
+		if id := translate.InterfaceID(*test.i); !bytes.Equal(id, test.expected) {
+			t.Errorf("test[%d] - InterfaceID() wrong result. expected=%x, got=%x", i, test.expected, id)
+		}
+	}
+}
+
+func TestExtractAbi_supportsInterface(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			input: `
+interface Balance {
+	func balance(owner int) int
+}
+contract implements Balance {
+	func balance(owner int) int {
+		return 0
+	}
+}`,
+			expected: []string{"balance(int)"},
+		},
+		{
+			input: `
+pragma erc165
+interface Balance {
+	func balance(owner int) int
+}
+contract implements Balance {
+	func balance(owner int) int {
+		return 0
+	}
+}`,
+			expected: []string{"balance(int)", "supportsInterface(int)"},
+		},
+		{
+			input: `
+pragma erc165
+contract {
+	func balance(owner int) int {
+		return 0
+	}
+}`,
+			expected: []string{"balance(int)", "supportsInterface(int)"},
+		},
+		{
+			input: `
+interface Balance {
+	func balance(owner int) int
+}
+contract implements Balance {
+	func supportsInterface(id int) bool {
+		return false
+	}
+	func balance(owner int) int {
+		return 0
+	}
+}`,
+			expected: []string{"supportsInterface(int)", "balance(int)"},
+		},
+		{
+			input: `
+pragma erc165
+contract {
+	func supportsInterface(id int) int {
+		return 0
+	}
+}`,
+			expectedErr: "function supportsInterface(int) collides with supportsInterface generated by pragma erc165",
+		},
+	}
+
+	for i, test := range tests {
+		contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(test.input)))
+		if err != nil {
+			t.Fatalf("test[%d] - Parse() returns unexpected error: %s", i, err)
+		}
+
+		a, err := translate.ExtractAbi(*contract)
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - ExtractAbi() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			if _, err := translate.CompileContract(*contract); err == nil || !errors.Is(err, translate.ErrCompile) {
+				t.Errorf("test[%d] - CompileContract() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("test[%d] - ExtractAbi() returns unexpected error: %s", i, err)
+		}
+
+		signatures := make([]string, 0)
+		for _, m := range a.Methods {
+			signatures = append(signatures, m.Signature())
+		}
+		if len(signatures) != len(test.expected) {
+			t.Fatalf("test[%d] - ExtractAbi() wrong methods. expected=%v, got=%v", i, test.expected, signatures)
+		}
+		for j, s := range signatures {
+			if s != test.expected[j] {
+				t.Errorf("test[%d] - ExtractAbi() wrong methods. expected=%v, got=%v", i, test.expected, signatures)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"context"
+
+	"github.com/DE-labtory/koa/abi"
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/opcode"
+)
+
+// FunctionReport describes the compiled bytecode of a function,
+// so that the cost of each function can be seen at build time
+type FunctionReport struct {
+	Signature string
+
+	// Size is the size of the bytecode of function in bytes
+	Size int
+
+	// MaxStack is the estimated maximum number of items function
+	// keeps on the stack. It is counted along the bytecode without
+	// following jumps, so the branches are counted one after another.
+	MaxStack int
+}
+
+// ReportFunctions compiles contract and returns FunctionReport of
+// each function in the order of function jumper
+func ReportFunctions(c ast.Contract) ([]FunctionReport, error) {
+	asm, funcMap, err := compileContract(context.Background(), c)
+	if err != nil {
+		return nil, Error{err}
+	}
+
+	c, err = withSupportsInterface(c)
+	if err != nil {
+		return nil, err
+	}
+
+	functions := c.Functions
+	reports := make([]FunctionReport, 0, len(functions))
+	for i, f := range functions {
+		start := funcMap[string(abi.Selector(f.Signature()))]
+		end := len(asm.AsmCodes)
+		if i+1 < len(functions) {
+			end = funcMap[string(abi.Selector(functions[i+1].Signature()))]
+		}
+
+		code := (&Asm{AsmCodes: asm.AsmCodes[start:end]}).ToRawByteCode()
+		reports = append(reports, FunctionReport{
+			Signature: f.Signature(),
+			Size:      len(code),
+			MaxStack:  maxStack(code),
+		})
+	}
+
+	return reports, nil
+}
+
+// maxStack returns the maximum stack depth reached by bytecode
+// executed from the first to the last instruction. Depth never goes
+// below zero, because items under the function belong to the caller.
+func maxStack(code []byte) int {
+	depth, max := 0, 0
+	for i := 0; i < len(code); i++ {
+		spec, ok := opcode.SpecOf(opcode.Type(code[i]))
+		if !ok {
+			continue
+		}
+
+		depth -= spec.Pops
+		if depth < 0 {
+			depth = 0
+		}
+		depth += spec.Pushes
+		if depth > max {
+			max = depth
+		}
+		i += spec.Operand
+	}
+	return max
+}
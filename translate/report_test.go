@@ -0,0 +1,55 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/translate"
+)
+
+func TestReportFunctions(t *testing.T) {
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(`
+contract {
+	func add(a int, b int) int {
+		return a + b
+	}
+	func one() int {
+		return 1
+	}
+}`)))
+	if err != nil {
+		t.Fatalf("Parse() returns unexpected error: %s", err)
+	}
+
+	reports, err := translate.ReportFunctions(*contract)
+	if err != nil {
+		t.Fatalf("ReportFunctions() returns unexpected error: %s", err)
+	}
+
+	// add: 2 x (Push LoadArgs Push Push Mstore) + 2 x (Push Push Mload) + Add Returning
+	// one: Push Returning
+	expected := []translate.FunctionReport{
+		{Signature: "add(int,int)", Size: 98, MaxStack: 3},
+		{Signature: "one()", Size: 10, MaxStack: 1},
+	}
+	if !reflect.DeepEqual(reports, expected) {
+		t.Errorf("ReportFunctions() wrong result.\nexpected=%v\ngot=%v", expected, reports)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"fmt"
+
+	"github.com/DE-labtory/koa/abi"
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/opcode"
+)
+
+// declareErrors records the selectors of custom errors
+func (cc *compileContext) declareErrors(errs []*ast.ErrorLiteral) error {
+	for _, e := range errs {
+		m, err := abi.ExtractAbiFromError(*e)
+		if err != nil {
+			return err
+		}
+		cc.errorSelectors[e.Name.Name] = m.ID()
+	}
+	return nil
+}
+
+// compileRevertStatement() compiles 'revert Insufficient(a, b)', which
+// aborts with the selector of error and its arguments. Arguments are
+// pushed in reverse order, so that the first one is popped first.
+//
+// Ex)
+//
+// translate
+//
+//	'revert Insufficient(a, b)'
+//
+// to
+//
+//	'<b> <a> Push <selector> Push 2 Raise'
+func compileRevertStatement(s *ast.RevertStatement, asm *Asm, tracer MemTracer, cc *compileContext) error {
+	selector, ok := cc.errorSelectors[s.Error.Name]
+	if !ok {
+		return fmt.Errorf("compileRevertStatement() error - undefined error %s", s.Error.Name)
+	}
+
+	for i := len(s.Arguments) - 1; i >= 0; i-- {
+		if err := compileExpression(s.Arguments[i], asm, tracer, cc); err != nil {
+			return err
+		}
+	}
+
+	if err := compilePrimitive(selector, asm); err != nil {
+		return err
+	}
+	if err := compilePrimitive(len(s.Arguments), asm); err != nil {
+		return err
+	}
+	asm.Emerge(opcode.Raise)
+
+	return nil
+}
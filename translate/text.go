@@ -0,0 +1,161 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/DE-labtory/koa/opcode"
+)
+
+// Text format of compiled program has one instruction per line.
+// Operator is written with its name, and Push is followed by its
+// 8 bytes operand in hexadecimal with 0x prefix. Everything after
+// ';' is comment.
+//
+//	Push 0x0000000000000001
+//	Push 0x0000000000000002
+//	Add ; 1 + 2
+//
+// Text() emits the index of JumpDst as comment, which is the
+// operand jump instructions use to get there.
+
+// OperandSize is the size of Push operand in bytes
+const OperandSize = 8
+
+// commentPrefix starts comment in text format
+const commentPrefix = ";"
+
+// TextError happens when text format of program can't be read
+type TextError struct {
+	Line   int
+	Reason string
+}
+
+func (e TextError) Error() string {
+	return fmt.Sprintf("[line %d] %s", e.Line, e.Reason)
+}
+
+// Disassemble converts raw bytecode back to Asm
+func Disassemble(rawByteCode []byte) (Asm, error) {
+	asm := Asm{
+		AsmCodes: make([]AsmCode, 0),
+	}
+
+	for i := 0; i < len(rawByteCode); i++ {
+		operator := opcode.Type(rawByteCode[i])
+		spec, ok := opcode.SpecOf(operator)
+		if !ok {
+			return Asm{}, fmt.Errorf("Disassemble() error - invalid opcode %02x at %d", rawByteCode[i], i)
+		}
+
+		if spec.Operand == 0 {
+			asm.Emerge(operator)
+			continue
+		}
+
+		if i+spec.Operand >= len(rawByteCode) {
+			return Asm{}, fmt.Errorf("Disassemble() error - operand of %s at %d is shorter than %d bytes", spec.Name, i, spec.Operand)
+		}
+		operand := make([]byte, spec.Operand)
+		copy(operand, rawByteCode[i+1:i+1+spec.Operand])
+
+		asm.Emerge(operator, operand)
+		i += spec.Operand
+	}
+
+	return asm, nil
+}
+
+// Text renders asm in text format
+func (a *Asm) Text() string {
+	var out bytes.Buffer
+
+	for i := 0; i < len(a.AsmCodes); i++ {
+		code := a.AsmCodes[i]
+		out.WriteString(code.Value)
+
+		switch {
+		case len(code.RawByte) == 1 && opcode.Type(code.RawByte[0]) == opcode.Push && i+1 < len(a.AsmCodes):
+			i++
+			out.WriteString(" 0x" + hex.EncodeToString(a.AsmCodes[i].RawByte))
+		case len(code.RawByte) == 1 && opcode.Type(code.RawByte[0]) == opcode.JumpDst:
+			out.WriteString(fmt.Sprintf(" %s %d", commentPrefix, i))
+		}
+
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+// ParseText reads program in text format, the result has the same
+// bytecode with the program which text was rendered from
+func ParseText(text string) (Asm, error) {
+	asm := Asm{
+		AsmCodes: make([]AsmCode, 0),
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		fields := asmFields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		operator, err := opcode.Lookup(fields[0])
+		if err != nil {
+			return Asm{}, TextError{i + 1, fmt.Sprintf("unknown operator %s", fields[0])}
+		}
+
+		if operator != opcode.Push {
+			if len(fields) != 1 {
+				return Asm{}, TextError{i + 1, fmt.Sprintf("%s doesn't take operand", fields[0])}
+			}
+			asm.Emerge(operator)
+			continue
+		}
+
+		if len(fields) != 2 {
+			return Asm{}, TextError{i + 1, "Push takes exactly one operand"}
+		}
+
+		operand, err := parseOperand(fields[1])
+		if err != nil {
+			return Asm{}, TextError{i + 1, err.Error()}
+		}
+		asm.Emerge(operator, operand)
+	}
+
+	return asm, nil
+}
+
+// parseOperand decodes 0x prefixed hexadecimal operand of Push
+func parseOperand(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return nil, fmt.Errorf("operand %s should start with 0x", s)
+	}
+
+	operand, err := hex.DecodeString(s[2:])
+	if err != nil || len(operand) != OperandSize {
+		return nil, fmt.Errorf("operand %s should be %d bytes in hexadecimal", s, OperandSize)
+	}
+
+	return operand, nil
+}
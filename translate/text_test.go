@@ -0,0 +1,151 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DE-labtory/koa/opcode"
+	"github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/translate"
+)
+
+func TestAsm_Text(t *testing.T) {
+	asm := translate.Asm{}
+	asm.Emerge(opcode.Push, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04})
+	asm.Emerge(opcode.Jump)
+	asm.Emerge(opcode.JumpDst)
+	asm.Emerge(opcode.Push, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01})
+	asm.Emerge(opcode.Returning)
+
+	expected := `Push 0x0000000000000004
+Jump
+JumpDst ; 3
+Push 0x0000000000000001
+Returning
+`
+	if text := asm.Text(); text != expected {
+		t.Errorf("Text() wrong result.\nexpected=%s\ngot=%s", expected, text)
+	}
+}
+
+func TestParseText(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    []byte
+		expectedErr string
+	}{
+		{
+			input: `
+; adds two numbers
+Push 0x0000000000000001
+Push 0x0000000000000002   ; second operand
+Add
+`,
+			expected: []byte{
+				byte(opcode.Push), 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+				byte(opcode.Push), 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+				byte(opcode.Add),
+			},
+		},
+		{
+			input:       "Push 0x01",
+			expectedErr: "[line 1] operand 0x01 should be 8 bytes in hexadecimal",
+		},
+		{
+			input:       "Push 1",
+			expectedErr: "[line 1] operand 1 should start with 0x",
+		},
+		{
+			input:       "Push",
+			expectedErr: "[line 1] Push takes exactly one operand",
+		},
+		{
+			input:       "\nAdd 0x0000000000000001",
+			expectedErr: "[line 2] Add doesn't take operand",
+		},
+		{
+			input:       "Plus",
+			expectedErr: "[line 1] unknown operator Plus",
+		},
+	}
+
+	for i, test := range tests {
+		asm, err := translate.ParseText(test.input)
+		if test.expectedErr != "" {
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("test[%d] - ParseText() wrong error. expected=%s, got=%v", i, test.expectedErr, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("test[%d] - ParseText() returns unexpected error: %s", i, err)
+			continue
+		}
+		if raw := asm.ToRawByteCode(); !bytes.Equal(raw, test.expected) {
+			t.Errorf("test[%d] - ParseText() wrong bytecode. expected=%x, got=%x", i, test.expected, raw)
+		}
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	input := `
+contract {
+	func add(a int, b int) int {
+		if (a > b) {
+			return a + b
+		}
+		return b
+	}
+}`
+
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(input)))
+	if err != nil {
+		t.Fatalf("parser error: %s", err)
+	}
+
+	compiled, err := translate.CompileContract(*contract)
+	if err != nil {
+		t.Fatalf("compile error: %s", err)
+	}
+
+	asm, err := translate.Disassemble(compiled.ToRawByteCode())
+	if err != nil {
+		t.Fatalf("Disassemble() returns unexpected error: %s", err)
+	}
+	if !asm.Equal(compiled) {
+		t.Fatalf("Disassemble() wrong result.\nexpected=%s\ngot=%s", compiled.String(), asm.String())
+	}
+
+	parsed, err := translate.ParseText(asm.Text())
+	if err != nil {
+		t.Fatalf("ParseText() returns unexpected error: %s", err)
+	}
+	if !bytes.Equal(parsed.ToRawByteCode(), compiled.ToRawByteCode()) {
+		t.Errorf("round trip of text format changed bytecode.\nexpected=%x\ngot=%x",
+			compiled.ToRawByteCode(), parsed.ToRawByteCode())
+	}
+
+	if _, err := translate.Disassemble([]byte{0xff}); err == nil {
+		t.Errorf("Disassemble() should fail on invalid opcode")
+	}
+	if _, err := translate.Disassemble([]byte{byte(opcode.Push), 0x01}); err == nil {
+		t.Errorf("Disassemble() should fail on short operand")
+	}
+}
@@ -30,6 +30,10 @@ func (e EntryError) Error() string {
 	return fmt.Sprintf("[%s] definition doesn't exist", e.Id)
 }
 
+func (e EntryError) Is(target error) bool {
+	return target == ErrCompile
+}
+
 type MemTracer interface {
 	MemDefiner
 	MemGetter
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/opcode"
+)
+
+// unsignedOperators are opcodes for operators which are
+// different when operands are uint
+var unsignedOperators = map[ast.Operator]opcode.Type{
+	ast.Slash: opcode.UDiv,
+	ast.Mod:   opcode.UMod,
+	ast.LT:    opcode.ULT,
+	ast.LTE:   opcode.ULTE,
+	ast.GT:    opcode.UGT,
+	ast.GTE:   opcode.UGTE,
+}
+
+// isUnsigned reports whether operands of infix expression are uint.
+// Type checker guarantees both operands have the same type, except
+// integer literal which takes the type of the other operand.
+func (cc *compileContext) isUnsigned(e *ast.InfixExpression) bool {
+	return cc.isUnsignedOperand(e.Left) || cc.isUnsignedOperand(e.Right)
+}
+
+func (cc *compileContext) isUnsignedOperand(e ast.Expression) bool {
+	switch expr := e.(type) {
+	case *ast.Identifier:
+		return cc.unsigned[expr.Name]
+	case *ast.InfixExpression:
+		switch expr.Operator {
+		case ast.Plus, ast.Minus, ast.Asterisk, ast.Slash, ast.Mod:
+			return cc.isUnsigned(expr)
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/encoding"
+	"github.com/DE-labtory/koa/opcode"
+)
+
+// sized reports whether ds is integer narrower than vm word
+func sized(ds ast.DataStructure) bool {
+	return 0 < ds.Width() && ds.Width() < 64
+}
+
+// declareWidth records the width of variable name of type ds
+func (cc *compileContext) declareWidth(name string, ds ast.DataStructure) {
+	if sized(ds) {
+		cc.widths[name] = ds.Width()
+		return
+	}
+	delete(cc.widths, name)
+}
+
+// widthOf returns the width of the integer value e evaluates to,
+// or 0 if e is untyped integer literal
+func (cc *compileContext) widthOf(e ast.Expression) int {
+	switch expr := e.(type) {
+	case *ast.IntegerLiteral:
+		return 0
+	case *ast.Identifier:
+		if w, ok := cc.widths[expr.Name]; ok {
+			return w
+		}
+		return 64
+	case *ast.PrefixExpression:
+		return cc.widthOf(expr.Right)
+	case *ast.InfixExpression:
+		l, r := cc.widthOf(expr.Left), cc.widthOf(expr.Right)
+		if l > r {
+			return l
+		}
+		return r
+	default:
+		return 64
+	}
+}
+
+// fitsWidth reports whether v can be represented by signed
+// integer of width bits
+func fitsWidth(v int64, width int) bool {
+	if width <= 0 || width >= 64 {
+		return true
+	}
+	limit := int64(1) << uint(width-1)
+	return -limit <= v && v < limit
+}
+
+// compileTruncate wraps the value on top of the stack around to
+// signed integer of width bits, which is
+//
+//	(value + 2^(width-1)) umod 2^width - 2^(width-1)
+func compileTruncate(width int, asm *Asm) error {
+	if width <= 0 || width >= 64 {
+		return nil
+	}
+
+	half, err := encoding.EncodeOperand(int64(1) << uint(width-1))
+	if err != nil {
+		return err
+	}
+	modulus, err := encoding.EncodeOperand(int64(1) << uint(width))
+	if err != nil {
+		return err
+	}
+
+	asm.Emerge(opcode.Push, half)
+	asm.Emerge(opcode.Add)
+	asm.Emerge(opcode.Push, modulus)
+	asm.Emerge(opcode.UMod)
+	asm.Emerge(opcode.Push, half)
+	asm.Emerge(opcode.Sub)
+	return nil
+}
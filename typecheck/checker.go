@@ -0,0 +1,1035 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package typecheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/symbol"
+)
+
+// invalidType is used for expressions whose type could not be
+// determined. Errors are not reported again for expressions which
+// have invalid operands, so a single mistake doesn't cascade.
+const invalidType ast.DataStructure = 0
+
+// ErrType is matched by Error and Errors,
+// use errors.Is(err, ErrType) to distinguish them from the errors
+// of other phases
+var ErrType = errors.New("type error")
+
+// Error contains a type error which happened during
+// checking the contract. Pos is set when the parser recorded
+// where Source starts.
+type Error struct {
+	Pos    ast.Pos
+	Source ast.Node
+	Reason string
+}
+
+func (e Error) Error() string {
+	if e.Pos.IsValid() {
+		return fmt.Sprintf("[%s] [%s] %s", e.Pos, e.Source.String(), e.Reason)
+	}
+	return fmt.Sprintf("[%s] %s", e.Source.String(), e.Reason)
+}
+
+func (e Error) Is(target error) bool {
+	return target == ErrType
+}
+
+// Errors is the list of every type error found in a contract
+type Errors []Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e Errors) Is(target error) bool {
+	return target == ErrType
+}
+
+// As sets target to the first error of the list when target is *Error
+func (e Errors) As(target interface{}) bool {
+	t, ok := target.(*Error)
+	if !ok || len(e) == 0 {
+		return false
+	}
+	*t = e[0]
+	return true
+}
+
+// assignable reports whether value e of type t can be used where
+// target type is expected. Signed integer can be widened implicitly,
+// but not narrowed. Integer literal is untyped, so it can be used as
+// any integer type its value fits in.
+func assignable(e ast.Expression, t, target ast.DataStructure) bool {
+	if t == invalidType || t == target {
+		return true
+	}
+
+	if isSigned(t) && isSigned(target) && t.Width() <= target.Width() {
+		return true
+	}
+
+	v, ok := literalValue(e)
+	if !ok || t != ast.IntType {
+		return false
+	}
+
+	switch {
+	case target == ast.UintType:
+		return v >= 0
+	case isSigned(target):
+		return fits(v, target.Width())
+	default:
+		return false
+	}
+}
+
+// literalValue returns the value of e if e is integer literal,
+// possibly negated
+func literalValue(e ast.Expression) (int64, bool) {
+	switch e := e.(type) {
+	case *ast.IntegerLiteral:
+		return e.Value, true
+	case *ast.PrefixExpression:
+		if e.Operator != ast.Minus {
+			return 0, false
+		}
+		v, ok := literalValue(e.Right)
+		return -v, ok
+	default:
+		return 0, false
+	}
+}
+
+// fits reports whether v can be represented by signed integer of width bits
+func fits(v int64, width int) bool {
+	if width >= 64 {
+		return true
+	}
+	limit := int64(1) << uint(width-1)
+	return -limit <= v && v < limit
+}
+
+// tupleElement returns ith element of e if e is tuple expression,
+// otherwise nil
+func tupleElement(e ast.Expression, i int) ast.Expression {
+	if tuple, ok := e.(*ast.TupleExpression); ok && i < len(tuple.Elements) {
+		return tuple.Elements[i]
+	}
+	return nil
+}
+
+// isInteger reports whether arithmetic operators are defined on t
+func isInteger(t ast.DataStructure) bool {
+	return isSigned(t) || t == ast.UintType
+}
+
+// isSigned reports whether t is signed integer of any width
+func isSigned(t ast.DataStructure) bool {
+	switch t {
+	case ast.IntType, ast.Int8Type, ast.Int16Type, ast.Int32Type:
+		return true
+	default:
+		return false
+	}
+}
+
+// signedType returns signed integer type of given width
+func signedType(width int) ast.DataStructure {
+	switch width {
+	case 8:
+		return ast.Int8Type
+	case 16:
+		return ast.Int16Type
+	case 32:
+		return ast.Int32Type
+	default:
+		return ast.IntType
+	}
+}
+
+// checker walks the AST keeping track of the symbols in scope
+// and the function currently being checked.
+type checker struct {
+	scope *symbol.Scope
+	fn    *ast.FunctionLiteral
+	errs  Errors
+
+	// customErrors are the errors declared in contract by name,
+	// they are not in scope because they are only used by revert
+	customErrors map[string]*ast.ErrorLiteral
+}
+
+// Check walks the contract after parsing and reports type mismatches
+// in assignments, conditions, return values and operator operands.
+// It returns nil when contract is well-typed, otherwise Errors.
+func Check(c *ast.Contract) error {
+	return CheckContext(context.Background(), c)
+}
+
+// CheckContext is like Check but stops before checking the next
+// function once ctx is done, returning ctx.Err()
+func CheckContext(ctx context.Context, c *ast.Contract) error {
+	ch := &checker{
+		scope:        symbol.NewScope(),
+		errs:         Errors{},
+		customErrors: make(map[string]*ast.ErrorLiteral),
+	}
+	ch.declareBuiltins()
+
+	for _, e := range c.Enums {
+		ch.declareEnum(e)
+	}
+
+	for _, cs := range c.Constants {
+		ch.checkConstStatement(cs)
+	}
+
+	for _, e := range c.Errors {
+		ch.declareError(e)
+	}
+
+	// functions are declared first, so that they can be
+	// called before their definition
+	for _, fn := range c.Functions {
+		ch.declareFunction(fn)
+	}
+	ch.checkImplements(c)
+
+	for _, fn := range c.Functions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ch.checkFunction(fn)
+	}
+
+	if len(ch.errs) != 0 {
+		return ch.errs
+	}
+	return nil
+}
+
+func (c *checker) errorf(source ast.Node, format string, args ...interface{}) {
+	var pos ast.Pos
+	if n, ok := source.(ast.Positioned); ok {
+		pos = n.Position()
+	}
+	c.errorAt(pos, source, format, args...)
+}
+
+func (c *checker) errorAt(pos ast.Pos, source ast.Node, format string, args ...interface{}) {
+	c.errs = append(c.errs, Error{
+		Pos:    pos,
+		Source: source,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+// enterScope creates new scope than converts it to existing scope
+func (c *checker) enterScope() {
+	c.scope = symbol.NewEnclosedScope(c.scope)
+}
+
+// leaveScope converts current scope's outer to existing scope
+func (c *checker) leaveScope() {
+	c.scope = c.scope.GetOuter()
+}
+
+// blank is the identifier which discards value assigned to it,
+// it is never declared and can't be used as value
+const blank = "_"
+
+// declare adds variable with its data structure to current scope
+func (c *checker) declare(ident *ast.Identifier, ds ast.DataStructure) {
+	if ident.Name == blank {
+		return
+	}
+
+	if _, ok := selectors[ident.Name]; ok {
+		c.errorf(ident, "cannot declare reserved identifier %s", ident.Name)
+		return
+	}
+
+	switch ds {
+	case ast.IntType:
+		c.scope.Set(ident.Name, &symbol.Integer{Name: ident})
+	case ast.Int8Type, ast.Int16Type, ast.Int32Type:
+		c.scope.Set(ident.Name, &symbol.SizedInteger{Name: ident, Width: ds.Width()})
+	case ast.BoolType:
+		c.scope.Set(ident.Name, &symbol.Boolean{Name: ident})
+	case ast.StringType:
+		c.scope.Set(ident.Name, &symbol.String{Name: ident})
+	case ast.BytesType:
+		c.scope.Set(ident.Name, &symbol.Bytes{Name: ident})
+	case ast.UintType:
+		c.scope.Set(ident.Name, &symbol.Uint{Name: ident})
+	case ast.AddressType:
+		c.scope.Set(ident.Name, &symbol.Address{Name: ident})
+	}
+}
+
+// builtins are functions provided by the language,
+// contract can't declare function with the same name.
+// Strings made by itoa and hex have at most 6 characters,
+// contract fails with conversion error for larger number.
+var builtins = []*symbol.Function{
+	{Name: "len", Parameters: []ast.DataStructure{ast.BytesType}, ReturnType: ast.IntType},
+	{Name: "chainid", Parameters: []ast.DataStructure{}, ReturnType: ast.IntType},
+	{Name: "require", Parameters: []ast.DataStructure{ast.BoolType, ast.StringType}, ReturnType: ast.VoidType},
+	{Name: "assert", Parameters: []ast.DataStructure{ast.BoolType}, ReturnType: ast.VoidType},
+	{Name: "itoa", Parameters: []ast.DataStructure{ast.IntType}, ReturnType: ast.StringType},
+	{Name: "atoi", Parameters: []ast.DataStructure{ast.StringType}, ReturnType: ast.IntType},
+	{Name: "hex", Parameters: []ast.DataStructure{ast.IntType}, ReturnType: ast.StringType},
+}
+
+// Builtins returns the builtin functions,
+// which are in scope of every contract
+func Builtins() []*symbol.Function {
+	return append([]*symbol.Function{}, builtins...)
+}
+
+// declareBuiltins adds builtin functions to current scope
+func (c *checker) declareBuiltins() {
+	for _, fn := range builtins {
+		c.scope.Set(fn.Name, fn)
+	}
+}
+
+// declareFunction adds function symbol with its signature to current scope
+func (c *checker) declareFunction(fn *ast.FunctionLiteral) {
+	for _, b := range builtins {
+		if b.Name == fn.Name.Name {
+			c.errorf(fn.Name, "cannot redeclare builtin function %s", b.Name)
+			return
+		}
+	}
+
+	c.scope.Set(fn.Name.Name, functionSymbol(fn))
+}
+
+// functionSymbol returns the symbol of function literal
+func functionSymbol(fn *ast.FunctionLiteral) *symbol.Function {
+	params := make([]ast.DataStructure, 0, len(fn.Parameters))
+	defaults := make([]ast.Expression, 0, len(fn.Parameters))
+	for _, p := range fn.Parameters {
+		params = append(params, p.Type)
+		defaults = append(defaults, p.Default)
+	}
+
+	return &symbol.Function{
+		Name:        fn.Name.Name,
+		Parameters:  params,
+		ReturnType:  fn.ReturnType,
+		ReturnTypes: fn.ReturnTypes,
+		Defaults:    defaults,
+	}
+}
+
+// checkImplements checks contract has every function of the
+// interfaces it implements, with the same signature
+func (c *checker) checkImplements(contract *ast.Contract) {
+	interfaces := make(map[string]*ast.Interface)
+	for _, i := range contract.Interfaces {
+		interfaces[i.Name.Name] = i
+	}
+
+	functions := make(map[string]*ast.FunctionLiteral)
+	for _, fn := range contract.Functions {
+		functions[fn.Name.Name] = fn
+	}
+
+	for _, name := range contract.Implements {
+		i, ok := interfaces[name.Name]
+		if !ok {
+			c.errorf(name, "undefined interface %s", name.Name)
+			continue
+		}
+
+		for _, want := range i.Functions {
+			fn, ok := functions[want.Name.Name]
+			if !ok {
+				c.errorf(name, "contract does not implement %s (missing function %s)", name.Name, want.Name.Name)
+				continue
+			}
+
+			have, wantSig := functionSymbol(fn).Signature(), functionSymbol(want).Signature()
+			if have != wantSig {
+				c.errorf(name, "contract does not implement %s (wrong signature for %s: have %s, want %s)",
+					name.Name, want.Name.Name, have, wantSig)
+			}
+		}
+	}
+}
+
+func (c *checker) checkFunction(fn *ast.FunctionLiteral) {
+	// default values are checked in contract scope, because they are
+	// evaluated by caller where parameters don't exist
+	for _, p := range fn.Parameters {
+		if p.Default == nil {
+			continue
+		}
+		if t := c.typeOf(p.Default); !assignable(p.Default, t, p.Type) {
+			c.errorf(p.Default, "cannot use %s (type %s) as default value of %s (type %s)",
+				p.Default, t, p.Identifier.Name, p.Type)
+		}
+	}
+
+	c.fn = fn
+	c.enterScope()
+
+	for _, p := range fn.Parameters {
+		c.declare(p.Identifier, p.Type)
+	}
+	c.checkBlockStatement(fn.Body)
+
+	c.leaveScope()
+	c.fn = nil
+}
+
+func (c *checker) checkBlockStatement(b *ast.BlockStatement) {
+	if b == nil {
+		return
+	}
+
+	c.enterScope()
+	for _, s := range b.Statements {
+		c.checkStatement(s)
+	}
+	c.leaveScope()
+}
+
+func (c *checker) checkStatement(s ast.Statement) {
+	switch stmt := s.(type) {
+	case *ast.AssignStatement:
+		c.checkAssignStatement(stmt)
+	case *ast.TupleAssignStatement:
+		c.checkTupleAssignStatement(stmt)
+	case *ast.ReassignStatement:
+		c.checkReassignStatement(stmt)
+	case *ast.ReturnStatement:
+		c.checkReturnStatement(stmt)
+	case *ast.IfStatement:
+		c.checkIfStatement(stmt)
+	case *ast.ForStatement:
+		c.checkForStatement(stmt)
+	case *ast.DoWhileStatement:
+		c.checkDoWhileStatement(stmt)
+	case *ast.BlockStatement:
+		c.checkBlockStatement(stmt)
+	case *ast.RevertStatement:
+		c.checkRevertStatement(stmt)
+	case *ast.ExpressionStatement:
+		// results of call statement are discarded,
+		// however many values callee returns
+		if call, ok := stmt.Expr.(*ast.CallExpression); ok {
+			c.typesOfCall(call)
+			return
+		}
+		c.typeOf(stmt.Expr)
+	}
+}
+
+// checkAssignStatement verifies value of assign statement
+// has the declared type. e.g. int a = "str" is invalid
+func (c *checker) checkAssignStatement(s *ast.AssignStatement) {
+	t := c.typeOf(s.Value)
+	if !assignable(s.Value, t, s.Type) {
+		c.errorf(s, "cannot assign %s to %s (type %s)", t, s.Variable.Name, s.Type)
+	}
+
+	c.declare(&s.Variable, s.Type)
+}
+
+// checkConstStatement verifies value of constant has the declared
+// type, then declares constant in contract scope
+func (c *checker) checkConstStatement(s *ast.ConstStatement) {
+	t := c.typeOf(s.Value)
+	if !assignable(s.Value, t, s.Type) {
+		c.errorf(s, "cannot assign %s to %s (type %s)", t, s.Name.Name, s.Type)
+	}
+
+	c.scope.Set(s.Name.Name, &symbol.Constant{Name: &s.Name, DataType: s.Type})
+}
+
+// checkTupleAssignStatement verifies value produces as many values
+// as there are variables, and each value has the declared type
+func (c *checker) checkTupleAssignStatement(s *ast.TupleAssignStatement) {
+	ts := c.typesOf(s.Value)
+	if ts != nil && len(ts) != len(s.Variables) {
+		c.errorf(s, "assignment mismatch: %d variables but %d values", len(s.Variables), len(ts))
+	} else if ts != nil {
+		for i, t := range ts {
+			if !assignable(tupleElement(s.Value, i), t, s.Types[i]) {
+				c.errorf(s, "cannot assign %s to %s (type %s)", t, s.Variables[i].Name, s.Types[i])
+			}
+		}
+	}
+
+	for i := range s.Variables {
+		c.declare(&s.Variables[i], s.Types[i])
+	}
+}
+
+// checkReassignStatement verifies new value has the same
+// type with the variable
+func (c *checker) checkReassignStatement(s *ast.ReassignStatement) {
+	if s.Variable.Name == blank {
+		c.typeOf(s.Value)
+		return
+	}
+
+	if sym, ok := c.scope.Get(s.Variable.Name).(*symbol.Constant); ok {
+		c.errorf(s, "cannot assign to constant %s", sym.Name.Name)
+		return
+	}
+
+	vt := c.typeOf(s.Variable)
+	t := c.typeOf(s.Value)
+	if vt != invalidType && !assignable(s.Value, t, vt) {
+		c.errorf(s, "cannot assign %s to %s (type %s)", t, s.Variable.Name, vt)
+	}
+}
+
+// declareEnum adds enum declared in contract to current scope
+func (c *checker) declareEnum(e *ast.EnumLiteral) {
+	if _, ok := selectors[e.Name.Name]; ok {
+		c.errorf(e.Name, "cannot declare reserved identifier %s", e.Name.Name)
+		return
+	}
+	if c.scope.Get(e.Name.Name) != nil {
+		c.errorf(e.Name, "enum %s redeclared in contract", e.Name.Name)
+		return
+	}
+
+	members := make([]string, 0, len(e.Members))
+	for _, m := range e.Members {
+		members = append(members, m.Name)
+	}
+	c.scope.Set(e.Name.Name, &symbol.Enum{Name: e.Name, Members: members})
+}
+
+// declareError adds custom error declared in contract
+func (c *checker) declareError(e *ast.ErrorLiteral) {
+	if _, ok := c.customErrors[e.Name.Name]; ok {
+		c.errorf(e.Name, "error %s redeclared in contract", e.Name.Name)
+		return
+	}
+	c.customErrors[e.Name.Name] = e
+}
+
+// checkRevertStatement verifies revert names declared error,
+// and its arguments match the parameters of the error
+func (c *checker) checkRevertStatement(s *ast.RevertStatement) {
+	args := make([]ast.DataStructure, 0, len(s.Arguments))
+	for _, arg := range s.Arguments {
+		args = append(args, c.typeOf(arg))
+	}
+
+	e, ok := c.customErrors[s.Error.Name]
+	if !ok {
+		c.errorf(s, "undefined error: %s", s.Error.Name)
+		return
+	}
+
+	if len(args) != len(e.Parameters) {
+		c.errorf(s, "wrong number of arguments in revert %s, have %d, want %d",
+			errorSignature(e), len(args), len(e.Parameters))
+		return
+	}
+
+	for i, t := range args {
+		want := e.Parameters[i].Type
+		if !assignable(s.Arguments[i], t, want) {
+			c.errorf(s.Arguments[i], "cannot use %s (type %s) as type %s in argument %d to %s",
+				s.Arguments[i], t, want, i+1, e.Name.Name)
+		}
+	}
+}
+
+// errorSignature returns custom error's name with its parameter
+// types. e.g. InsufficientBalance(int, int)
+func errorSignature(e *ast.ErrorLiteral) string {
+	params := make([]string, 0, len(e.Parameters))
+	for _, p := range e.Parameters {
+		params = append(params, p.Type.String())
+	}
+	return fmt.Sprintf("%s(%s)", e.Name.Name, strings.Join(params, ", "))
+}
+
+// checkReturnStatement verifies return statement matches the
+// function signature: void functions must return nothing, and other
+// functions must return a value of their return type
+func (c *checker) checkReturnStatement(s *ast.ReturnStatement) {
+	if c.fn == nil {
+		return
+	}
+
+	if s.ReturnValue == nil {
+		if c.fn.ReturnType != ast.VoidType {
+			c.errorAt(s.Pos, s, "missing return value in function %s (return type %s)",
+				c.fn.Name.Name, c.fn.ReturnTypeString())
+		}
+		return
+	}
+
+	if c.fn.ReturnType == ast.TupleType {
+		c.checkTupleReturn(s)
+		return
+	}
+
+	t := c.typeOf(s.ReturnValue)
+	if c.fn.ReturnType == ast.VoidType {
+		c.errorAt(s.Pos, s, "too many return values in function %s (return type void)",
+			c.fn.Name.Name)
+		return
+	}
+
+	if !assignable(s.ReturnValue, t, c.fn.ReturnType) {
+		c.errorAt(s.Pos, s, "cannot return %s in function %s (return type %s)",
+			t, c.fn.Name.Name, c.fn.ReturnType)
+	}
+}
+
+// checkTupleReturn verifies return statement of function returning
+// multiple values returns as many values as declared, each of the
+// declared type
+func (c *checker) checkTupleReturn(s *ast.ReturnStatement) {
+	ts := c.typesOf(s.ReturnValue)
+	if ts == nil {
+		return
+	}
+
+	if len(ts) != len(c.fn.ReturnTypes) {
+		c.errorAt(s.Pos, s, "wrong number of return values in function %s, have %d, want %d",
+			c.fn.Name.Name, len(ts), len(c.fn.ReturnTypes))
+		return
+	}
+
+	for i, t := range ts {
+		if !assignable(tupleElement(s.ReturnValue, i), t, c.fn.ReturnTypes[i]) {
+			c.errorAt(s.Pos, s, "cannot return %s as value %d in function %s (return type %s)",
+				t, i+1, c.fn.Name.Name, c.fn.ReturnTypeString())
+		}
+	}
+}
+
+// checkForStatement verifies condition of loop is boolean, variables
+// declared in init clause are visible only in the loop
+func (c *checker) checkForStatement(s *ast.ForStatement) {
+	c.enterScope()
+	defer c.leaveScope()
+
+	if s.Init != nil {
+		c.checkStatement(s.Init)
+	}
+
+	if s.Condition != nil {
+		t := c.typeOf(s.Condition)
+		if t != invalidType && t != ast.BoolType {
+			c.errorf(s.Condition, "non-bool %s (type %s) used as for condition", s.Condition, t)
+		}
+	}
+
+	if s.Post != nil {
+		c.checkStatement(s.Post)
+	}
+
+	c.checkBlockStatement(s.Body)
+}
+
+// checkDoWhileStatement verifies condition of loop is boolean,
+// variables declared in body are not visible in condition
+func (c *checker) checkDoWhileStatement(s *ast.DoWhileStatement) {
+	c.checkBlockStatement(s.Body)
+
+	t := c.typeOf(s.Condition)
+	if t != invalidType && t != ast.BoolType {
+		c.errorf(s.Condition, "non-bool %s (type %s) used as do-while condition", s.Condition, t)
+	}
+}
+
+// checkIfStatement verifies condition is boolean
+func (c *checker) checkIfStatement(s *ast.IfStatement) {
+	t := c.typeOf(s.Condition)
+	if t != invalidType && t != ast.BoolType {
+		c.errorf(s.Condition, "non-bool %s (type %s) used as if condition", s.Condition, t)
+	}
+
+	c.checkBlockStatement(s.Consequence)
+	c.checkBlockStatement(s.Alternative)
+}
+
+// typeOf returns the data structure which expression produces,
+// reporting errors found in its operands
+func (c *checker) typeOf(e ast.Expression) ast.DataStructure {
+	switch expr := e.(type) {
+	case *ast.IntegerLiteral:
+		return ast.IntType
+	case *ast.StringLiteral:
+		return ast.StringType
+	case *ast.BooleanLiteral:
+		return ast.BoolType
+	case *ast.BytesLiteral:
+		return ast.BytesType
+	case *ast.AddressLiteral:
+		return ast.AddressType
+	case *ast.IndexExpression:
+		return c.typeOfIndex(expr)
+	case *ast.SelectorExpression:
+		return c.typeOfSelector(expr)
+	case *ast.Identifier:
+		return c.typeOfIdentifier(expr)
+	case *ast.PrefixExpression:
+		return c.typeOfPrefix(expr)
+	case *ast.InfixExpression:
+		return c.typeOfInfix(expr)
+	case *ast.CallExpression:
+		ts := c.typesOfCall(expr)
+		if ts == nil {
+			return invalidType
+		}
+		if len(ts) != 1 {
+			c.errorf(expr, "multiple-value %s in single-value context", expr)
+			return invalidType
+		}
+		return ts[0]
+	case *ast.TupleExpression:
+		c.errorf(expr, "multiple-value %s in single-value context", expr)
+		return invalidType
+	default:
+		return invalidType
+	}
+}
+
+// typesOf returns types of every value which expression produces,
+// tuple expression and call to function returning multiple values
+// produce more than one value. Returns nil when the types couldn't be
+// determined.
+func (c *checker) typesOf(e ast.Expression) []ast.DataStructure {
+	switch expr := e.(type) {
+	case *ast.TupleExpression:
+		ts := make([]ast.DataStructure, 0, len(expr.Elements))
+		for _, elem := range expr.Elements {
+			ts = append(ts, c.typeOf(elem))
+		}
+		return ts
+	case *ast.CallExpression:
+		return c.typesOfCall(expr)
+	default:
+		t := c.typeOf(e)
+		if t == invalidType {
+			return nil
+		}
+		return []ast.DataStructure{t}
+	}
+}
+
+func (c *checker) typeOfIdentifier(e *ast.Identifier) ast.DataStructure {
+	if e.Name == blank {
+		c.errorf(e, "cannot use _ as value")
+		return invalidType
+	}
+
+	sym := c.scope.Get(e.Name)
+	if sym == nil {
+		c.errorf(e, "undefined: %s", e.Name)
+		return invalidType
+	}
+
+	switch sym.Type() {
+	case symbol.ConstantSymbol:
+		return sym.(*symbol.Constant).DataType
+	case symbol.IntegerSymbol:
+		return ast.IntType
+	case symbol.SizedSymbol:
+		return signedType(sym.(*symbol.SizedInteger).Width)
+	case symbol.BooleanSymbol:
+		return ast.BoolType
+	case symbol.StringSymbol:
+		return ast.StringType
+	case symbol.UintSymbol:
+		return ast.UintType
+	case symbol.BytesSymbol:
+		return ast.BytesType
+	case symbol.AddressSymbol:
+		return ast.AddressType
+	default:
+		c.errorf(e, "%s is not a variable", e.Name)
+		return invalidType
+	}
+}
+
+func (c *checker) typeOfPrefix(e *ast.PrefixExpression) ast.DataStructure {
+	t := c.typeOf(e.Right)
+	if t == invalidType {
+		return invalidType
+	}
+
+	switch e.Operator {
+	case ast.Bang:
+		if t != ast.BoolType {
+			c.errorf(e, "operator %s not defined on %s", e.Operator, t)
+			return invalidType
+		}
+		return ast.BoolType
+	case ast.Minus:
+		if !isSigned(t) {
+			c.errorf(e, "operator %s not defined on %s", e.Operator, t)
+			return invalidType
+		}
+		return t
+	default:
+		c.errorf(e, "unknown prefix operator %s", e.Operator)
+		return invalidType
+	}
+}
+
+func (c *checker) typeOfInfix(e *ast.InfixExpression) ast.DataStructure {
+	lt := c.typeOf(e.Left)
+	rt := c.typeOf(e.Right)
+	if lt == invalidType || rt == invalidType {
+		return invalidType
+	}
+
+	// untyped integer literal takes the type of the other operand,
+	// otherwise narrower integer is widened to the other
+	_, leftLiteral := literalValue(e.Left)
+	_, rightLiteral := literalValue(e.Right)
+	switch {
+	case rightLiteral && assignable(e.Right, rt, lt):
+		rt = lt
+	case leftLiteral && assignable(e.Left, lt, rt):
+		lt = rt
+	case assignable(e.Left, lt, rt):
+		lt = rt
+	case assignable(e.Right, rt, lt):
+		rt = lt
+	}
+
+	if lt != rt {
+		c.errorf(e, "mismatched types %s and %s", lt, rt)
+		return invalidType
+	}
+
+	switch e.Operator {
+	case ast.Plus, ast.Minus, ast.Asterisk, ast.Slash, ast.Mod:
+		if !isInteger(lt) {
+			c.errorf(e, "operator %s not defined on %s", e.Operator, lt)
+			return invalidType
+		}
+		return lt
+	case ast.LT, ast.GT, ast.LTE, ast.GTE:
+		if !isInteger(lt) {
+			c.errorf(e, "operator %s not defined on %s", e.Operator, lt)
+			return invalidType
+		}
+		return ast.BoolType
+	case ast.EQ, ast.NOT_EQ:
+		if lt == ast.VoidType {
+			c.errorf(e, "operator %s not defined on %s", e.Operator, lt)
+			return invalidType
+		}
+		return ast.BoolType
+	case ast.LAND, ast.LOR:
+		if lt != ast.BoolType {
+			c.errorf(e, "operator %s not defined on %s", e.Operator, lt)
+			return invalidType
+		}
+		return ast.BoolType
+	default:
+		c.errorf(e, "unknown infix operator %s", e.Operator)
+		return invalidType
+	}
+}
+
+// selectors are fields of reserved identifiers, which are
+// provided by the execution environment
+var selectors = map[string]map[string]ast.DataStructure{
+	"msg": {
+		"sender": ast.AddressType,
+	},
+	"block": {
+		"timestamp": ast.IntType,
+		"number":    ast.IntType,
+	},
+}
+
+// Reserved returns the reserved identifiers which have fields
+func Reserved() []string {
+	names := make([]string, 0, len(selectors))
+	for name := range selectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fields returns the fields of reserved identifier name and their types
+func Fields(name string) map[string]ast.DataStructure {
+	fields := make(map[string]ast.DataStructure)
+	for field, t := range selectors[name] {
+		fields[field] = t
+	}
+	return fields
+}
+
+func (c *checker) typeOfSelector(e *ast.SelectorExpression) ast.DataStructure {
+	// members of enum are integer constants
+	if enum, ok := c.scope.Get(e.Left.Name).(*symbol.Enum); ok {
+		if _, ok := enum.Value(e.Field.Name); !ok {
+			c.errorf(e, "undefined: %s", e)
+			return invalidType
+		}
+		return ast.IntType
+	}
+
+	fields, ok := selectors[e.Left.Name]
+	if !ok {
+		c.errorf(e, "%s has no fields", e.Left.Name)
+		return invalidType
+	}
+
+	t, ok := fields[e.Field.Name]
+	if !ok {
+		c.errorf(e, "undefined: %s", e)
+		return invalidType
+	}
+	return t
+}
+
+// typeOfIndex verifies bytes is indexed with int,
+// indexing bytes produces the byte as int
+func (c *checker) typeOfIndex(e *ast.IndexExpression) ast.DataStructure {
+	lt := c.typeOf(e.Left)
+	it := c.typeOf(e.Index)
+	if lt == invalidType || it == invalidType {
+		return invalidType
+	}
+
+	if lt != ast.BytesType {
+		c.errorf(e, "cannot index %s (type %s)", e.Left, lt)
+		return invalidType
+	}
+	if it != ast.IntType {
+		c.errorf(e, "non-int index %s (type %s)", e.Index, it)
+		return invalidType
+	}
+	return ast.IntType
+}
+
+// typesOfCall verifies call expression against the signature of
+// callee: number of arguments and type of each argument should match
+// with parameters. Call expression produces callee's return types.
+func (c *checker) typesOfCall(e *ast.CallExpression) []ast.DataStructure {
+	args := make([]ast.DataStructure, 0, len(e.Arguments))
+	for _, arg := range e.Arguments {
+		args = append(args, c.typeOf(arg))
+	}
+
+	ident, ok := e.Function.(*ast.Identifier)
+	if !ok {
+		c.errorf(e, "cannot call non-function %s", e.Function)
+		return nil
+	}
+
+	sym := c.scope.Get(ident.Name)
+	if sym == nil {
+		c.errorf(e, "undefined function: %s", ident.Name)
+		return nil
+	}
+
+	fn, ok := sym.(*symbol.Function)
+	if !ok {
+		c.errorf(e, "cannot call non-function %s", ident.Name)
+		return nil
+	}
+
+	if required := requiredParameters(fn); len(args) < required || len(args) > len(fn.Parameters) {
+		want := fmt.Sprintf("%d", len(fn.Parameters))
+		if required != len(fn.Parameters) {
+			want = fmt.Sprintf("%d to %d", required, len(fn.Parameters))
+		}
+		c.errorf(e, "wrong number of arguments in call to %s, have %d, want %s",
+			fn.Signature(), len(args), want)
+		return returnTypesOf(fn)
+	}
+
+	for i, t := range args {
+		if !assignable(e.Arguments[i], t, fn.Parameters[i]) {
+			c.errorf(e.Arguments[i], "cannot use %s (type %s) as type %s in argument %d to %s",
+				e.Arguments[i], t, fn.Parameters[i], i+1, fn.Name)
+		}
+	}
+
+	if fn.Name == "require" && len(e.Arguments) > 1 {
+		c.checkReason(e.Arguments[1])
+	}
+
+	// call omitting trailing arguments is completed with
+	// their default values
+	for i := len(e.Arguments); i < len(fn.Parameters); i++ {
+		e.Arguments = append(e.Arguments, fn.Defaults[i])
+	}
+
+	return returnTypesOf(fn)
+}
+
+// maxReasonLen is the maximum length of the string literal which
+// require reverts with, including its quotes. Reason is reverted
+// with as a single word, so it is at most 6 characters.
+const maxReasonLen = 8
+
+// checkReason verifies that reason literal of require fits in the
+// word which contract reverts with
+func (c *checker) checkReason(reason ast.Expression) {
+	lit, ok := reason.(*ast.StringLiteral)
+	if ok && len(lit.Value) > maxReasonLen {
+		c.errorf(lit, "require reason %s is too long, reason can have at most %d characters",
+			lit.Value, maxReasonLen-2)
+	}
+}
+
+// requiredParameters returns the number of parameters which
+// have no default value
+func requiredParameters(fn *symbol.Function) int {
+	for i, d := range fn.Defaults {
+		if d != nil {
+			return i
+		}
+	}
+	return len(fn.Parameters)
+}
+
+// returnTypesOf returns types of values which function returns
+func returnTypesOf(fn *symbol.Function) []ast.DataStructure {
+	if fn.ReturnType == ast.TupleType {
+		return fn.ReturnTypes
+	}
+	return []ast.DataStructure{fn.ReturnType}
+}
@@ -0,0 +1,590 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package typecheck_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/parse"
+	"github.com/DE-labtory/koa/typecheck"
+)
+
+func parseTestContract(t *testing.T, input string) *ast.Contract {
+	contract, err := parse.Parse(parse.NewTokenBuffer(parse.NewLexer(input)))
+	if err != nil {
+		t.Fatalf("parser error: %s", err)
+	}
+	return contract
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr string
+	}{
+		{
+			input: `
+contract {
+	func add(a int, b int) int {
+		int c = a + b
+		if (c > 10 && true) {
+			return c
+		}
+		c = c * 2
+		return -c
+	}
+}`,
+			expectedErr: "",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		int ddd2 = "iam_string"
+	}
+}`,
+			expectedErr: `[line 3, column 5] [int ddd2 = "iam_string"] cannot assign string to ddd2 (type int)`,
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		bool a = true
+		a = 1
+	}
+}`,
+			expectedErr: "[line 4, column 3] [a = 1] cannot assign int to a (type bool)",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		if (1 + 2) {
+		}
+	}
+}`,
+			expectedErr: "[line 3, column 7] [(1 + 2)] non-bool (1 + 2) (type int) used as if condition",
+		},
+		{
+			input: `
+contract {
+	func foo() string {
+		return 1
+	}
+}`,
+			expectedErr: "[line 3, column 8] [return 1] cannot return int in function foo (return type string)",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		return 1
+	}
+}`,
+			expectedErr: "[line 3, column 8] [return 1] too many return values in function foo (return type void)",
+		},
+		{
+			input: `
+contract {
+	func foo() bool {
+		if (true) {
+			return
+		}
+		return false
+	}
+}`,
+			expectedErr: "[line 4, column 10] [return] missing return value in function foo (return type bool)",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		return
+	}
+}`,
+			expectedErr: "",
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return 1 + "a"
+	}
+}`,
+			expectedErr: `[line 3, column 10] [(1 + "a")] mismatched types int and string`,
+		},
+		{
+			input: `
+contract {
+	func foo() bool {
+		return true + false
+	}
+}`,
+			expectedErr: "[line 3, column 13] [(true + false)] operator + not defined on bool",
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return 1 && 2
+	}
+}`,
+			expectedErr: "[line 3, column 10] [(1 && 2)] operator && not defined on int",
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return a + 1
+	}
+}`,
+			expectedErr: "[line 3, column 10] [a] undefined: a",
+		},
+		// errors are not cascaded from invalid operands
+		{
+			input: `
+contract {
+	func foo() int {
+		int a = (1 + true) * 2
+		return a
+	}
+}`,
+			expectedErr: "[line 3, column 12] [(1 + true)] mismatched types int and bool",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+	}
+	func bar() int {
+		int a = foo()
+		return baz()
+	}
+}`,
+			expectedErr: "[line 5, column 5] [int a = function foo(  )] cannot assign void to a (type int)\n" +
+				"[line 6, column 12] [function baz(  )] undefined function: baz",
+		},
+		{
+			input: `
+contract {
+	func add(a int, b int) int {
+		return a + b
+	}
+	func foo() int {
+		return add(1)
+	}
+}`,
+			expectedErr: "[line 6, column 12] [function add( 1 )] wrong number of arguments in call to add(int, int) int, have 1, want 2",
+		},
+		{
+			input: `
+contract {
+	func foo() int {
+		return add(1, true)
+	}
+	func add(a int, b int) int {
+		return a + b
+	}
+}`,
+			expectedErr: "[line 3, column 20] [true] cannot use true (type bool) as type int in argument 2 to add",
+		},
+		{
+			input: `
+contract {
+	func foo(a int) int {
+		return a(1)
+	}
+}`,
+			expectedErr: "[line 3, column 10] [function a( 1 )] cannot call non-function a",
+		},
+		{
+			input: `
+contract {
+	func pair() (int, bool) {
+		return 1, true
+	}
+	func foo() int {
+		int a, bool b = pair()
+		if (b) {
+			return a
+		}
+		return 0
+	}
+}`,
+			expectedErr: "",
+		},
+		{
+			input: `
+contract {
+	func pair() (int, bool) {
+		return true, 1
+	}
+}`,
+			expectedErr: "[line 3, column 8] [return true, 1] cannot return bool as value 1 in function pair (return type (int, bool))\n" +
+				"[line 3, column 8] [return true, 1] cannot return int as value 2 in function pair (return type (int, bool))",
+		},
+		{
+			input: `
+contract {
+	func pair() (int, bool) {
+		return 1
+	}
+}`,
+			expectedErr: "[line 3, column 8] [return 1] wrong number of return values in function pair, have 1, want 2",
+		},
+		{
+			input: `
+contract {
+	func pair() (int, bool) {
+		return 1, true
+	}
+	func foo() int {
+		int a, string b = pair()
+		int c, bool d, int e = pair()
+		int f = pair()
+		return 1, 2
+	}
+}`,
+			expectedErr: "[line 6, column 5] [int a, string b = function pair(  )] cannot assign bool to b (type string)\n" +
+				"[line 7, column 5] [int c, bool d, int e = function pair(  )] assignment mismatch: 3 variables but 2 values\n" +
+				"[line 8, column 14] [function pair(  )] multiple-value function pair(  ) in single-value context\n" +
+				"[line 9, column 10] [1, 2] multiple-value 1, 2 in single-value context",
+		},
+		{
+			input: `
+contract {
+	func pair() (int, bool) {
+		return 1, true
+	}
+	func foo() int {
+		pair()
+		int a, bool b = 1, true
+		return a
+	}
+}`,
+			expectedErr: "",
+		},
+		{
+			input: `
+contract {
+	func foo(n int) int {
+		int total = 0
+		for (int i = 0; i < n; i = i + 1) {
+			total = total + i
+		}
+		for (total) {
+			string i = "a"
+		}
+		return total
+	}
+}`,
+			expectedErr: "[line 7, column 12] [total] non-bool total (type int) used as for condition",
+		},
+		{
+			input: `
+contract {
+	const int FEE = 100
+	const bool OPEN = FEE > 10
+	const string NAME = FEE
+	func foo() bool {
+		int total = FEE * 2
+		string s = FEE
+		return OPEN
+	}
+}`,
+			expectedErr: "[line 4, column 6] [const string NAME = FEE] cannot assign int to NAME (type string)\n" +
+				"[line 7, column 8] [string s = FEE] cannot assign int to s (type string)",
+		},
+		{
+			input: `
+contract {
+	func foo(data bytes) int {
+		bytes hash = 0x"deadbeef"
+		int first = hash[0] + len(data)
+		int bad = first[0]
+		int worse = hash[true]
+		string s = 0x"00"
+		return len(hash)
+	}
+}`,
+			expectedErr: "[line 5, column 17] [(first[0])] cannot index first (type int)\n" +
+				"[line 6, column 18] [(hash[true])] non-int index true (type bool)\n" +
+				`[line 7, column 8] [string s = 0x"00"] cannot assign bytes to s (type string)`,
+		},
+		{
+			input: `
+contract {
+	const uint LIMIT = 10
+	func foo(a uint, b int) uint {
+		uint c = a * 2 + 1
+		bool small = c < LIMIT
+		uint d = -1
+		uint e = a + b
+		int f = -a
+		c = 5
+		return c / 2
+	}
+}`,
+			expectedErr: "[line 6, column 6] [uint d = (-1)] cannot assign int to d (type uint)\n" +
+				"[line 7, column 12] [(a + b)] mismatched types uint and int\n" +
+				"[line 8, column 11] [(-a)] operator - not defined on uint",
+		},
+		{
+			input: `
+contract {
+	const int8 MAX = 127
+	func foo(a int8, b int16, c int32) int32 {
+		int16 d = a + b
+		int e = c * 2
+		int8 f = -128
+		int8 g = 128
+		int8 h = b
+		int16 i = a + 1000
+		a = a * 2 + MAX
+		return -d
+	}
+}`,
+			expectedErr: "[line 7, column 6] [int8 g = 128] cannot assign int to g (type int8)\n" +
+				"[line 8, column 6] [int8 h = b] cannot assign int16 to h (type int8)\n" +
+				"[line 9, column 7] [int16 i = (a + 1000)] cannot assign int to i (type int16)",
+		},
+		{
+			input: `
+contract {
+	func foo(a int) int {
+		require(a > 0, "positive")
+		assert(a != 1)
+		require(a)
+		assert(a, "a")
+		int b = require(true, "b")
+		require(a > 1, "amount")
+		return a
+	}
+}`,
+			expectedErr: `[line 3, column 27] ["positive"] require reason "positive" is too long, reason can have at most 6 characters` + "\n" +
+				"[line 5, column 9] [function require( a )] wrong number of arguments in call to require(bool, string) void, have 1, want 2\n" +
+				`[line 6, column 8] [function assert( a, "a" )] wrong number of arguments in call to assert(bool) void, have 2, want 1` + "\n" +
+				`[line 7, column 5] [int b = function require( true, "b" )] cannot assign void to b (type int)`,
+		},
+		{
+			input: `
+contract {
+	func foo(owner address) bool {
+		address a = msg.sender
+		int msg = 1
+		bool b = msg.sender > owner
+		int c = msg.value
+		bool d = block.timestamp > 100 && block.number < 10
+		int e = foo.bar
+		return a == owner
+	}
+}`,
+			expectedErr: "[line 4, column 9] [msg] cannot declare reserved identifier msg\n" +
+				"[line 5, column 14] [(msg.sender > owner)] operator > not defined on address\n" +
+				"[line 6, column 13] [msg.value] undefined: msg.value\n" +
+				"[line 8, column 13] [foo.bar] foo has no fields",
+		},
+		{
+			input: `
+interface Token {
+	func balance(owner int) int
+	func transfer(to int, amount int) bool
+	func burn()
+}
+contract implements Token, Ownable {
+	func balance(owner int) int {
+		return 0
+	}
+	func transfer(to int, amount uint) bool {
+		return true
+	}
+}`,
+			expectedErr: "[line 6, column 25] [Token] contract does not implement Token (wrong signature for transfer: have transfer(int, uint) bool, want transfer(int, int) bool)\n" +
+				"[line 6, column 25] [Token] contract does not implement Token (missing function burn)\n" +
+				"[line 6, column 34] [Ownable] undefined interface Ownable",
+		},
+		{
+			input: `
+contract {
+	func chainid() int {
+		return 1
+	}
+	func foo() int {
+		return chainid(1)
+	}
+}`,
+			expectedErr: "[line 2, column 13] [chainid] cannot redeclare builtin function chainid\n" +
+				"[line 6, column 16] [function chainid( 1 )] wrong number of arguments in call to chainid() int, have 1, want 0",
+		},
+		{
+			input: `
+contract {
+	func foo(n int) int {
+		string s = itoa(n)
+		string h = hex(n)
+		int m = atoi(s)
+		return atoi(n) + m
+	}
+}`,
+			expectedErr: "[line 6, column 15] [n] cannot use n (type int) as type string in argument 1 to atoi",
+		},
+		{
+			input: `
+contract {
+	error Empty()
+	error Insufficient(needed int, got int)
+	error Empty(code int)
+	func foo(n int) int {
+		if (n > 10) {
+			revert Insufficient(n, true)
+		}
+		if (n > 5) {
+			revert Insufficient(n)
+		}
+		revert Missing(n)
+	}
+}`,
+			expectedErr: "[line 4, column 12] [Empty] error Empty redeclared in contract\n" +
+				"[line 7, column 30] [true] cannot use true (type bool) as type int in argument 2 to Insufficient\n" +
+				"[line 10, column 9] [revert Insufficient( n )] wrong number of arguments in revert Insufficient(int, int), have 1, want 2\n" +
+				"[line 12, column 8] [revert Missing( n )] undefined error: Missing",
+		},
+		{
+			input: `
+contract {
+	enum Status { Pending, Active }
+	const int FIRST = Status.Pending
+	func foo(n int) bool {
+		bool ok = n == Status.Active
+		string s = Status.Closed
+		return Status
+	}
+}`,
+			expectedErr: "[line 6, column 19] [Status.Closed] undefined: Status.Closed\n" +
+				"[line 7, column 16] [Status] Status is not a variable",
+		},
+		{
+			input: `
+contract {
+	func foo(_ int, _ bool, a int) int {
+		int _ = a
+		string _ = "discarded"
+		_ = true
+		return _
+	}
+}`,
+			expectedErr: "[line 6, column 11] [_] cannot use _ as value",
+		},
+		{
+			input: `
+contract {
+	func foo() {
+		int x = 0
+		do {
+			int y = x
+			x = x + 1
+		} while (x)
+		do {
+		} while (y > 0)
+	}
+}`,
+			expectedErr: "[line 7, column 12] [x] non-bool x (type int) used as do-while condition\n" +
+				"[line 9, column 12] [y] undefined: y",
+		},
+		{
+			input: `
+contract {
+	const int FEE = 10
+	func fee(amount int, rate int = FEE, round bool = true) int {
+		return amount * rate
+	}
+	func foo() int {
+		return fee(1) + fee(1, 2) + fee(1, 2, false)
+	}
+}`,
+			expectedErr: "",
+		},
+		{
+			input: `
+contract {
+	func fee(amount int, rate int = true, round bool = amount > 0) int {
+		return amount * rate
+	}
+	func foo() int {
+		return fee() + fee(1, 2, false, 3)
+	}
+}`,
+			expectedErr: "[line 2, column 37] [true] cannot use true (type bool) as default value of rate (type int)\n" +
+				"[line 2, column 58] [amount] undefined: amount\n" +
+				"[line 6, column 12] [function fee(  )] wrong number of arguments in call to fee(int, int, bool) int, have 0, want 1 to 3\n" +
+				"[line 6, column 20] [function fee( 1, 2, false, 3 )] wrong number of arguments in call to fee(int, int, bool) int, have 4, want 1 to 3",
+		},
+	}
+
+	for i, tt := range tests {
+		err := typecheck.Check(parseTestContract(t, tt.input))
+
+		if tt.expectedErr == "" && err != nil {
+			t.Errorf("test[%d] - Check() returns unexpected error: %s", i, err)
+			continue
+		}
+
+		if tt.expectedErr != "" && (err == nil || err.Error() != tt.expectedErr) {
+			t.Errorf("test[%d] - Check() returns wrong error.\nexpected=%s\ngot=%v", i, tt.expectedErr, err)
+		}
+	}
+}
+
+func TestCheckContext(t *testing.T) {
+	contract := parseTestContract(t, `
+contract {
+	func foo() int {
+		return 1
+	}
+}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := typecheck.CheckContext(ctx, contract); err != context.Canceled {
+		t.Errorf("CheckContext() with cancelled context wrong error. expected=%v, got=%v", context.Canceled, err)
+	}
+}
+
+func TestCheck_defaultArguments(t *testing.T) {
+	contract := parseTestContract(t, `
+contract {
+	func fee(amount int, rate int = 10, round bool = true) int {
+		return amount * rate
+	}
+	func foo() int {
+		return fee(1)
+	}
+}`)
+
+	if err := typecheck.Check(contract); err != nil {
+		t.Fatalf("Check() returns unexpected error: %s", err)
+	}
+
+	ret := contract.Functions[1].Body.Statements[0].(*ast.ReturnStatement)
+	expected := "function fee( 1, 10, true )"
+	if result := ret.ReturnValue.String(); result != expected {
+		t.Errorf("Check() wrong completed call. expected=%s, got=%s", expected, result)
+	}
+}
@@ -22,21 +22,28 @@ import (
 
 var opCodes = map[opcode.Type]opCode{
 	// 0x0 range
-	opcode.Add: add{},
-	opcode.Mul: mul{},
-	opcode.Sub: sub{},
-	opcode.Div: div{},
-	opcode.Mod: mod{},
-	opcode.And: and{},
-	opcode.Or:  or{},
+	opcode.Add:  add{},
+	opcode.Mul:  mul{},
+	opcode.Sub:  sub{},
+	opcode.Div:  div{},
+	opcode.Mod:  mod{},
+	opcode.And:  and{},
+	opcode.Or:   or{},
+	opcode.UDiv: udiv{},
+	opcode.UMod: umod{},
 
 	// 0x10 range
-	opcode.LT:  lt{},
-	opcode.LTE: lte{},
-	opcode.GT:  gt{},
-	opcode.GTE: gte{},
-	opcode.EQ:  eq{},
-	opcode.NOT: not{},
+	opcode.LT:    lt{},
+	opcode.LTE:   lte{},
+	opcode.GT:    gt{},
+	opcode.GTE:   gte{},
+	opcode.EQ:    eq{},
+	opcode.NOT:   not{},
+	opcode.Minus: minus{},
+	opcode.ULT:   ult{},
+	opcode.ULTE:  ulte{},
+	opcode.UGT:   ugt{},
+	opcode.UGTE:  ugte{},
 
 	// 0x20 range
 	opcode.Pop:       pop{},
@@ -51,10 +58,25 @@ var opCodes = map[opcode.Type]opCode{
 	opcode.JumpDst:   jumpDst{},
 
 	// 0x30 range
-	opcode.Jumpi: jumpi{},
-	opcode.DUP:   dup{},
-	opcode.SWAP:  swap{},
-	opcode.Exit:  exit{},
+	opcode.Jumpi:       jumpi{},
+	opcode.DUP:         dup{},
+	opcode.SWAP:        swap{},
+	opcode.Exit:        exit{},
+	opcode.ChainID:     chainid{},
+	opcode.Revert:      revert{},
+	opcode.Timestamp:   timestamp{},
+	opcode.Number:      number{},
+	opcode.Itoa:        itoa{},
+	opcode.Atoi:        atoi{},
+	opcode.Hex:         tohex{},
+	opcode.Raise:       raise{},
+	opcode.Caller:      caller{},
+	opcode.LoadAddress: loadaddress{},
+	opcode.AddressEQ:   addresseq{},
+	opcode.ByteAt:      byteat{},
+	opcode.Len:         length{},
+	opcode.LoadBytes:   loadbytes{},
+	opcode.Returns:     returns{},
 }
 
 // Converts rawByteCode to assembly code.
@@ -68,17 +90,21 @@ func disassemble(rawByteCode []byte) (*asm, error) {
 			return nil, ErrInvalidOpcode
 		}
 
-		switch op.hex()[0] {
-		case uint8(opcode.Push):
-			body := make([]uint8, 0)
-			body = append(body, rawByteCode[i+1:i+9]...)
+		asm.code = append(asm.code, op)
 
-			asm.code = append(asm.code, op)
-			asm.code = append(asm.code, Data{Body: body})
-			i += 8
-		default:
-			asm.code = append(asm.code, op)
+		spec, _ := opcode.SpecOf(opcode.Type(rawByteCode[i]))
+		if spec.Operand == 0 {
+			continue
 		}
+
+		if i+spec.Operand >= len(rawByteCode) {
+			return nil, ErrInvalidData
+		}
+		body := make([]uint8, 0, spec.Operand)
+		body = append(body, rawByteCode[i+1:i+1+spec.Operand]...)
+
+		asm.code = append(asm.code, Data{Body: body})
+		i += spec.Operand
 	}
 
 	return asm, nil
@@ -133,7 +159,7 @@ func (a *asm) next() hexer {
 
 func (a *asm) jump(pc uint64) {
 	if pc > uint64(len(a.code))-1 {
-		panic("Access to invalid program counter!")
+		panic(ErrInvalidJump)
 	}
 	a.pc = pc
 }
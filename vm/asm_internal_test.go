@@ -72,6 +72,35 @@ func TestAssemble_invalid(t *testing.T) {
 	}
 }
 
+func TestAssemble_truncatedOperand(t *testing.T) {
+	testByteCode := makeTestByteCode(
+		uint8(opcode.Push), int64ToBytes(1)[:4],
+	)
+
+	_, err := disassemble(testByteCode)
+	if err != ErrInvalidData {
+		t.Errorf("disassemble() wrong error. expected=%v, got=%v", ErrInvalidData, err)
+	}
+}
+
+func TestOpCodes_spec(t *testing.T) {
+	specs := opcode.Specs()
+	if len(opCodes) != len(specs) {
+		t.Errorf("opCodes has %d opcodes, but opcode table has %d", len(opCodes), len(specs))
+	}
+
+	for _, spec := range specs {
+		op, ok := opCodes[spec.Type]
+		if !ok {
+			t.Errorf("opcode %s is not implemented", spec.Name)
+			continue
+		}
+		if !bytes.Equal(op.hex(), []uint8{uint8(spec.Type)}) {
+			t.Errorf("opcode %s has wrong hex. expected=%x, got=%x", spec.Name, uint8(spec.Type), op.hex())
+		}
+	}
+}
+
 func TestNext(t *testing.T) {
 	testByteCode := makeTestByteCode(
 		uint8(opcode.Push), int64ToBytes(1),
@@ -46,6 +46,10 @@ var ErrInvalidMemory = errors.New("Invalid memory reference")
 type Memory struct {
 	data []byte
 	cost uint64
+
+	// limit is the maximum size memory can be resized to,
+	// zero means no limit
+	limit uint64
 }
 
 func NewMemory() *Memory {
@@ -55,9 +59,16 @@ func NewMemory() *Memory {
 	}
 }
 
+// inRange reports whether offset + size is in memory, without
+// overflowing when contract passes huge offset or size
+func (m *Memory) inRange(offset, size uint64) bool {
+	length := uint64(m.Len())
+	return size <= length && offset <= length-size
+}
+
 // Set sets offset to value
 func (m *Memory) Set(offset uint64, value byte) {
-	if offset > uint64(m.Len()) {
+	if !m.inRange(offset, 1) {
 		panic(ErrInvalidMemory)
 	}
 	m.data[offset] = value
@@ -69,7 +80,7 @@ func (m *Memory) Set8(offset uint64, value []byte) {
 	tmp := new(big.Int)
 	tmp.SetBytes(value)
 
-	if offset+8 > uint64(m.Len()) {
+	if !m.inRange(offset, 8) {
 		panic(ErrInvalidMemory)
 	}
 	copy(m.data[offset:offset+8], []byte{0, 0, 0, 0, 0, 0, 0, 0})
@@ -79,44 +90,41 @@ func (m *Memory) Set8(offset uint64, value []byte) {
 // Sets sets offset + size to value
 func (m *Memory) Sets(offset, size uint64, value []byte) {
 	if size > 0 {
-		if offset+size > uint64(m.Len()) {
+		if !m.inRange(offset, size) {
 			panic(ErrInvalidMemory)
 		}
 		copy(m.data[offset:offset+size], value)
 	}
 }
 
-// Get returns offset + size as a new slice
+// Get returns offset + size as a new slice, or nil if it is out of
+// memory. Size is bounded by memory, so that contract can't allocate
+// more than memory limit by reading.
 func (m *Memory) GetVal(offset, size uint64) []byte {
-	if size == 0 {
+	if size == 0 || !m.inRange(offset, size) {
 		return nil
 	}
 
-	if uint64(m.Len()) > offset {
-		cpy := make([]byte, size)
-		copy(cpy, m.data[offset:offset+size])
-
-		return cpy
-	}
+	cpy := make([]byte, size)
+	copy(cpy, m.data[offset:offset+size])
 
-	return nil
+	return cpy
 }
 
-// GetPtr returns the offset + size
+// GetPtr returns the offset + size, or nil if it is out of memory
 func (m *Memory) GetPtr(offset, size uint64) []byte {
-	if size == 0 {
+	if size == 0 || !m.inRange(offset, size) {
 		return nil
 	}
 
-	if len(m.data) > int(offset) {
-		return m.data[offset : offset+size]
-	}
-
-	return nil
+	return m.data[offset : offset+size]
 }
 
 // Resize resizes the memory to size
 func (m *Memory) Resize(size uint64) {
+	if m.limit > 0 && size > m.limit {
+		panic(ErrMemoryLimit)
+	}
 	if uint64(m.Len()) < size {
 		m.data = append(m.data, make([]byte, size-uint64(m.Len()))...)
 	}
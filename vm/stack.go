@@ -16,12 +16,17 @@
 
 package vm
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 const (
 	stackMaxSize = 1024
 )
 
+var ErrStackUnderflow = errors.New("Stack underflow")
+
 type item int64
 
 // Stack is an object for basic Stack operations. Items popped to the Stack are
@@ -44,6 +49,9 @@ func (s *Stack) PushN(ds ...item) {
 }
 
 func (s *Stack) Pop() item {
+	if len(s.items) == 0 {
+		panic(ErrStackUnderflow)
+	}
 	item := s.items[len(s.items)-1]
 	s.items = s.items[:len(s.items)-1]
 	return item
@@ -54,10 +62,16 @@ func (s *Stack) Len() int {
 }
 
 func (s *Stack) Dup() {
+	if s.Len() < 1 {
+		panic(ErrStackUnderflow)
+	}
 	s.Push(s.items[s.Len()-1])
 }
 
 func (s *Stack) Swap() {
+	if s.Len() < 2 {
+		panic(ErrStackUnderflow)
+	}
 	s.items[s.Len()-2], s.items[s.Len()-1] = s.items[s.Len()-1], s.items[s.Len()-2]
 }
 
@@ -17,9 +17,14 @@
 package vm
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
+	"github.com/DE-labtory/koa/abi"
 	"github.com/DE-labtory/koa/encoding"
 	"github.com/DE-labtory/koa/opcode"
 )
@@ -34,35 +39,161 @@ const (
 
 var ErrInvalidData = errors.New("Invalid data")
 var ErrInvalidOpcode = errors.New("invalid opcode")
+var ErrInvalidJump = errors.New("Access to invalid program counter")
+var ErrDivideByZero = errors.New("Division by zero")
+var ErrRevert = errors.New("Execution reverted")
+var ErrConversion = errors.New("Invalid conversion")
+var ErrIndexOutOfRange = errors.New("Index out of range")
+
+// RevertError is returned when contract aborts with Revert,
+// Reason is the word it reverted with
+type RevertError struct {
+	Reason []byte
+
+	// Data is the selector of custom error followed by its arguments
+	// encoded as abi, when contract aborts with Raise
+	Data []byte
+}
+
+func (e RevertError) Error() string {
+	if len(e.Data) >= 4 {
+		return fmt.Sprintf("%s: error %x", ErrRevert, e.Data[:4])
+	}
+
+	reason := bytes.TrimRight(e.Reason, "\x00")
+	if len(reason) == 0 {
+		return ErrRevert.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrRevert, reason)
+}
+
+func (e RevertError) Is(target error) bool {
+	return target == ErrRevert
+}
 
 // The Execute function assemble the rawByteCode into an assembly code,
 // which in turn executes the assembly logic.
-func Execute(rawByteCode []byte, memory *Memory, callFunc *CallFunc) (*Stack, error) {
+//
+// Stack, memory and jump faults inside of opcodes are returned as
+// ErrStackUnderflow, ErrInvalidMemory and ErrInvalidJump, so callers
+// can check them with errors.Is.
+func Execute(rawByteCode []byte, memory *Memory, callFunc *CallFunc) (stack *Stack, err error) {
+	return ExecuteLimited(rawByteCode, memory, callFunc, Limits{})
+}
+
+// ErrStepLimit, ErrMemoryLimit and ErrStackLimit are returned when
+// contract exceeds one of Limits
+var ErrStepLimit = errors.New("step limit exceeded")
+var ErrMemoryLimit = errors.New("memory limit exceeded")
+var ErrStackLimit = errors.New("stack limit exceeded")
+
+// Limits bounds the resources used to execute untrusted contract.
+// Zero value of each field means no limit.
+type Limits struct {
+	// MaxSteps is the maximum number of opcodes executed
+	MaxSteps int
+
+	// MaxMemory is the maximum size of memory in bytes
+	MaxMemory uint64
+
+	// MaxStack is the maximum number of items in stack
+	MaxStack int
+}
+
+// Sandbox is the preset of limits for executing contracts which
+// server doesn't trust, such as ones sent to playground or RPC.
+// VM has no host functions or precompiles, so bounding steps, memory
+// and stack is all that is needed to keep contract from hogging server.
+var Sandbox = Limits{
+	MaxSteps:  1 << 20,
+	MaxMemory: 1 << 20,
+	MaxStack:  stackMaxSize,
+}
+
+// ExecuteLimited is like Execute but fails as soon as contract
+// exceeds one of limits
+func ExecuteLimited(rawByteCode []byte, memory *Memory, callFunc *CallFunc, limits Limits) (stack *Stack, err error) {
 
 	s := newStack()
+	defer func() {
+		if r := recover(); r != nil {
+			fault, ok := r.(error)
+			if !ok || !isFault(fault) {
+				panic(r)
+			}
+			stack, err = s, fault
+		}
+	}()
+
 	asm, err := disassemble(rawByteCode)
 	if err != nil {
 		return &Stack{}, err
 	}
 
+	if memory != nil {
+		memory.limit = limits.MaxMemory
+		defer func() { memory.limit = 0 }()
+	}
+
+	steps := 0
 	for h := asm.code[0]; h != nil; h = asm.next() {
+		steps++
+		if limits.MaxSteps > 0 && steps > limits.MaxSteps {
+			return s, ErrStepLimit
+		}
+
 		op, ok := h.(opCode)
 		if !ok {
 			return &Stack{}, ErrInvalidOpcode
 		}
 
+		spec, _ := opcode.SpecOf(opcode.Type(op.hex()[0]))
+		if s.Len() < spec.Pops {
+			return s, ErrStackUnderflow
+		}
+
 		err := op.Do(s, asm, memory, callFunc)
 		if err != nil {
 			return s, err
 		}
+
+		if limits.MaxStack > 0 && s.Len() > limits.MaxStack {
+			return s, ErrStackLimit
+		}
 	}
 
 	return s, nil
 }
 
+// isFault reports whether err is one of the errors which opcodes
+// panic with when the contract misbehaves
+func isFault(err error) bool {
+	return err == ErrStackUnderflow || err == ErrInvalidMemory || err == ErrInvalidJump ||
+		err == ErrMemoryLimit
+}
+
 type CallFunc struct {
 	Func []byte
 	Args []byte
+
+	// ChainID identifies the environment which contract is executed in,
+	// so that data signed for one chain can't be replayed on another
+	ChainID int64
+
+	// Block is the block which contract is executed in
+	Block Block
+
+	// Caller is the address which calls contract, returned by
+	// msg.sender. Address shorter than encoding.AddressLength is
+	// padded with zero on the left.
+	Caller []byte
+}
+
+// Block has the context of the block, which contracts can
+// use for time-locked or height-dependent logic
+type Block struct {
+	Number    int64
+	Timestamp int64
 }
 
 // function return the Func in CallFunc
@@ -84,18 +215,28 @@ func (cf CallFunc) function() []byte {
 //  ptr1 | ptr2 | ... | size1 | value1 | size2 | value2 | ...
 // -----------------------------------------------------------------
 //
-// arguments retrieve nth value from CallFunc Args
-func (cf CallFunc) arguments(n int) []byte {
-	if n < 0 {
-		panic("CallFunc.arguments receive minus value as parameters")
+// arguments retrieve nth value from CallFunc Args. Args come from
+// the caller, so ErrInvalidData is returned when n, pointer or size
+// points out of Args.
+func (cf CallFunc) arguments(n int) ([]byte, error) {
+	length := uint64(len(cf.Args))
+	if n < 0 || uint64(n) >= length/PTRSIZE {
+		return nil, ErrInvalidData
 	}
 
-	ptr := n * PTRSIZE
+	ptr := uint64(n) * PTRSIZE
 
 	sizePtr := binary.BigEndian.Uint64(cf.Args[ptr : ptr+PTRSIZE])
+	if sizePtr > length-SIZEPTRSIZE {
+		return nil, ErrInvalidData
+	}
+
 	sizeVal := binary.BigEndian.Uint64(cf.Args[sizePtr : sizePtr+SIZEPTRSIZE])
+	if sizeVal > length-sizePtr-SIZEPTRSIZE {
+		return nil, ErrInvalidData
+	}
 
-	return cf.Args[sizePtr+SIZEPTRSIZE : sizePtr+SIZEPTRSIZE+sizeVal]
+	return cf.Args[sizePtr+SIZEPTRSIZE : sizePtr+SIZEPTRSIZE+sizeVal], nil
 }
 
 type opCode interface {
@@ -112,6 +253,8 @@ type div struct{}
 type mod struct{}
 type and struct{}
 type or struct{}
+type udiv struct{}
+type umod struct{}
 
 // 0x10 range
 type lt struct{}
@@ -120,6 +263,11 @@ type gt struct{}
 type gte struct{}
 type eq struct{}
 type not struct{}
+type minus struct{}
+type ult struct{}
+type ulte struct{}
+type ugt struct{}
+type ugte struct{}
 
 // 0x20 range
 type pop struct{}
@@ -138,6 +286,21 @@ type jumpi struct{}
 type dup struct{}
 type swap struct{}
 type exit struct{}
+type chainid struct{}
+type revert struct{}
+type timestamp struct{}
+type number struct{}
+type itoa struct{}
+type atoi struct{}
+type tohex struct{}
+type raise struct{}
+type caller struct{}
+type loadaddress struct{}
+type addresseq struct{}
+type byteat struct{}
+type length struct{}
+type loadbytes struct{}
+type returns struct{}
 
 func (add) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
 	y := stack.Pop()
@@ -182,6 +345,9 @@ func (sub) hex() []uint8 {
 func (div) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
 	y := stack.Pop()
 	x := stack.Pop()
+	if y == 0 {
+		return ErrDivideByZero
+	}
 
 	item, _ := euclidean_div(x, y)
 
@@ -197,6 +363,9 @@ func (div) hex() []uint8 {
 func (mod) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
 	y := stack.Pop()
 	x := stack.Pop()
+	if y == 0 {
+		return ErrDivideByZero
+	}
 
 	_, item := euclidean_div(x, y)
 
@@ -239,6 +408,38 @@ func (or) hex() []uint8 {
 	return []uint8{uint8(opcode.Or)}
 }
 
+func (udiv) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	y := uint64(stack.Pop())
+	x := uint64(stack.Pop())
+	if y == 0 {
+		return ErrDivideByZero
+	}
+
+	stack.Push(item(x / y))
+
+	return nil
+}
+
+func (udiv) hex() []uint8 {
+	return []uint8{uint8(opcode.UDiv)}
+}
+
+func (umod) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	y := uint64(stack.Pop())
+	x := uint64(stack.Pop())
+	if y == 0 {
+		return ErrDivideByZero
+	}
+
+	stack.Push(item(x % y))
+
+	return nil
+}
+
+func (umod) hex() []uint8 {
+	return []uint8{uint8(opcode.UMod)}
+}
+
 func (lt) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
 	y, x := stack.Pop(), stack.Pop()
 
@@ -336,6 +537,57 @@ func (not) hex() []uint8 {
 	return []uint8{uint8(opcode.NOT)}
 }
 
+func (minus) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	x := stack.Pop()
+
+	stack.Push(-x)
+	return nil
+}
+
+func (minus) hex() []uint8 {
+	return []uint8{uint8(opcode.Minus)}
+}
+
+func (ult) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	y, x := uint64(stack.Pop()), uint64(stack.Pop())
+	stack.Push(boolToItem(x < y))
+	return nil
+}
+
+func (ult) hex() []uint8 {
+	return []uint8{uint8(opcode.ULT)}
+}
+
+func (ulte) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	y, x := uint64(stack.Pop()), uint64(stack.Pop())
+	stack.Push(boolToItem(x <= y))
+	return nil
+}
+
+func (ulte) hex() []uint8 {
+	return []uint8{uint8(opcode.ULTE)}
+}
+
+func (ugt) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	y, x := uint64(stack.Pop()), uint64(stack.Pop())
+	stack.Push(boolToItem(x > y))
+	return nil
+}
+
+func (ugt) hex() []uint8 {
+	return []uint8{uint8(opcode.UGT)}
+}
+
+func (ugte) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	y, x := uint64(stack.Pop()), uint64(stack.Pop())
+	stack.Push(boolToItem(x >= y))
+	return nil
+}
+
+func (ugte) hex() []uint8 {
+	return []uint8{uint8(opcode.UGTE)}
+}
+
 func (pop) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
 	_ = stack.Pop()
 	return nil
@@ -364,6 +616,9 @@ func (push) hex() []uint8 {
 func (mload) Do(stack *Stack, _ asmReader, memory *Memory, _ *CallFunc) error {
 	offset, size := stack.Pop(), stack.Pop()
 	value := memory.GetVal(uint64(offset), uint64(size))
+	if len(value) < 8 {
+		return ErrInvalidMemory
+	}
 
 	stack.Push(bytesToItem(value))
 	return nil
@@ -395,7 +650,7 @@ func (msize) Do(stack *Stack, _ asmReader, memory *Memory, _ *CallFunc) error {
 }
 
 func (msize) hex() []uint8 {
-	return []uint8{uint8(opcode.Mstore)}
+	return []uint8{uint8(opcode.Msize)}
 }
 
 func (loadfunc) Do(stack *Stack, _ asmReader, _ *Memory, callfunc *CallFunc) error {
@@ -416,7 +671,13 @@ func (loadfunc) hex() []uint8 {
 
 func (loadargs) Do(stack *Stack, _ asmReader, _ *Memory, callfunc *CallFunc) error {
 	index := stack.Pop()
-	argument := callfunc.arguments(int(index))
+	argument, err := callfunc.arguments(int(index))
+	if err != nil {
+		return err
+	}
+	if len(argument) != 8 {
+		return ErrInvalidData
+	}
 
 	stack.Push(bytesToItem(argument))
 
@@ -498,12 +759,265 @@ func (exit) hex() []uint8 {
 	return []uint8{uint8(opcode.Exit)}
 }
 
+func (chainid) Do(stack *Stack, _ asmReader, _ *Memory, callfunc *CallFunc) error {
+	stack.Push(item(callfunc.ChainID))
+	return nil
+}
+
+func (chainid) hex() []uint8 {
+	return []uint8{uint8(opcode.ChainID)}
+}
+
+func (revert) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	reason := stack.Pop()
+	return RevertError{Reason: int64ToBytes(int64(reason))}
+}
+
+func (revert) hex() []uint8 {
+	return []uint8{uint8(opcode.Revert)}
+}
+
+func (timestamp) Do(stack *Stack, _ asmReader, _ *Memory, callfunc *CallFunc) error {
+	stack.Push(item(callfunc.Block.Timestamp))
+	return nil
+}
+
+func (timestamp) hex() []uint8 {
+	return []uint8{uint8(opcode.Timestamp)}
+}
+
+func (number) Do(stack *Stack, _ asmReader, _ *Memory, callfunc *CallFunc) error {
+	stack.Push(item(callfunc.Block.Number))
+	return nil
+}
+
+func (number) hex() []uint8 {
+	return []uint8{uint8(opcode.Number)}
+}
+
+func (itoa) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	s, err := stringToItem(strconv.FormatInt(int64(stack.Pop()), 10))
+	if err != nil {
+		return err
+	}
+
+	stack.Push(s)
+	return nil
+}
+
+func (itoa) hex() []uint8 {
+	return []uint8{uint8(opcode.Itoa)}
+}
+
+func (atoi) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	n, err := strconv.ParseInt(itemToString(stack.Pop()), 10, 64)
+	if err != nil {
+		return ErrConversion
+	}
+
+	stack.Push(item(n))
+	return nil
+}
+
+func (atoi) hex() []uint8 {
+	return []uint8{uint8(opcode.Atoi)}
+}
+
+func (tohex) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	s, err := stringToItem(strconv.FormatInt(int64(stack.Pop()), 16))
+	if err != nil {
+		return err
+	}
+
+	stack.Push(s)
+	return nil
+}
+
+func (tohex) hex() []uint8 {
+	return []uint8{uint8(opcode.Hex)}
+}
+
+func (raise) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	n, selector := stack.Pop(), stack.Pop()
+	if n < 0 || int(n) > stack.Len() {
+		return ErrStackUnderflow
+	}
+
+	args := make([]interface{}, 0, n)
+	for i := item(0); i < n; i++ {
+		args = append(args, int64(stack.Pop()))
+	}
+
+	encoded, err := abi.Encode(args...)
+	if err != nil {
+		return err
+	}
+
+	// selector is pushed right-aligned in the word
+	data := append(int64ToBytes(int64(selector))[4:], encoded...)
+	return RevertError{Data: data}
+}
+
+func (raise) hex() []uint8 {
+	return []uint8{uint8(opcode.Raise)}
+}
+
+func (caller) Do(stack *Stack, _ asmReader, _ *Memory, callfunc *CallFunc) error {
+	return pushAddress(stack, callfunc.Caller)
+}
+
+func (caller) hex() []uint8 {
+	return []uint8{uint8(opcode.Caller)}
+}
+
+func (loadaddress) Do(stack *Stack, _ asmReader, _ *Memory, callfunc *CallFunc) error {
+	index := stack.Pop()
+	argument, err := callfunc.arguments(int(index))
+	if err != nil {
+		return err
+	}
+	return pushAddress(stack, argument)
+}
+
+func (loadaddress) hex() []uint8 {
+	return []uint8{uint8(opcode.LoadAddress)}
+}
+
+func (addresseq) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	y2, y1, y0 := stack.Pop(), stack.Pop(), stack.Pop()
+	x2, x1, x0 := stack.Pop(), stack.Pop(), stack.Pop()
+
+	stack.Push(boolToItem(x0 == y0 && x1 == y1 && x2 == y2))
+	return nil
+}
+
+func (addresseq) hex() []uint8 {
+	return []uint8{uint8(opcode.AddressEQ)}
+}
+
+func (byteat) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	index, b := stack.Pop(), stack.Pop()
+
+	value := encoding.UnpackBytes(int64(b))
+	if index < 0 || int64(index) >= int64(len(value)) {
+		return ErrIndexOutOfRange
+	}
+
+	stack.Push(item(value[index]))
+	return nil
+}
+
+func (byteat) hex() []uint8 {
+	return []uint8{uint8(opcode.ByteAt)}
+}
+
+func (length) Do(stack *Stack, _ asmReader, _ *Memory, _ *CallFunc) error {
+	b := stack.Pop()
+	stack.Push(item(len(encoding.UnpackBytes(int64(b)))))
+	return nil
+}
+
+func (length) hex() []uint8 {
+	return []uint8{uint8(opcode.Len)}
+}
+
+func (loadbytes) Do(stack *Stack, _ asmReader, _ *Memory, callfunc *CallFunc) error {
+	index := stack.Pop()
+
+	argument, err := callfunc.arguments(int(index))
+	if err != nil {
+		return err
+	}
+
+	b, err := encoding.PackBytes(argument)
+	if err != nil {
+		return ErrInvalidData
+	}
+
+	stack.Push(item(b))
+	return nil
+}
+
+func (loadbytes) hex() []uint8 {
+	return []uint8{uint8(opcode.LoadBytes)}
+}
+
+func (returns) Do(stack *Stack, asm asmReader, _ *Memory, _ *CallFunc) error {
+	n := stack.Pop()
+	if n < 0 || int(n)+2 > stack.Len() {
+		return ErrStackUnderflow
+	}
+
+	values := make([]item, n)
+	for i := len(values) - 1; i >= 0; i-- {
+		values[i] = stack.Pop()
+	}
+	_, pos := stack.Pop(), stack.Pop()
+
+	asm.jump(uint64(pos - 1))
+
+	stack.PushN(values...)
+	return nil
+}
+
+func (returns) hex() []uint8 {
+	return []uint8{uint8(opcode.Returns)}
+}
+
+// pushAddress pushes words of address, so that the last
+// word is at the top of the stack
+func pushAddress(stack *Stack, address []byte) error {
+	a, err := encoding.BytesToAddress(address)
+	if err != nil {
+		return ErrInvalidData
+	}
+
+	for _, word := range a.Words() {
+		stack.Push(item(word))
+	}
+	return nil
+}
+
+// String is stored in item left-aligned and padded with zero.
+// String literal keeps its quotes as written in source, so strings
+// made by opcodes are quoted too, to compare equal to the literal.
+
+// stringToItem quotes s and stores it to item, s should be
+// at most 6 bytes to fit in
+func stringToItem(s string) (item, error) {
+	quoted := `"` + s + `"`
+	if len(quoted) > 8 {
+		return 0, ErrConversion
+	}
+
+	b := make([]byte, 8)
+	copy(b, quoted)
+	return bytesToItem(b), nil
+}
+
+// itemToString returns string stored in item without its quotes
+func itemToString(i item) string {
+	s := string(bytes.TrimRight(int64ToBytes(int64(i)), "\x00"))
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
 func int64ToBytes(int64 int64) []byte {
 	byteSlice := make([]byte, 8)
 	binary.BigEndian.PutUint64(byteSlice, uint64(int64))
 	return byteSlice
 }
 
+// boolToItem converts b to 1 if it is true, otherwise 0
+func boolToItem(b bool) item {
+	if b {
+		return item(1)
+	}
+	return item(0)
+}
+
 func bytesToItem(bytes []byte) item {
 	item := item(binary.BigEndian.Uint64(bytes))
 	return item
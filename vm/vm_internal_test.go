@@ -300,7 +300,10 @@ func TestCallFuncArguments(t *testing.T) {
 
 	for i, tt := range tests {
 		cf := CallFunc{Args: tt.args}
-		result := cf.arguments(tt.n)
+		result, err := cf.arguments(tt.n)
+		if err != nil {
+			t.Errorf("test[%d] - arguments() returns unexpected error: %s", i, err)
+		}
 
 		if !bytes.Equal(result, tt.expected) {
 			t.Errorf("test[%d] - Wrong arguments returned expected=%v, got=%v",
@@ -309,12 +312,41 @@ func TestCallFuncArguments(t *testing.T) {
 	}
 }
 
-func TestCallFuncArgumentsPanic(t *testing.T) {
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("Test case did not panic")
-		}
-	}()
+func TestCallFuncArguments_invalid(t *testing.T) {
+	tests := []struct {
+		n    int
+		args []byte
+	}{
+		{-1, nil},
+		{0, nil},
+		{0, []byte{0x00, 0x00, 0x00}},
+		// pointer out of args
+		{0, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00}},
+		// pointer to the last byte, size doesn't fit
+		{0, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x07}},
+		// huge pointer which overflows when adding size
+		{0, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		// size longer than args
+		{0, []byte{
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x09,
+			0x01, 0x02,
+		}},
+		// huge size which overflows
+		{0, []byte{
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08,
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		}},
+		// index past the pointers
+		{2, []byte{
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		}},
+	}
 
-	CallFunc{}.arguments(-1)
+	for i, tt := range tests {
+		if _, err := (CallFunc{Args: tt.args}).arguments(tt.n); err != ErrInvalidData {
+			t.Errorf("test[%d] - arguments() wrong error. expected=%s, got=%v", i, ErrInvalidData, err)
+		}
+	}
 }
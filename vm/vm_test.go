@@ -17,9 +17,9 @@
 package vm
 
 import (
-	"reflect"
-
 	"bytes"
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/DE-labtory/koa/abi"
@@ -237,6 +237,23 @@ func TestAdd(t *testing.T) {
 	}
 }
 
+func TestMinus(t *testing.T) {
+	testByteCode := makeTestByteCode(
+		uint8(opcode.Push), int64ToBytes(3),
+		uint8(opcode.Minus),
+	)
+	testExpected := item(-3)
+
+	stack, err := Execute(testByteCode, nil, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	result := stack.Pop()
+	if testExpected != result {
+		t.Errorf("stack.Pop() result wrong - expected=%d, got=%d", testExpected, result)
+	}
+}
+
 func TestAdd_negative(t *testing.T) {
 	testByteCode := makeTestByteCode(
 		uint8(opcode.Push), int64ToBytes(-20),
@@ -684,6 +701,50 @@ func TestPush_invalid(t *testing.T) {
 	}
 }
 
+func TestExecute_fault(t *testing.T) {
+	tests := []struct {
+		byteCode    []byte
+		expectedErr error
+	}{
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(1),
+				uint8(opcode.Add),
+			),
+			expectedErr: ErrStackUnderflow,
+		},
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(30),
+				uint8(opcode.Jump),
+			),
+			expectedErr: ErrInvalidJump,
+		},
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(8), // size
+				uint8(opcode.Push), int64ToBytes(0), // offset
+				uint8(opcode.Mload),
+			),
+			expectedErr: ErrInvalidMemory,
+		},
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(1),
+				uint8(opcode.Jumpi),
+			),
+			expectedErr: ErrStackUnderflow,
+		},
+	}
+
+	for i, test := range tests {
+		_, err := Execute(test.byteCode, NewMemory(), nil)
+		if !errors.Is(err, test.expectedErr) {
+			t.Errorf("test[%d] - Execute() wrong error. expected=%v, got=%v", i, test.expectedErr, err)
+		}
+	}
+}
+
 func TestMload(t *testing.T) {
 	testByteCode := makeTestByteCode(
 		uint8(opcode.Push), int64ToBytes(8), // size
@@ -922,6 +983,36 @@ func TestReturning(t *testing.T) {
 	}
 }
 
+func TestReturns(t *testing.T) {
+	testByteCode := makeTestByteCode( //  op code index
+		uint8(opcode.Push), int64ToBytes(13), // 0 , 1
+		uint8(opcode.Push), int64ToBytes(1), // 2 , 3 -> function selector
+		uint8(opcode.Push), int64ToBytes(5), // 4 , 5
+		uint8(opcode.Push), int64ToBytes(6), // 6 , 7
+		uint8(opcode.Push), int64ToBytes(2), // 8 , 9 -> number of values
+		uint8(opcode.Returns),               // 10
+		uint8(opcode.Push), int64ToBytes(3), // 11 , 12
+		uint8(opcode.Push), int64ToBytes(4), // 13 , 14 ( jump to here! )
+	)
+
+	testExpected := []item{5, 6, 4}
+
+	stack, err := Execute(testByteCode, nil, nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(stack.items) != len(testExpected) {
+		t.Fatalf("Invalid stack size - expected=%d, got =%d", len(testExpected), stack.Len())
+	}
+
+	for i, item := range stack.items {
+		if testExpected[i] != item {
+			t.Errorf("Stack item is incorrect - expected=%d, got=%d", testExpected[i], item)
+		}
+	}
+}
+
 func TestExit(t *testing.T) {
 	testByteCode := makeTestByteCode( //  op code index
 		uint8(opcode.Push), int64ToBytes(1), // 0 , 1
@@ -1092,3 +1183,204 @@ func TestSWAP(t *testing.T) {
 		}
 	}
 }
+
+// TestItoaHex checks the limit of strings made by Itoa and Hex,
+// which fit in a word with their quotes, so at most 6 characters
+func TestItoaHex(t *testing.T) {
+	tests := []struct {
+		op          opcode.Type
+		value       int64
+		expected    string
+		expectedErr error
+	}{
+		{op: opcode.Itoa, value: 0, expected: "0"},
+		{op: opcode.Itoa, value: 999999, expected: "999999"},
+		{op: opcode.Itoa, value: -99999, expected: "-99999"},
+		{op: opcode.Itoa, value: 1000000, expectedErr: ErrConversion},
+		{op: opcode.Itoa, value: -100000, expectedErr: ErrConversion},
+		{op: opcode.Hex, value: 0xffffff, expected: "ffffff"},
+		{op: opcode.Hex, value: -0xfffff, expected: "-fffff"},
+		{op: opcode.Hex, value: 0x1000000, expectedErr: ErrConversion},
+	}
+
+	for i, test := range tests {
+		testByteCode := makeTestByteCode(
+			uint8(opcode.Push), int64ToBytes(test.value),
+			uint8(test.op),
+		)
+
+		stack, err := Execute(testByteCode, nil, nil)
+		if err != test.expectedErr {
+			t.Errorf("test[%d] - Execute() wrong error. expected=%v, got=%v", i, test.expectedErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if s := itemToString(stack.items[0]); s != test.expected {
+			t.Errorf("test[%d] - Execute() wrong string. expected=%s, got=%s", i, test.expected, s)
+		}
+	}
+}
+
+func TestExecuteLimited(t *testing.T) {
+	tests := []struct {
+		byteCode    []byte
+		limits      Limits
+		expectedErr error
+	}{
+		{
+			// jumps back to the push forever
+			byteCode: makeTestByteCode( //  op code index
+				uint8(opcode.JumpDst),               // 0
+				uint8(opcode.Push), int64ToBytes(1), // 1 , 2
+				uint8(opcode.Jump),                  // 3
+			),
+			limits:      Limits{MaxSteps: 100},
+			expectedErr: ErrStepLimit,
+		},
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(64), // size
+				uint8(opcode.Msize),
+			),
+			limits:      Limits{MaxMemory: 32},
+			expectedErr: ErrMemoryLimit,
+		},
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(1),
+				uint8(opcode.Push), int64ToBytes(2),
+				uint8(opcode.Push), int64ToBytes(3),
+			),
+			limits:      Limits{MaxStack: 2},
+			expectedErr: ErrStackLimit,
+		},
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(64), // size
+				uint8(opcode.Msize),
+				uint8(opcode.Push), int64ToBytes(1),
+			),
+			limits:      Sandbox,
+			expectedErr: nil,
+		},
+	}
+
+	for i, test := range tests {
+		_, err := ExecuteLimited(test.byteCode, NewMemory(), nil, test.limits)
+		if err != test.expectedErr {
+			t.Errorf("test[%d] - ExecuteLimited() wrong error. expected=%v, got=%v", i, test.expectedErr, err)
+		}
+	}
+}
+
+// TestExecuteLimited_hostile runs the bytecode and args which untrusted
+// client can send, they must fail without crashing the host
+func TestExecuteLimited_hostile(t *testing.T) {
+	tests := []struct {
+		byteCode    []byte
+		args        []byte
+		expectedErr error
+	}{
+		{
+			// loads more than memory has
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(16),
+				uint8(opcode.Msize),
+				uint8(opcode.Push), int64ToBytes(100000), // size
+				uint8(opcode.Push), int64ToBytes(0), // offset
+				uint8(opcode.Mload),
+			),
+			expectedErr: ErrInvalidMemory,
+		},
+		{
+			// offset + size overflows
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(16),
+				uint8(opcode.Msize),
+				uint8(opcode.Push), int64ToBytes(8), // size
+				uint8(opcode.Push), int64ToBytes(-4), // offset
+				uint8(opcode.Mload),
+			),
+			expectedErr: ErrInvalidMemory,
+		},
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(16),
+				uint8(opcode.Msize),
+				uint8(opcode.Push), int64ToBytes(1), // value
+				uint8(opcode.Push), int64ToBytes(8), // size
+				uint8(opcode.Push), int64ToBytes(-4), // offset
+				uint8(opcode.Mstore),
+			),
+			expectedErr: ErrInvalidMemory,
+		},
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(-1), // size
+				uint8(opcode.Msize),
+			),
+			expectedErr: ErrMemoryLimit,
+		},
+		{
+			// no args
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(0),
+				uint8(opcode.LoadArgs),
+			),
+			expectedErr: ErrInvalidData,
+		},
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(-1),
+				uint8(opcode.LoadArgs),
+			),
+			args:        int64ToBytes(8),
+			expectedErr: ErrInvalidData,
+		},
+		{
+			// pointer out of args
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(0),
+				uint8(opcode.LoadArgs),
+			),
+			args:        int64ToBytes(1 << 40),
+			expectedErr: ErrInvalidData,
+		},
+		{
+			// argument shorter than item
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(0),
+				uint8(opcode.LoadArgs),
+			),
+			args:        append(append(int64ToBytes(8), int64ToBytes(3)...), 0x01, 0x02, 0x03),
+			expectedErr: ErrInvalidData,
+		},
+		{
+			// size longer than args
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(0),
+				uint8(opcode.LoadAddress),
+			),
+			args:        append(int64ToBytes(8), int64ToBytes(20)...),
+			expectedErr: ErrInvalidData,
+		},
+		{
+			byteCode: makeTestByteCode(
+				uint8(opcode.Push), int64ToBytes(0),
+				uint8(opcode.LoadBytes),
+			),
+			args:        append(int64ToBytes(8), int64ToBytes(-1)...),
+			expectedErr: ErrInvalidData,
+		},
+	}
+
+	for i, test := range tests {
+		_, err := ExecuteLimited(test.byteCode, NewMemory(), &CallFunc{Args: test.args}, Sandbox)
+		if err != test.expectedErr {
+			t.Errorf("test[%d] - ExecuteLimited() wrong error. expected=%v, got=%v", i, test.expectedErr, err)
+		}
+	}
+}
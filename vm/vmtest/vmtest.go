@@ -0,0 +1,221 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package vmtest runs portable vm test vectors.
+//
+// Vector is written in JSON so that other implementations of the vm can
+// run the same cases. Byte fields are hexadecimal strings and stack items
+// are listed from bottom to top.
+//
+//	{
+//	  "name": "add",
+//	  "pre": {"memory": ""},
+//	  "code": "21000000000000000121000000000000000201",
+//	  "input": {"func": "", "args": ""},
+//	  "post": {"stack": [3]}
+//	}
+//
+// Expected fault is written with its name in "error" instead of "post".
+// The vm has no gas or logs, so vector does not describe them yet.
+package vmtest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/DE-labtory/koa/vm"
+)
+
+// Faults are the names of vm errors which vector can expect
+var Faults = map[string]error{
+	"StackUnderflow":  vm.ErrStackUnderflow,
+	"InvalidMemory":   vm.ErrInvalidMemory,
+	"InvalidJump":     vm.ErrInvalidJump,
+	"InvalidOpcode":   vm.ErrInvalidOpcode,
+	"InvalidData":     vm.ErrInvalidData,
+	"DivideByZero":    vm.ErrDivideByZero,
+	"Revert":          vm.ErrRevert,
+	"Conversion":      vm.ErrConversion,
+	"IndexOutOfRange": vm.ErrIndexOutOfRange,
+}
+
+// Vector is a test case of the vm
+type Vector struct {
+	Name  string `json:"name"`
+	Pre   Pre    `json:"pre"`
+	Code  string `json:"code"`
+	Input Input  `json:"input"`
+	Post  Post   `json:"post"`
+	Error string `json:"error,omitempty"`
+}
+
+// Pre is the state before the code runs
+type Pre struct {
+	Memory string `json:"memory"`
+}
+
+// Input is the function call the code runs with
+type Input struct {
+	Func    string `json:"func"`
+	Args    string `json:"args"`
+	ChainID int64  `json:"chainId,omitempty"`
+
+	BlockNumber int64 `json:"blockNumber,omitempty"`
+	Timestamp   int64 `json:"timestamp,omitempty"`
+
+	Caller string `json:"caller,omitempty"`
+}
+
+// Post is the expected state after the code runs.
+// Memory is compared only if it is not empty.
+type Post struct {
+	Stack  []int64 `json:"stack"`
+	Memory string  `json:"memory,omitempty"`
+}
+
+// Load reads vectors of JSON array from r
+func Load(r io.Reader) ([]Vector, error) {
+	vectors := make([]Vector, 0)
+	if err := json.NewDecoder(r).Decode(&vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// LoadDir reads vectors of every .json file in dir in file name order
+func LoadDir(dir string) ([]Vector, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	vectors := make([]Vector, 0)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := Load(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		vectors = append(vectors, v...)
+	}
+
+	return vectors, nil
+}
+
+// Run executes the code of v and compares the result with v
+func Run(v Vector) error {
+	code, err := decodeField("code", v.Code)
+	if err != nil {
+		return err
+	}
+	preMemory, err := decodeField("pre.memory", v.Pre.Memory)
+	if err != nil {
+		return err
+	}
+	function, err := decodeField("input.func", v.Input.Func)
+	if err != nil {
+		return err
+	}
+	args, err := decodeField("input.args", v.Input.Args)
+	if err != nil {
+		return err
+	}
+
+	caller, err := decodeField("input.caller", v.Input.Caller)
+	if err != nil {
+		return err
+	}
+
+	memory := vm.NewMemory()
+	memory.Resize(uint64(len(preMemory)))
+	memory.Sets(0, uint64(len(preMemory)), preMemory)
+
+	stack, err := vm.Execute(code, memory, &vm.CallFunc{
+		Func:    function,
+		Args:    args,
+		ChainID: v.Input.ChainID,
+		Block: vm.Block{
+			Number:    v.Input.BlockNumber,
+			Timestamp: v.Input.Timestamp,
+		},
+		Caller: caller,
+	})
+	if v.Error != "" {
+		return checkFault(v.Error, err)
+	}
+	if err != nil {
+		return fmt.Errorf("unexpected error: %v", err)
+	}
+
+	got := make([]int64, stack.Len())
+	for i := len(got) - 1; i >= 0; i-- {
+		got[i] = int64(stack.Pop())
+	}
+	if !equalStack(got, v.Post.Stack) {
+		return fmt.Errorf("wrong stack. expected=%v, got=%v", v.Post.Stack, got)
+	}
+
+	if v.Post.Memory != "" {
+		if got := hex.EncodeToString(memory.Data()); got != v.Post.Memory {
+			return fmt.Errorf("wrong memory. expected=%s, got=%s", v.Post.Memory, got)
+		}
+	}
+
+	return nil
+}
+
+func checkFault(name string, err error) error {
+	fault, ok := Faults[name]
+	if !ok {
+		return fmt.Errorf("unknown error %s", name)
+	}
+	if !errors.Is(err, fault) {
+		return fmt.Errorf("wrong error. expected=%v, got=%v", fault, err)
+	}
+	return nil
+}
+
+func decodeField(field, s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", field, err)
+	}
+	return b, nil
+}
+
+func equalStack(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
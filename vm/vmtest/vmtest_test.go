@@ -0,0 +1,76 @@
+/*
+ * Copyright 2018-2019 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vmtest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DE-labtory/koa/vm/vmtest"
+)
+
+func TestVectors(t *testing.T) {
+	vectors, err := vmtest.LoadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no test vectors in testdata")
+	}
+
+	for _, v := range vectors {
+		if err := vmtest.Run(v); err != nil {
+			t.Errorf("%s: %v", v.Name, err)
+		}
+	}
+}
+
+func TestRun_mismatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`[{"name": "stack", "code": "210000000000000001", "post": {"stack": [2]}}]`,
+			"wrong stack. expected=[2], got=[1]",
+		},
+		{
+			`[{"name": "error", "code": "210000000000000001", "error": "StackUnderflow"}]`,
+			"wrong error. expected=Stack underflow, got=<nil>",
+		},
+		{
+			`[{"name": "fault", "code": "21", "error": "Unknown"}]`,
+			"unknown error Unknown",
+		},
+		{
+			`[{"name": "code", "code": "zz"}]`,
+			"invalid code: encoding/hex: invalid byte: U+007A 'z'",
+		},
+	}
+
+	for i, test := range tests {
+		vectors, err := vmtest.Load(strings.NewReader(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = vmtest.Run(vectors[0])
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("test[%d] - Run() wrong error. expected=%s, got=%v", i, test.expected, err)
+		}
+	}
+}